@@ -14,17 +14,274 @@ CREATE TABLE IF NOT EXISTS `seller` (
   DEFAULT CHARSET = utf8
   ROW_FORMAT = DYNAMIC;
 
+CREATE TABLE IF NOT EXISTS `brand`
+(
+  `id_brand`        int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `uuid`            VARCHAR(36)      NOT NULL,
+  `name`            VARCHAR(200)     NOT NULL,
+  `normalized_name` VARCHAR(200)     NOT NULL,
+  PRIMARY KEY (`id_brand`),
+  UNIQUE KEY `uuid` (`uuid`),
+  UNIQUE KEY `normalized_name` (`normalized_name`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
 CREATE TABLE IF NOT EXISTS `product`
 (
   `id_product` int(10) unsigned NOT NULL AUTO_INCREMENT,
   `name`       VARCHAR(200)     NOT NULL,
+  `normalized_name` VARCHAR(200) NOT NULL DEFAULT '',
   `brand`      VARCHAR(200)     NOT NULL,
+  `fk_brand`   INT(10) unsigned NULL     DEFAULT NULL,
   `stock`      INT(10) DEFAULT 0,
   `fk_seller`  INT(10) unsigned NOT NULL,
   `uuid`       VARCHAR(36)      NOT NULL,
+  `version`    INT(10) unsigned NOT NULL DEFAULT 1,
+  `deleted_at` DATETIME         NULL     DEFAULT NULL,
+  `price_amount`   BIGINT       NULL     DEFAULT NULL,
+  `price_currency` CHAR(3)      NULL     DEFAULT NULL,
+  `attributes`     JSON         NULL     DEFAULT NULL,
+  `sku`            VARCHAR(64)  NULL     DEFAULT NULL,
+  `gtin`           VARCHAR(14)  NULL     DEFAULT NULL,
+  `gtin14`         CHAR(14) AS (LPAD(`gtin`, 14, '0')) STORED,
+  `status`         VARCHAR(16)  NOT NULL DEFAULT 'published',
+  `published_at`   DATETIME     NULL     DEFAULT NULL,
+  `archived_at`    DATETIME     NULL     DEFAULT NULL,
   PRIMARY KEY (`id_product`),
   UNIQUE KEY `uuid` (`uuid`),
-  CONSTRAINT fk_seller FOREIGN KEY (fk_seller) REFERENCES seller (id_seller)
+  UNIQUE KEY `seller_sku` (`fk_seller`, `sku`),
+  UNIQUE KEY `gtin14` (`gtin14`),
+  KEY `deleted_at` (`deleted_at`),
+  KEY `price` (`price_currency`, `price_amount`),
+  KEY `seller_normalized_name` (`fk_seller`, `normalized_name`),
+  KEY `status` (`status`),
+  CONSTRAINT fk_seller FOREIGN KEY (fk_seller) REFERENCES seller (id_seller),
+  CONSTRAINT fk_brand FOREIGN KEY (fk_brand) REFERENCES brand (id_brand)
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE IF NOT EXISTS `reservation`
+(
+  `id_reservation` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `uuid`           VARCHAR(36)      NOT NULL,
+  `fk_product`     INT(10) unsigned NOT NULL,
+  `quantity`       INT(10) unsigned NOT NULL,
+  `status`         VARCHAR(16)      NOT NULL DEFAULT 'active',
+  `expires_at`     DATETIME         NOT NULL,
+  `created_at`     DATETIME         NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id_reservation`),
+  UNIQUE KEY `uuid` (`uuid`),
+  KEY `product_status` (`fk_product`, `status`),
+  KEY `status_expires_at` (`status`, `expires_at`),
+  CONSTRAINT fk_reservation_product FOREIGN KEY (fk_product) REFERENCES product (id_product) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE IF NOT EXISTS `product_audit`
+(
+  `id_product_audit` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_uuid`     VARCHAR(36)      NOT NULL,
+  `revision`         INT(10) unsigned NOT NULL,
+  `action`           VARCHAR(32)      NOT NULL,
+  `actor`            VARCHAR(200)     NOT NULL,
+  `request_id`       VARCHAR(64)      NOT NULL,
+  `changes`          JSON             NOT NULL,
+  `snapshot`         JSON             NOT NULL,
+  `created_at`       DATETIME(3)      NOT NULL,
+  PRIMARY KEY (`id_product_audit`),
+  UNIQUE KEY `product_revision` (`product_uuid`, `revision`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE IF NOT EXISTS `product_price_history`
+(
+  `id_product_price_history` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_uuid`             VARCHAR(36)      NOT NULL,
+  `price_amount`             BIGINT           NULL DEFAULT NULL,
+  `price_currency`           CHAR(3)          NULL DEFAULT NULL,
+  `changed_at`               DATETIME(3)      NOT NULL,
+  PRIMARY KEY (`id_product_price_history`),
+  KEY `product_changed_at` (`product_uuid`, `changed_at`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE IF NOT EXISTS `price_alert`
+(
+  `id_price_alert` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `uuid`           VARCHAR(36)      NOT NULL,
+  `fk_product`     INT(10) unsigned NOT NULL,
+  `email`          VARCHAR(255)     NOT NULL DEFAULT '',
+  `phone`          VARCHAR(32)      NOT NULL DEFAULT '',
+  `created_at`     DATETIME         NOT NULL,
+  PRIMARY KEY (`id_price_alert`),
+  UNIQUE KEY `uuid` (`uuid`),
+  KEY `fk_product` (`fk_product`),
+  CONSTRAINT fk_price_alert_product FOREIGN KEY (fk_product) REFERENCES product (id_product) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE IF NOT EXISTS `product_variant`
+(
+  `id_product_variant` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `uuid`               VARCHAR(36)      NOT NULL,
+  `fk_product`         INT(10) unsigned NOT NULL,
+  `sku`                VARCHAR(64)      NOT NULL,
+  `options`            JSON             NOT NULL,
+  `stock`              INT(10)          NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id_product_variant`),
+  UNIQUE KEY `uuid` (`uuid`),
+  UNIQUE KEY `product_sku` (`fk_product`, `sku`),
+  CONSTRAINT fk_variant_product FOREIGN KEY (fk_product) REFERENCES product (id_product) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE IF NOT EXISTS `product_image`
+(
+  `id_product_image` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `uuid`             VARCHAR(36)      NOT NULL,
+  `fk_product`       INT(10) unsigned NOT NULL,
+  `content_type`     VARCHAR(32)      NOT NULL,
+  `width`            INT(10) unsigned NOT NULL,
+  `height`           INT(10) unsigned NOT NULL,
+  `size`             BIGINT unsigned  NOT NULL,
+  `created_at`       DATETIME         NOT NULL,
+  PRIMARY KEY (`id_product_image`),
+  UNIQUE KEY `uuid` (`uuid`),
+  KEY `fk_product` (`fk_product`),
+  CONSTRAINT fk_image_product FOREIGN KEY (fk_product) REFERENCES product (id_product) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE IF NOT EXISTS `category`
+(
+  `id_category` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `uuid`        VARCHAR(36)      NOT NULL,
+  `name`        VARCHAR(200)     NOT NULL,
+  `slug`        VARCHAR(64)      NOT NULL,
+  `fk_parent`   INT(10) unsigned NULL DEFAULT NULL,
+  `path`        VARCHAR(255)     NOT NULL,
+  `attribute_schema` JSON        NULL DEFAULT NULL,
+  PRIMARY KEY (`id_category`),
+  UNIQUE KEY `uuid` (`uuid`),
+  UNIQUE KEY `path` (`path`),
+  CONSTRAINT fk_category_parent FOREIGN KEY (fk_parent) REFERENCES category (id_category)
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE IF NOT EXISTS `product_category`
+(
+  `fk_product`  INT(10) unsigned NOT NULL,
+  `fk_category` INT(10) unsigned NOT NULL,
+  PRIMARY KEY (`fk_product`, `fk_category`),
+  KEY `fk_category` (`fk_category`),
+  CONSTRAINT fk_product_category_product FOREIGN KEY (fk_product) REFERENCES product (id_product) ON DELETE CASCADE,
+  CONSTRAINT fk_product_category_category FOREIGN KEY (fk_category) REFERENCES category (id_category) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE IF NOT EXISTS `location`
+(
+  `id_location` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `uuid`        VARCHAR(36)      NOT NULL,
+  `fk_seller`   INT(10) unsigned NOT NULL,
+  `name`        VARCHAR(200)     NOT NULL,
+  PRIMARY KEY (`id_location`),
+  UNIQUE KEY `uuid` (`uuid`),
+  UNIQUE KEY `seller_name` (`fk_seller`, `name`),
+  CONSTRAINT fk_location_seller FOREIGN KEY (fk_seller) REFERENCES seller (id_seller)
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE IF NOT EXISTS `product_stock`
+(
+  `fk_product`  INT(10) unsigned NOT NULL,
+  `fk_location` INT(10) unsigned NOT NULL,
+  `stock`       INT(10)          NOT NULL DEFAULT 0,
+  PRIMARY KEY (`fk_product`, `fk_location`),
+  KEY `fk_location` (`fk_location`),
+  CONSTRAINT fk_stock_product FOREIGN KEY (fk_product) REFERENCES product (id_product) ON DELETE CASCADE,
+  CONSTRAINT fk_stock_location FOREIGN KEY (fk_location) REFERENCES location (id_location) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE IF NOT EXISTS `idempotency_record`
+(
+  `idempotency_key` CHAR(64)         NOT NULL,
+  `fingerprint`     CHAR(64)         NOT NULL,
+  `status_code`     SMALLINT unsigned NULL DEFAULT NULL,
+  `content_type`    VARCHAR(100)     NULL     DEFAULT NULL,
+  `headers`         JSON             NULL     DEFAULT NULL,
+  `body`            MEDIUMBLOB       NULL     DEFAULT NULL,
+  `expires_at`      DATETIME         NOT NULL,
+  `created_at`      DATETIME         NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`idempotency_key`),
+  KEY `expires_at` (`expires_at`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE IF NOT EXISTS `scheduled_change`
+(
+  `id_scheduled_change` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `uuid`                VARCHAR(36)      NOT NULL,
+  `fk_product`          INT(10) unsigned NOT NULL,
+  `patch`               JSON             NOT NULL,
+  `effective_at`        DATETIME         NOT NULL,
+  `status`              VARCHAR(16)      NOT NULL DEFAULT 'pending',
+  `attempts`            INT(10) unsigned NOT NULL DEFAULT 0,
+  `next_attempt_at`     DATETIME         NOT NULL,
+  `last_error`          TEXT             NULL,
+  `applied_at`          DATETIME         NULL     DEFAULT NULL,
+  `created_by`          VARCHAR(200)     NOT NULL DEFAULT '',
+  `created_at`          DATETIME         NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id_scheduled_change`),
+  UNIQUE KEY `uuid` (`uuid`),
+  KEY `status_next_attempt_at` (`status`, `next_attempt_at`),
+  KEY `product_effective_at` (`fk_product`, `effective_at`),
+  CONSTRAINT fk_scheduled_change_product FOREIGN KEY (fk_product) REFERENCES product (id_product) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE IF NOT EXISTS `stock_movement`
+(
+  `id_stock_movement` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `product_uuid`      VARCHAR(36)      NOT NULL,
+  `delta`             INT(10)          NOT NULL,
+  `reason`            VARCHAR(16)      NOT NULL,
+  `reference`         VARCHAR(255)     NULL DEFAULT NULL,
+  `actor`             VARCHAR(200)     NOT NULL DEFAULT '',
+  `stock_after`       INT(10)          NOT NULL,
+  `created_at`        DATETIME(3)      NOT NULL,
+  PRIMARY KEY (`id_stock_movement`),
+  KEY `product_uuid` (`product_uuid`, `id_stock_movement`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE IF NOT EXISTS `stock_reconciliation`
+(
+  `id_stock_reconciliation` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `uuid`                    VARCHAR(36)      NOT NULL,
+  `seller_uuid`             VARCHAR(36)      NULL     DEFAULT NULL,
+  `status`                  VARCHAR(16)      NOT NULL DEFAULT 'pending',
+  `report_lines`            JSON             NOT NULL,
+  `created_by`              VARCHAR(200)     NOT NULL DEFAULT '',
+  `created_at`              DATETIME         NOT NULL,
+  `reviewed_by`             VARCHAR(200)     NULL     DEFAULT NULL,
+  `reviewed_at`             DATETIME         NULL     DEFAULT NULL,
+  PRIMARY KEY (`id_stock_reconciliation`),
+  UNIQUE KEY `uuid` (`uuid`),
+  KEY `seller_status` (`seller_uuid`, `status`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
+
+CREATE TABLE IF NOT EXISTS `product_bundle_component`
+(
+  `fk_bundle`    INT(10) unsigned NOT NULL,
+  `fk_component` INT(10) unsigned NOT NULL,
+  `quantity`     INT(10) unsigned NOT NULL,
+  PRIMARY KEY (`fk_bundle`, `fk_component`),
+  KEY `fk_component` (`fk_component`),
+  CONSTRAINT fk_bundle_component_bundle FOREIGN KEY (fk_bundle) REFERENCES product (id_product) ON DELETE CASCADE,
+  CONSTRAINT fk_bundle_component_product FOREIGN KEY (fk_component) REFERENCES product (id_product) ON DELETE RESTRICT
 ) ENGINE = InnoDB
   DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
 
@@ -44,27 +301,30 @@ INSERT INTO seller (id_seller, name, email, phone, uuid) VALUES
 (13, 'Owen Swick', 'owen.swick@seller.com', '202-555-0166', UUID()),
 (14, 'Christene Ringgold', 'christene.ringgold@seller.com', '202-555-0166', UUID());
 
-INSERT INTO product (id_product, name, brand, stock, fk_seller, uuid) VALUES
-(1, 'Raja', 'RJ', 100, 1, UUID()),
-(2, 'Pure Linen Plain Shirt', 'ShirtsCo', 44, 1, UUID()),
-(3, 'Long Sleeve Nursing Tops', 'ShirtsCo', 12, 1, UUID()),
-(4, 'Christian Dior Pure Poison Eau De Parfum Spray', 'Christian Dior', 1031, 2, UUID()),
-(5, 'Speed TR Flexweave Shoes', 'Flexweave', 1300, 2, UUID()),
-(6, 'Berlin New Shirt', 'ShirtsCo', 2100, 2, UUID()),
-(7, '2 Pack Bikini Brief', 'ShirtsCo', 3200, 2, UUID()),
-(8, 'Organic Moisturizing Lip Balm', 'Organic', 10, 2, UUID()),
-(9, 'Combi Leather Sandals', 'ShoesCo', 1100, 3, UUID()),
-(10, 'Sasha', 'Sasha', 120, 3, UUID()),
-(11, 'Womens Bella Surfing Maxi Tank Dress', 'ShirtsCo', 2100, 4, UUID()),
-(12, 'Plano Tee', 'TeeCo', 10, 4, UUID()),
-(13, 'Solid Cross Back Bikini Top', 'ShirtsCo', 120, 3, UUID()),
-(14, 'Ottana Sweater', 'Ottana', 3200, 1, UUID()),
-(15, 'Brushed Herringbone Pant With Tape Detail In Loose Tapered Fit', 'Ottana', 100, 5, UUID()),
-(16, 'Mina', 'Mina', 321, 6, UUID()),
-(17, 'Claire Top', 'Claire', 1321, 7, UUID()),
-(18, 'Calvin Klein Fully Delicious Sheer Plumping Lip Gloss', 'Calvin Klein', 132, 8, UUID()),
-(19, 'Shara Shara White Stem Sleeping Mask', 'Shara Shara', 1021, 9, UUID()),
-(20, 'Classy & Fabulous Coat', 'ShirtsCo', 1332, 11, UUID()),
-(21, 'Black Cherry Dress', 'ShirtsCo', 11, 12, UUID()),
-(22, 'Storm Jacket', 'ShirtsCo', 145, 14, UUID()),
-(23, 'Sweet Daisy Top', 'ShirtsCo', 14400, 14, UUID());
+INSERT INTO product (id_product, name, normalized_name, brand, stock, fk_seller, uuid) VALUES
+(1, 'Raja', 'raja', 'RJ', 100, 1, UUID()),
+(2, 'Pure Linen Plain Shirt', 'purelinenplainshirt', 'ShirtsCo', 44, 1, UUID()),
+(3, 'Long Sleeve Nursing Tops', 'longsleevenursingtops', 'ShirtsCo', 12, 1, UUID()),
+(4, 'Christian Dior Pure Poison Eau De Parfum Spray', 'christiandiorpurepoisoneaudeparfumspray', 'Christian Dior', 1031, 2, UUID()),
+(5, 'Speed TR Flexweave Shoes', 'speedtrflexweaveshoes', 'Flexweave', 1300, 2, UUID()),
+(6, 'Berlin New Shirt', 'berlinnewshirt', 'ShirtsCo', 2100, 2, UUID()),
+(7, '2 Pack Bikini Brief', '2packbikinibrief', 'ShirtsCo', 3200, 2, UUID()),
+(8, 'Organic Moisturizing Lip Balm', 'organicmoisturizinglipbalm', 'Organic', 10, 2, UUID()),
+(9, 'Combi Leather Sandals', 'combileathersandals', 'ShoesCo', 1100, 3, UUID()),
+(10, 'Sasha', 'sasha', 'Sasha', 120, 3, UUID()),
+(11, 'Womens Bella Surfing Maxi Tank Dress', 'womensbellasurfingmaxitankdress', 'ShirtsCo', 2100, 4, UUID()),
+(12, 'Plano Tee', 'planotee', 'TeeCo', 10, 4, UUID()),
+(13, 'Solid Cross Back Bikini Top', 'solidcrossbackbikinitop', 'ShirtsCo', 120, 3, UUID()),
+(14, 'Ottana Sweater', 'ottanasweater', 'Ottana', 3200, 1, UUID()),
+(15, 'Brushed Herringbone Pant With Tape Detail In Loose Tapered Fit', 'brushedherringbonepantwithtapedetailinloosetaperedfit', 'Ottana', 100, 5, UUID()),
+(16, 'Mina', 'mina', 'Mina', 321, 6, UUID()),
+(17, 'Claire Top', 'clairetop', 'Claire', 1321, 7, UUID()),
+(18, 'Calvin Klein Fully Delicious Sheer Plumping Lip Gloss', 'calvinkleinfullydelicioussheerplumpinglipgloss', 'Calvin Klein', 132, 8, UUID()),
+(19, 'Shara Shara White Stem Sleeping Mask', 'sharasharawhitestemsleepingmask', 'Shara Shara', 1021, 9, UUID()),
+(20, 'Classy & Fabulous Coat', 'classyfabulouscoat', 'ShirtsCo', 1332, 11, UUID()),
+(21, 'Black Cherry Dress', 'blackcherrydress', 'ShirtsCo', 11, 12, UUID()),
+(22, 'Storm Jacket', 'stormjacket', 'ShirtsCo', 145, 14, UUID()),
+(23, 'Sweet Daisy Top', 'sweetdaisytop', 'ShirtsCo', 14400, 14, UUID());
+
+INSERT INTO stock_movement (product_uuid, delta, reason, actor, stock_after, created_at)
+SELECT uuid, stock, 'initial', 'seed', stock, NOW(3) FROM product WHERE stock <> 0;
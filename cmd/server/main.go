@@ -1,6 +1,10 @@
 package main
 
 import (
+	"os"
+
+	"github.com/rs/zerolog/log"
+
 	"coding-challenge-go/server"
 	"coding-challenge-go/server/config"
 
@@ -10,6 +14,23 @@ import (
 func main() {
 
 	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("Invalid configuration")
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := server.Export(cfg, os.Args[2:]); err != nil {
+			log.Fatal().Err(err).Msg("Fail to export products")
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate-brands" {
+		if err := server.MigrateBrands(cfg, os.Args[2:]); err != nil {
+			log.Fatal().Err(err).Msg("Fail to migrate brands")
+		}
+		return
+	}
 
 	server.Server(cfg)
 }
@@ -0,0 +1,35 @@
+package brand
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const maxNameLength = 200
+
+type Brand struct {
+	BrandID int    `json:"-"`
+	UUID    string `json:"uuid"`
+	Name    string `json:"name"`
+}
+
+// Normalize reduces a brand name to the key brands are deduplicated by, so
+// "ShirtsCo", "Shirts Co" and "shirts-co" are the same brand.
+func Normalize(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func (b *Brand) validate() error {
+	b.Name = strings.TrimSpace(b.Name)
+	if Normalize(b.Name) == "" || len(b.Name) > maxNameLength {
+		return NewInvalidBrandError(fmt.Sprintf("name must be between 1 and %d characters and contain a letter or digit", maxNameLength))
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package brand
+
+import "fmt"
+
+type BrandNotFoundError struct {
+	id string
+}
+
+func (e BrandNotFoundError) Error() string {
+	return fmt.Sprintf("Brand is not found with id=%s", e.id)
+}
+
+func NewBrandNotFoundError(uuid string) error {
+	return &BrandNotFoundError{
+		id: uuid,
+	}
+}
+
+type InvalidBrandError struct {
+	reason string
+}
+
+func (e InvalidBrandError) Error() string {
+	return fmt.Sprintf("Brand is invalid: %s", e.reason)
+}
+
+func NewInvalidBrandError(reason string) error {
+	return &InvalidBrandError{
+		reason: reason,
+	}
+}
+
+// Reason tells what is wrong with the brand.
+func (e InvalidBrandError) Reason() string {
+	return e.reason
+}
+
+type BrandConflictError struct {
+	name     string
+	existing string
+}
+
+func (e BrandConflictError) Error() string {
+	return fmt.Sprintf("Brand %s is the same as existing brand id=%s", e.name, e.existing)
+}
+
+func NewBrandConflictError(name string, existingUUID string) error {
+	return &BrandConflictError{
+		name:     name,
+		existing: existingUUID,
+	}
+}
+
+type BrandInUseError struct {
+	id       string
+	products int
+}
+
+func (e BrandInUseError) Error() string {
+	return fmt.Sprintf("Brand id=%s is used by %d products and cannot be deleted", e.id, e.products)
+}
+
+func NewBrandInUseError(uuid string, products int) error {
+	return &BrandInUseError{
+		id:       uuid,
+		products: products,
+	}
+}
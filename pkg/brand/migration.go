@@ -0,0 +1,106 @@
+package brand
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+type (
+	// NameCount is how many products carry a brand string before migration.
+	NameCount struct {
+		Name     string
+		Products int
+	}
+
+	// MergeGroup is one brand together with every spelling that was merged into it.
+	MergeGroup struct {
+		Brand    *Brand   `json:"brand"`
+		Variants []string `json:"variants"`
+		Products int      `json:"products"`
+	}
+
+	// MigrationReport describes what migrating the brand strings did, or would do on a dry run.
+	MigrationReport struct {
+		DryRun   bool          `json:"dry_run"`
+		Brands   int           `json:"brands"`
+		Products int           `json:"products"`
+		Groups   []*MergeGroup `json:"groups"`
+	}
+)
+
+// Merged returns the groups that folded more than one spelling into a brand.
+func (r *MigrationReport) Merged() []*MergeGroup {
+	merged := []*MergeGroup{}
+	for _, g := range r.Groups {
+		if len(g.Variants) > 1 {
+			merged = append(merged, g)
+		}
+	}
+	return merged
+}
+
+// WriteText writes the report in a form meant for the operator running the migration.
+func (r *MigrationReport) WriteText(w io.Writer) error {
+	verb := "Linked"
+	if r.DryRun {
+		verb = "Would link"
+	}
+	if _, err := fmt.Fprintf(w, "%s %d products to %d brands, %d of them new\n", verb, r.Products, len(r.Groups), r.Brands); err != nil {
+		return err
+	}
+
+	merged := r.Merged()
+	if len(merged) == 0 {
+		_, err := fmt.Fprintln(w, "No spellings were merged")
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "Merged spellings:"); err != nil {
+		return err
+	}
+	for _, g := range merged {
+		_, err := fmt.Fprintf(w, "  %s <- %s (%d products)\n", g.Brand.Name, strings.Join(g.Variants, ", "), g.Products)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// planMigration groups brand strings by their normalized name. Each group is
+// named after its most used spelling, ties going to the alphabetically first.
+// Strings that normalize to nothing are left out.
+func planMigration(counts []*NameCount) []*MergeGroup {
+	sorted := make([]*NameCount, len(counts))
+	copy(sorted, counts)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Products != sorted[j].Products {
+			return sorted[i].Products > sorted[j].Products
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	byKey := map[string]*MergeGroup{}
+	groups := []*MergeGroup{}
+	for _, c := range sorted {
+		key := Normalize(c.Name)
+		if key == "" {
+			continue
+		}
+		g, ok := byKey[key]
+		if !ok {
+			g = &MergeGroup{Brand: &Brand{Name: c.Name}}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.Variants = append(g.Variants, c.Name)
+		g.Products += c.Products
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Brand.Name < groups[j].Brand.Name })
+	for _, g := range groups {
+		sort.Strings(g.Variants)
+	}
+	return groups
+}
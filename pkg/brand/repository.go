@@ -0,0 +1,165 @@
+package brand
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+const (
+	selectBrandQuery = "SELECT b.id_brand, b.uuid, b.name FROM brand b"
+)
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func (r *repository) List(ctx context.Context) ([]*Brand, error) {
+	return r.query(ctx, selectBrandQuery+" ORDER BY b.name")
+}
+
+func (r *repository) FindByUUID(ctx context.Context, uuid string) (*Brand, error) {
+	return r.findOne(ctx, selectBrandQuery+" WHERE b.uuid = ?", uuid)
+}
+
+func (r *repository) FindByNormalizedName(ctx context.Context, normalized string) (*Brand, error) {
+	return r.findOne(ctx, selectBrandQuery+" WHERE b.normalized_name = ?", normalized)
+}
+
+func (r *repository) Create(ctx context.Context, brand *Brand) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO brand (uuid, name, normalized_name) VALUES(?,?,?)",
+		brand.UUID, brand.Name, Normalize(brand.Name),
+	)
+
+	return err
+}
+
+func (r *repository) Update(ctx context.Context, brand *Brand) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE brand SET name = ?, normalized_name = ? WHERE uuid = ?",
+		brand.Name, Normalize(brand.Name), brand.UUID,
+	)
+	if err != nil {
+		return err
+	}
+
+	// The version moves on so clients holding an ETag see the renamed brand.
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE product p INNER JOIN brand b ON(b.id_brand = p.fk_brand) "+
+			"SET p.brand = b.name, p.version = p.version + 1 WHERE b.uuid = ?",
+		brand.UUID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) Delete(ctx context.Context, uuid string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM brand WHERE uuid = ?", uuid)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *repository) CountProducts(ctx context.Context, uuid string) (int, error) {
+	var products int
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT COUNT(*) FROM product p INNER JOIN brand b ON(b.id_brand = p.fk_brand) WHERE b.uuid = ?",
+		uuid,
+	).Scan(&products)
+	return products, err
+}
+
+func (r *repository) CountUnassignedNames(ctx context.Context) ([]*NameCount, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT brand, COUNT(*) FROM product WHERE fk_brand IS NULL GROUP BY brand")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []*NameCount{}
+	for rows.Next() {
+		c := &NameCount{}
+		if err := rows.Scan(&c.Name, &c.Products); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
+
+func (r *repository) AssignProducts(ctx context.Context, brand *Brand, names []string) (int64, error) {
+	if len(names) == 0 {
+		return 0, nil
+	}
+
+	args := []interface{}{brand.Name, brand.UUID}
+	for _, name := range names {
+		args = append(args, name)
+	}
+	// Like a rename, the version moves on so writes based on an old ETag cannot
+	// overwrite the migrated brand.
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE product SET brand = ?, fk_brand = (SELECT id_brand FROM brand WHERE uuid = ?), version = version + 1 "+
+			"WHERE fk_brand IS NULL AND brand IN (?"+strings.Repeat(",?", len(names)-1)+")",
+		args...,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *repository) findOne(ctx context.Context, query string, args ...interface{}) (*Brand, error) {
+	brands, err := r.query(ctx, query, args...)
+	if err != nil || len(brands) == 0 {
+		return nil, err
+	}
+	return brands[0], nil
+}
+
+func (r *repository) query(ctx context.Context, query string, args ...interface{}) ([]*Brand, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	brands := []*Brand{}
+
+	for rows.Next() {
+		brand := &Brand{}
+		if err := rows.Scan(&brand.BrandID, &brand.UUID, &brand.Name); err != nil {
+			return nil, err
+		}
+		brands = append(brands, brand)
+	}
+
+	return brands, rows.Err()
+}
@@ -0,0 +1,184 @@
+package brand
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type (
+	Service interface {
+		List(ctx context.Context) ([]*Brand, error)
+		FindByUUID(ctx context.Context, uuid string) (*Brand, error)
+		Create(ctx context.Context, brand *Brand) error
+		// Update renames a brand. Products of the brand carry the new name.
+		Update(ctx context.Context, brand *Brand) error
+		// Delete removes a brand no product uses.
+		Delete(ctx context.Context, uuid string) error
+		// Resolve finds the brand given by UUID or by name. An unknown name creates
+		// the brand, an unknown UUID is an error and an empty value resolves to nil.
+		Resolve(ctx context.Context, nameOrUUID string) (*Brand, error)
+		// Migrate moves products without a brand reference onto brands, merging
+		// spellings that only differ in case, spacing or punctuation.
+		Migrate(ctx context.Context, dryRun bool) (*MigrationReport, error)
+	}
+
+	Repository interface {
+		// List returns every brand ordered by name.
+		List(ctx context.Context) ([]*Brand, error)
+		FindByUUID(ctx context.Context, uuid string) (*Brand, error)
+		FindByNormalizedName(ctx context.Context, normalized string) (*Brand, error)
+		Create(ctx context.Context, brand *Brand) error
+		// Update writes brand and renames the products referencing it.
+		Update(ctx context.Context, brand *Brand) error
+		// Delete reports false when there was no such brand.
+		Delete(ctx context.Context, uuid string) (bool, error)
+		CountProducts(ctx context.Context, uuid string) (int, error)
+		// CountUnassignedNames counts the products per brand string among products
+		// that do not reference a brand yet.
+		CountUnassignedNames(ctx context.Context) ([]*NameCount, error)
+		// AssignProducts points unassigned products carrying one of names to brand.
+		AssignProducts(ctx context.Context, brand *Brand, names []string) (int64, error)
+	}
+
+	service struct {
+		repo Repository
+	}
+)
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) List(ctx context.Context) ([]*Brand, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *service) FindByUUID(ctx context.Context, uuid string) (*Brand, error) {
+	brand, err := s.repo.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if brand == nil {
+		return nil, NewBrandNotFoundError(uuid)
+	}
+	return brand, nil
+}
+
+func (s *service) Create(ctx context.Context, brand *Brand) error {
+	if err := brand.validate(); err != nil {
+		return err
+	}
+	if err := s.checkNameFree(ctx, brand); err != nil {
+		return err
+	}
+
+	brand.UUID = uuid.New().String()
+	return s.repo.Create(ctx, brand)
+}
+
+func (s *service) Update(ctx context.Context, brand *Brand) error {
+	if _, err := s.FindByUUID(ctx, brand.UUID); err != nil {
+		return err
+	}
+	if err := brand.validate(); err != nil {
+		return err
+	}
+	if err := s.checkNameFree(ctx, brand); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, brand)
+}
+
+func (s *service) Delete(ctx context.Context, uuid string) error {
+	products, err := s.repo.CountProducts(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if products > 0 {
+		return NewBrandInUseError(uuid, products)
+	}
+	deleted, err := s.repo.Delete(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return NewBrandNotFoundError(uuid)
+	}
+	return nil
+}
+
+func (s *service) Resolve(ctx context.Context, nameOrUUID string) (*Brand, error) {
+	nameOrUUID = strings.TrimSpace(nameOrUUID)
+	if nameOrUUID == "" {
+		return nil, nil
+	}
+	if _, err := uuid.Parse(nameOrUUID); err == nil {
+		return s.FindByUUID(ctx, nameOrUUID)
+	}
+
+	existing, err := s.repo.FindByNormalizedName(ctx, Normalize(nameOrUUID))
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	brand := &Brand{Name: nameOrUUID}
+	if err := s.Create(ctx, brand); err != nil {
+		return nil, err
+	}
+	return brand, nil
+}
+
+func (s *service) Migrate(ctx context.Context, dryRun bool) (*MigrationReport, error) {
+	counts, err := s.repo.CountUnassignedNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MigrationReport{DryRun: dryRun, Groups: planMigration(counts)}
+	for _, g := range report.Groups {
+		existing, err := s.repo.FindByNormalizedName(ctx, Normalize(g.Brand.Name))
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			g.Brand = existing
+		} else {
+			report.Brands++
+			if !dryRun {
+				if err := s.Create(ctx, g.Brand); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if dryRun {
+			report.Products += g.Products
+			continue
+		}
+		assigned, err := s.repo.AssignProducts(ctx, g.Brand, g.Variants)
+		if err != nil {
+			return nil, err
+		}
+		report.Products += int(assigned)
+	}
+
+	return report, nil
+}
+
+// checkNameFree fails when another brand already has the normalized name of brand.
+func (s *service) checkNameFree(ctx context.Context, brand *Brand) error {
+	existing, err := s.repo.FindByNormalizedName(ctx, Normalize(brand.Name))
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.UUID != brand.UUID {
+		return NewBrandConflictError(brand.Name, existing.UUID)
+	}
+	return nil
+}
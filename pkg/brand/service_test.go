@@ -0,0 +1,195 @@
+package brand
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Normalize(t *testing.T) {
+	assert.Equal(t, "shirtsco", Normalize("ShirtsCo"))
+	assert.Equal(t, "shirtsco", Normalize(" Shirts Co. "))
+	assert.Equal(t, "shirtsco", Normalize("shirts-co"))
+	assert.Equal(t, "", Normalize("--"))
+}
+
+func Test_serviceResolve(t *testing.T) {
+	repo := &repositoryMock{}
+	svc := NewService(repo)
+	ctx := context.Background()
+
+	created, err := svc.Resolve(ctx, "Shirts Co")
+	assert.NoError(t, err)
+	assert.Equal(t, "Shirts Co", created.Name)
+	assert.NotEmpty(t, created.UUID)
+
+	same, err := svc.Resolve(ctx, "shirtsco")
+	assert.NoError(t, err)
+	assert.Equal(t, created, same)
+
+	byUUID, err := svc.Resolve(ctx, created.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, created, byUUID)
+
+	_, err = svc.Resolve(ctx, "5a1f6b8e-2c3d-4e5f-8a9b-0c1d2e3f4a5b")
+	assert.Equal(t, NewBrandNotFoundError("5a1f6b8e-2c3d-4e5f-8a9b-0c1d2e3f4a5b"), err)
+
+	none, err := svc.Resolve(ctx, " ")
+	assert.NoError(t, err)
+	assert.Nil(t, none)
+
+	_, err = svc.Resolve(ctx, "&")
+	assert.IsType(t, &InvalidBrandError{}, err)
+	assert.Len(t, repo.brands, 1)
+}
+
+func Test_serviceCRUD(t *testing.T) {
+	repo := &repositoryMock{}
+	svc := NewService(repo)
+	ctx := context.Background()
+
+	shirts := &Brand{Name: "ShirtsCo"}
+	assert.NoError(t, svc.Create(ctx, shirts))
+	tees := &Brand{Name: "TeeCo"}
+	assert.NoError(t, svc.Create(ctx, tees))
+
+	assert.Equal(t, NewBrandConflictError("Shirts Co", shirts.UUID), svc.Create(ctx, &Brand{Name: "Shirts Co"}))
+	assert.Equal(t, NewBrandConflictError("teeco", tees.UUID), svc.Update(ctx, &Brand{UUID: shirts.UUID, Name: "teeco"}))
+
+	// Renaming to another spelling of its own name is allowed.
+	assert.NoError(t, svc.Update(ctx, &Brand{UUID: shirts.UUID, Name: "Shirts Co"}))
+	found, err := svc.FindByUUID(ctx, shirts.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Shirts Co", found.Name)
+
+	repo.products = map[string]int{tees.UUID: 2}
+	assert.Equal(t, NewBrandInUseError(tees.UUID, 2), svc.Delete(ctx, tees.UUID))
+	assert.NoError(t, svc.Delete(ctx, shirts.UUID))
+	assert.Equal(t, NewBrandNotFoundError(shirts.UUID), svc.Delete(ctx, shirts.UUID))
+}
+
+func Test_serviceMigrate(t *testing.T) {
+	repo := &repositoryMock{unassigned: []*NameCount{
+		{Name: "ShirtsCo", Products: 10},
+		{Name: "Shirts Co", Products: 2},
+		{Name: "shirtsco", Products: 2},
+		{Name: "TeeCo", Products: 1},
+		{Name: "Ottana", Products: 2},
+		{Name: "", Products: 1},
+	}}
+	svc := NewService(repo)
+	ctx := context.Background()
+	ottana := &Brand{Name: "OTTANA"}
+	assert.NoError(t, svc.Create(ctx, ottana))
+
+	report, err := svc.Migrate(ctx, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Brands)
+	assert.Equal(t, 17, report.Products)
+	assert.Len(t, repo.brands, 1)
+	assert.Empty(t, repo.assigned)
+
+	report, err = svc.Migrate(ctx, false)
+	assert.NoError(t, err)
+	assert.Len(t, repo.brands, 3)
+	assert.Equal(t, []string{"Ottana"}, repo.assigned[ottana.UUID])
+	assert.Equal(t, []string{"Shirts Co", "ShirtsCo", "shirtsco"}, report.Groups[1].Variants)
+	assert.Equal(t, "ShirtsCo", report.Groups[1].Brand.Name)
+	assert.Equal(t, "OTTANA", report.Groups[0].Brand.Name)
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, report.WriteText(out))
+	assert.Equal(t, "Linked 17 products to 3 brands, 2 of them new\n"+
+		"Merged spellings:\n"+
+		"  ShirtsCo <- Shirts Co, ShirtsCo, shirtsco (14 products)\n", out.String())
+}
+
+func Test_planMigration(t *testing.T) {
+	groups := planMigration([]*NameCount{
+		{Name: "Shirts Co", Products: 3},
+		{Name: "ShirtsCo", Products: 3},
+	})
+	assert.Len(t, groups, 1)
+	assert.Equal(t, "Shirts Co", groups[0].Brand.Name)
+	assert.Equal(t, 6, groups[0].Products)
+}
+
+type repositoryMock struct {
+	brands     []*Brand
+	products   map[string]int
+	unassigned []*NameCount
+	assigned   map[string][]string
+}
+
+func (m *repositoryMock) List(ctx context.Context) ([]*Brand, error) {
+	return m.brands, nil
+}
+
+func (m *repositoryMock) FindByUUID(ctx context.Context, uuid string) (*Brand, error) {
+	return m.find(func(b *Brand) bool { return b.UUID == uuid }), nil
+}
+
+func (m *repositoryMock) FindByNormalizedName(ctx context.Context, normalized string) (*Brand, error) {
+	return m.find(func(b *Brand) bool { return Normalize(b.Name) == normalized }), nil
+}
+
+func (m *repositoryMock) Create(ctx context.Context, brand *Brand) error {
+	cp := *brand
+	m.brands = append(m.brands, &cp)
+	return nil
+}
+
+func (m *repositoryMock) Update(ctx context.Context, brand *Brand) error {
+	for _, b := range m.brands {
+		if b.UUID == brand.UUID {
+			b.Name = brand.Name
+		}
+	}
+	return nil
+}
+
+func (m *repositoryMock) Delete(ctx context.Context, uuid string) (bool, error) {
+	for i, b := range m.brands {
+		if b.UUID == uuid {
+			m.brands = append(m.brands[:i], m.brands[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *repositoryMock) CountProducts(ctx context.Context, uuid string) (int, error) {
+	return m.products[uuid], nil
+}
+
+func (m *repositoryMock) CountUnassignedNames(ctx context.Context) ([]*NameCount, error) {
+	return m.unassigned, nil
+}
+
+func (m *repositoryMock) AssignProducts(ctx context.Context, brand *Brand, names []string) (int64, error) {
+	if m.assigned == nil {
+		m.assigned = map[string][]string{}
+	}
+	m.assigned[brand.UUID] = names
+	var assigned int64
+	for _, c := range m.unassigned {
+		for _, name := range names {
+			if c.Name == name {
+				assigned += int64(c.Products)
+			}
+		}
+	}
+	return assigned, nil
+}
+
+func (m *repositoryMock) find(match func(b *Brand) bool) *Brand {
+	for _, b := range m.brands {
+		if match(b) {
+			cp := *b
+			return &cp
+		}
+	}
+	return nil
+}
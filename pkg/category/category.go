@@ -0,0 +1,97 @@
+package category
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"coding-challenge-go/pkg/jsonschema"
+)
+
+const (
+	maxNameLength = 200
+	maxSlugLength = 64
+	// maxPathLength matches the path column, which holds the slugs from the root down.
+	maxPathLength = 255
+)
+
+// slugPattern keeps slugs safe to join into paths and to match with LIKE.
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type Category struct {
+	CategoryID int    `json:"-"`
+	UUID       string `json:"uuid"`
+	Name       string `json:"name"`
+	Slug       string `json:"slug"`
+	// ParentUUID is empty for a root category.
+	ParentUUID string `json:"parent_uuid,omitempty"`
+	// Path is the slugs from the root down to this category, e.g. "men/clothing/shirts".
+	Path string `json:"path"`
+	// AttributeSchema is an optional JSON Schema the attributes of products in
+	// this category must satisfy.
+	AttributeSchema json.RawMessage `json:"attribute_schema,omitempty"`
+	Children        []*Category     `json:"children,omitempty"`
+	// Ancestors lists the categories from the root down to the parent. It is only
+	// filled for product assignments, which render them as breadcrumbs.
+	Ancestors []*Category `json:"-"`
+}
+
+// childPath returns the path of a child of parent with slug. A nil parent means a root category.
+func childPath(parent *Category, slug string) string {
+	if parent == nil {
+		return slug
+	}
+	return parent.Path + "/" + slug
+}
+
+// IsDescendantOf reports whether c is below other in the tree.
+func (c *Category) IsDescendantOf(other *Category) bool {
+	return strings.HasPrefix(c.Path, other.Path+"/")
+}
+
+func (c *Category) validate() error {
+	if c.Name == "" || len(c.Name) > maxNameLength {
+		return NewInvalidCategoryError(fmt.Sprintf("name must be between 1 and %d characters", maxNameLength))
+	}
+	if len(c.Slug) > maxSlugLength || !slugPattern.MatchString(c.Slug) {
+		return NewInvalidCategoryError(fmt.Sprintf("slug must be at most %d lowercase letters, digits and single dashes", maxSlugLength))
+	}
+	if len(c.Path) > maxPathLength {
+		return NewInvalidCategoryError(fmt.Sprintf("path must be at most %d characters", maxPathLength))
+	}
+	if _, err := c.Schema(); err != nil {
+		return NewInvalidCategoryError(fmt.Sprintf("attribute_schema: %s", err.Error()))
+	}
+	return nil
+}
+
+// Schema returns the parsed attribute schema, or nil when the category has none.
+func (c *Category) Schema() (*jsonschema.Schema, error) {
+	if len(c.AttributeSchema) == 0 || string(c.AttributeSchema) == "null" {
+		return nil, nil
+	}
+	schema, err := jsonschema.Parse(c.AttributeSchema)
+	if err != nil {
+		return nil, err
+	}
+	if schema.Type != "" && schema.Type != "object" {
+		return nil, fmt.Errorf("must describe an object")
+	}
+	return schema, nil
+}
+
+// buildTree nests categories ordered by path under their parents and returns the roots.
+func buildTree(categories []*Category) []*Category {
+	byUUID := make(map[string]*Category, len(categories))
+	roots := []*Category{}
+	for _, c := range categories {
+		byUUID[c.UUID] = c
+		if parent, ok := byUUID[c.ParentUUID]; ok {
+			parent.Children = append(parent.Children, c)
+		} else {
+			roots = append(roots, c)
+		}
+	}
+	return roots
+}
@@ -0,0 +1,59 @@
+package category
+
+import "fmt"
+
+type CategoryNotFoundError struct {
+	id string
+}
+
+func (e CategoryNotFoundError) Error() string {
+	return fmt.Sprintf("Category is not found with id=%s", e.id)
+}
+
+func NewCategoryNotFoundError(uuid string) error {
+	return &CategoryNotFoundError{
+		id: uuid,
+	}
+}
+
+type InvalidCategoryError struct {
+	reason string
+}
+
+func (e InvalidCategoryError) Error() string {
+	return fmt.Sprintf("Category is invalid: %s", e.reason)
+}
+
+func NewInvalidCategoryError(reason string) error {
+	return &InvalidCategoryError{
+		reason: reason,
+	}
+}
+
+type CategoryPathConflictError struct {
+	path string
+}
+
+func (e CategoryPathConflictError) Error() string {
+	return fmt.Sprintf("Category already exists with path=%s", e.path)
+}
+
+func NewCategoryPathConflictError(path string) error {
+	return &CategoryPathConflictError{
+		path: path,
+	}
+}
+
+type CategoryNotEmptyError struct {
+	id string
+}
+
+func (e CategoryNotEmptyError) Error() string {
+	return fmt.Sprintf("Category id=%s has child categories and cannot be deleted", e.id)
+}
+
+func NewCategoryNotEmptyError(uuid string) error {
+	return &CategoryNotEmptyError{
+		id: uuid,
+	}
+}
@@ -0,0 +1,266 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+const (
+	selectCategoryQuery = "SELECT c.id_category, c.uuid, c.name, c.slug, COALESCE(parent.uuid, ''), c.path, c.attribute_schema FROM category c " +
+		"LEFT JOIN category parent ON(parent.id_category = c.fk_parent)"
+)
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func (r *repository) List(ctx context.Context) ([]*Category, error) {
+	return r.query(ctx, selectCategoryQuery+" ORDER BY c.path")
+}
+
+func (r *repository) FindByUUID(ctx context.Context, uuid string) (*Category, error) {
+	return r.findOne(ctx, selectCategoryQuery+" WHERE c.uuid = ?", uuid)
+}
+
+func (r *repository) FindByPath(ctx context.Context, path string) (*Category, error) {
+	return r.findOne(ctx, selectCategoryQuery+" WHERE c.path = ?", path)
+}
+
+func (r *repository) Create(ctx context.Context, category *Category) error {
+	parentID, err := r.parentID(ctx, category.ParentUUID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		"INSERT INTO category (uuid, name, slug, fk_parent, path, attribute_schema) VALUES(?,?,?,?,?,?)",
+		category.UUID, category.Name, category.Slug, parentID, category.Path, schemaValue(category),
+	)
+
+	return err
+}
+
+func (r *repository) Update(ctx context.Context, category *Category, oldPath string) error {
+	parentID, err := r.parentID(ctx, category.ParentUUID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE category SET name = ?, slug = ?, fk_parent = ?, attribute_schema = ? WHERE uuid = ?",
+		category.Name, category.Slug, parentID, schemaValue(category), category.UUID,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Slugs cannot contain LIKE wildcards, so the old path is safe to use as a prefix.
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE category SET path = CONCAT(?, SUBSTRING(path, ?)) WHERE path = ? OR path LIKE ?",
+		category.Path, len(oldPath)+1, oldPath, oldPath+"/%",
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) Delete(ctx context.Context, uuid string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM category WHERE uuid = ?", uuid)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *repository) HasChildren(ctx context.Context, uuid string) (bool, error) {
+	var children int
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT COUNT(*) FROM category c INNER JOIN category parent ON(parent.id_category = c.fk_parent) WHERE parent.uuid = ?",
+		uuid,
+	).Scan(&children)
+	return children > 0, err
+}
+
+func (r *repository) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*Category, error) {
+	assigned := map[string][]*Category{}
+	if len(productUUIDs) == 0 {
+		return assigned, nil
+	}
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT p.uuid, c.id_category, c.uuid, c.name, c.slug, COALESCE(parent.uuid, ''), c.path, c.attribute_schema FROM product_category pc "+
+			"INNER JOIN product p ON(p.id_product = pc.fk_product) "+
+			"INNER JOIN category c ON(c.id_category = pc.fk_category) "+
+			"LEFT JOIN category parent ON(parent.id_category = c.fk_parent) "+
+			"WHERE p.uuid IN ("+placeholders(len(productUUIDs))+") ORDER BY c.path",
+		stringArgs(productUUIDs)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ancestorPaths []string
+	for rows.Next() {
+		var productUUID string
+		category := &Category{}
+		err := rows.Scan(&productUUID, &category.CategoryID, &category.UUID, &category.Name, &category.Slug, &category.ParentUUID, &category.Path, (*[]byte)(&category.AttributeSchema))
+		if err != nil {
+			return nil, err
+		}
+		assigned[productUUID] = append(assigned[productUUID], category)
+		ancestorPaths = append(ancestorPaths, parentPaths(category.Path)...)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ancestorPaths) == 0 {
+		return assigned, nil
+	}
+	ancestors, err := r.query(ctx, selectCategoryQuery+" WHERE c.path IN ("+placeholders(len(ancestorPaths))+")", stringArgs(ancestorPaths)...)
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]*Category, len(ancestors))
+	for _, a := range ancestors {
+		byPath[a.Path] = a
+	}
+	for _, categories := range assigned {
+		for _, category := range categories {
+			for _, path := range parentPaths(category.Path) {
+				category.Ancestors = append(category.Ancestors, byPath[path])
+			}
+		}
+	}
+
+	return assigned, nil
+}
+
+func (r *repository) SetProductCategories(ctx context.Context, productUUID string, categoryUUIDs []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		"DELETE pc FROM product_category pc INNER JOIN product p ON(p.id_product = pc.fk_product) WHERE p.uuid = ?",
+		productUUID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(categoryUUIDs) > 0 {
+		_, err = tx.ExecContext(
+			ctx,
+			"INSERT INTO product_category (fk_product, fk_category) SELECT p.id_product, c.id_category FROM product p "+
+				"INNER JOIN category c ON(c.uuid IN ("+placeholders(len(categoryUUIDs))+")) WHERE p.uuid = ?",
+			append(stringArgs(categoryUUIDs), productUUID)...,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// parentID returns the id of the parent category, or nil for a root category.
+func (r *repository) parentID(ctx context.Context, parentUUID string) (interface{}, error) {
+	if parentUUID == "" {
+		return nil, nil
+	}
+	var id int
+	err := r.db.QueryRowContext(ctx, "SELECT id_category FROM category WHERE uuid = ?", parentUUID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, NewCategoryNotFoundError(parentUUID)
+	}
+	return id, err
+}
+
+func (r *repository) findOne(ctx context.Context, query string, args ...interface{}) (*Category, error) {
+	categories, err := r.query(ctx, query, args...)
+	if err != nil || len(categories) == 0 {
+		return nil, err
+	}
+	return categories[0], nil
+}
+
+func (r *repository) query(ctx context.Context, query string, args ...interface{}) ([]*Category, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	categories := []*Category{}
+
+	for rows.Next() {
+		category := &Category{}
+		err := rows.Scan(&category.CategoryID, &category.UUID, &category.Name, &category.Slug, &category.ParentUUID, &category.Path, (*[]byte)(&category.AttributeSchema))
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+// schemaValue returns the attribute schema to store, NULL when there is none.
+func schemaValue(category *Category) interface{} {
+	if len(category.AttributeSchema) == 0 || string(category.AttributeSchema) == "null" {
+		return nil
+	}
+	return []byte(category.AttributeSchema)
+}
+
+// parentPaths returns the paths above path from the root down, e.g. "men" and
+// "men/clothing" for "men/clothing/shirts".
+func parentPaths(path string) []string {
+	slugs := strings.Split(path, "/")
+	paths := make([]string, 0, len(slugs)-1)
+	for i := 1; i < len(slugs); i++ {
+		paths = append(paths, strings.Join(slugs[:i], "/"))
+	}
+	return paths
+}
+
+func placeholders(n int) string {
+	return "?" + strings.Repeat(",?", n-1)
+}
+
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
@@ -0,0 +1,145 @@
+package category
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type (
+	Service interface {
+		// Tree returns every category nested under its parent.
+		Tree(ctx context.Context) ([]*Category, error)
+		FindByUUID(ctx context.Context, uuid string) (*Category, error)
+		// Create adds category below category.ParentUUID, or as a root when it is empty.
+		Create(ctx context.Context, category *Category) error
+		// Update renames category or moves it below another parent. Its descendants move along.
+		Update(ctx context.Context, category *Category) error
+		// Delete removes a category without children. Products assigned to it lose the assignment.
+		Delete(ctx context.Context, uuid string) error
+	}
+
+	Repository interface {
+		// List returns every category ordered by path, so parents come before their children.
+		List(ctx context.Context) ([]*Category, error)
+		FindByUUID(ctx context.Context, uuid string) (*Category, error)
+		FindByPath(ctx context.Context, path string) (*Category, error)
+		Create(ctx context.Context, category *Category) error
+		// Update writes category and rewrites the paths below oldPath to start with category.Path.
+		Update(ctx context.Context, category *Category, oldPath string) error
+		// Delete reports false when there was no such category.
+		Delete(ctx context.Context, uuid string) (bool, error)
+		HasChildren(ctx context.Context, uuid string) (bool, error)
+		// ListByProducts returns the categories assigned to each product, ordered by
+		// path and with their ancestors filled in.
+		ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*Category, error)
+		// SetProductCategories replaces the categories assigned to a product.
+		SetProductCategories(ctx context.Context, productUUID string, categoryUUIDs []string) error
+	}
+
+	service struct {
+		repo Repository
+	}
+)
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Tree(ctx context.Context) ([]*Category, error) {
+	categories, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildTree(categories), nil
+}
+
+func (s *service) FindByUUID(ctx context.Context, uuid string) (*Category, error) {
+	category, err := s.repo.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, NewCategoryNotFoundError(uuid)
+	}
+	return category, nil
+}
+
+func (s *service) Create(ctx context.Context, category *Category) error {
+	parent, err := s.findParent(ctx, category.ParentUUID)
+	if err != nil {
+		return err
+	}
+	category.Path = childPath(parent, category.Slug)
+	if err := category.validate(); err != nil {
+		return err
+	}
+	if err := s.checkPathFree(ctx, category.Path); err != nil {
+		return err
+	}
+
+	category.UUID = uuid.New().String()
+	return s.repo.Create(ctx, category)
+}
+
+func (s *service) Update(ctx context.Context, category *Category) error {
+	existing, err := s.FindByUUID(ctx, category.UUID)
+	if err != nil {
+		return err
+	}
+	parent, err := s.findParent(ctx, category.ParentUUID)
+	if err != nil {
+		return err
+	}
+	if parent != nil && (parent.UUID == existing.UUID || parent.IsDescendantOf(existing)) {
+		return NewInvalidCategoryError("a category cannot be moved below itself")
+	}
+	category.Path = childPath(parent, category.Slug)
+	if err := category.validate(); err != nil {
+		return err
+	}
+	if category.Path != existing.Path {
+		if err := s.checkPathFree(ctx, category.Path); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.Update(ctx, category, existing.Path)
+}
+
+func (s *service) Delete(ctx context.Context, uuid string) error {
+	hasChildren, err := s.repo.HasChildren(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return NewCategoryNotEmptyError(uuid)
+	}
+	deleted, err := s.repo.Delete(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return NewCategoryNotFoundError(uuid)
+	}
+	return nil
+}
+
+// findParent returns the parent category, or nil for a root category.
+func (s *service) findParent(ctx context.Context, parentUUID string) (*Category, error) {
+	if parentUUID == "" {
+		return nil, nil
+	}
+	return s.FindByUUID(ctx, parentUUID)
+}
+
+func (s *service) checkPathFree(ctx context.Context, path string) error {
+	existing, err := s.repo.FindByPath(ctx, path)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return NewCategoryPathConflictError(path)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package category
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_serviceTree(t *testing.T) {
+	repo := &repositoryMock{}
+	svc := NewService(repo)
+	ctx := context.Background()
+
+	men := &Category{Name: "Men", Slug: "men"}
+	assert.NoError(t, svc.Create(ctx, men))
+	clothing := &Category{Name: "Clothing", Slug: "clothing", ParentUUID: men.UUID}
+	assert.NoError(t, svc.Create(ctx, clothing))
+	shirts := &Category{Name: "Shirts", Slug: "shirts", ParentUUID: clothing.UUID}
+	assert.NoError(t, svc.Create(ctx, shirts))
+	assert.Equal(t, "men/clothing/shirts", shirts.Path)
+
+	err := svc.Create(ctx, &Category{Name: "Shirts", Slug: "shirts", ParentUUID: clothing.UUID})
+	assert.Equal(t, NewCategoryPathConflictError("men/clothing/shirts"), err)
+
+	err = svc.Create(ctx, &Category{Name: "Tops", Slug: "Tops_2"})
+	assert.IsType(t, &InvalidCategoryError{}, err)
+
+	err = svc.Create(ctx, &Category{Name: "Tops", Slug: "tops", ParentUUID: "missing"})
+	assert.Equal(t, NewCategoryNotFoundError("missing"), err)
+
+	tree, err := svc.Tree(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, tree, 1)
+	assert.Equal(t, "shirts", tree[0].Children[0].Children[0].Slug)
+
+	err = svc.Update(ctx, &Category{UUID: men.UUID, Name: "Men", Slug: "men", ParentUUID: shirts.UUID})
+	assert.Equal(t, NewInvalidCategoryError("a category cannot be moved below itself"), err)
+
+	// Moving clothing to the root takes shirts along.
+	assert.NoError(t, svc.Update(ctx, &Category{UUID: clothing.UUID, Name: "Clothing", Slug: "apparel"}))
+	moved, err := svc.FindByUUID(ctx, shirts.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, "apparel/shirts", moved.Path)
+
+	assert.Equal(t, NewCategoryNotEmptyError(clothing.UUID), svc.Delete(ctx, clothing.UUID))
+	assert.NoError(t, svc.Delete(ctx, shirts.UUID))
+	assert.NoError(t, svc.Delete(ctx, clothing.UUID))
+	assert.Equal(t, NewCategoryNotFoundError(clothing.UUID), svc.Delete(ctx, clothing.UUID))
+}
+
+func Test_parentPaths(t *testing.T) {
+	assert.Equal(t, []string{"men", "men/clothing"}, parentPaths("men/clothing/shirts"))
+	assert.Equal(t, []string{}, parentPaths("men"))
+}
+
+type repositoryMock struct {
+	categories []*Category
+}
+
+func (m *repositoryMock) List(ctx context.Context) ([]*Category, error) {
+	categories := []*Category{}
+	for _, c := range m.categories {
+		cp := *c
+		categories = append(categories, &cp)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Path < categories[j].Path })
+	return categories, nil
+}
+
+func (m *repositoryMock) FindByUUID(ctx context.Context, uuid string) (*Category, error) {
+	return m.find(func(c *Category) bool { return c.UUID == uuid }), nil
+}
+
+func (m *repositoryMock) FindByPath(ctx context.Context, path string) (*Category, error) {
+	return m.find(func(c *Category) bool { return c.Path == path }), nil
+}
+
+func (m *repositoryMock) Create(ctx context.Context, category *Category) error {
+	cp := *category
+	m.categories = append(m.categories, &cp)
+	return nil
+}
+
+func (m *repositoryMock) Update(ctx context.Context, category *Category, oldPath string) error {
+	for _, c := range m.categories {
+		if c.UUID == category.UUID {
+			c.Name, c.Slug, c.ParentUUID = category.Name, category.Slug, category.ParentUUID
+		}
+		if c.Path == oldPath || strings.HasPrefix(c.Path, oldPath+"/") {
+			c.Path = category.Path + strings.TrimPrefix(c.Path, oldPath)
+		}
+	}
+	return nil
+}
+
+func (m *repositoryMock) Delete(ctx context.Context, uuid string) (bool, error) {
+	for i, c := range m.categories {
+		if c.UUID == uuid {
+			m.categories = append(m.categories[:i], m.categories[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *repositoryMock) HasChildren(ctx context.Context, uuid string) (bool, error) {
+	return m.find(func(c *Category) bool { return c.ParentUUID == uuid }) != nil, nil
+}
+
+func (m *repositoryMock) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*Category, error) {
+	return map[string][]*Category{}, nil
+}
+
+func (m *repositoryMock) SetProductCategories(ctx context.Context, productUUID string, categoryUUIDs []string) error {
+	return nil
+}
+
+func (m *repositoryMock) find(match func(c *Category) bool) *Category {
+	for _, c := range m.categories {
+		if match(c) {
+			cp := *c
+			return &cp
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package idempotency
+
+import "fmt"
+
+type InvalidKeyError struct {
+	reason string
+}
+
+func (e InvalidKeyError) Error() string {
+	return fmt.Sprintf("Idempotency-Key is invalid: %s", e.reason)
+}
+
+func NewInvalidKeyError(reason string) error {
+	return &InvalidKeyError{
+		reason: reason,
+	}
+}
+
+type KeyMismatchError struct {
+	key string
+}
+
+func (e KeyMismatchError) Error() string {
+	return fmt.Sprintf("Idempotency-Key %s was already used for a different request", e.key)
+}
+
+func NewKeyMismatchError(key string) error {
+	return &KeyMismatchError{
+		key: key,
+	}
+}
+
+type KeyInProgressError struct {
+	key string
+}
+
+func (e KeyInProgressError) Error() string {
+	return fmt.Sprintf("A request with Idempotency-Key %s is still in progress", e.key)
+}
+
+func NewKeyInProgressError(key string) error {
+	return &KeyInProgressError{
+		key: key,
+	}
+}
@@ -0,0 +1,25 @@
+package idempotency
+
+import (
+	"net/http"
+	"time"
+)
+
+// Response is the stored answer to the first request sent with a key.
+type Response struct {
+	StatusCode  int
+	ContentType string
+	// Header holds the headers a retry gets replayed, such as ETag and Location.
+	Header http.Header
+	Body   []byte
+}
+
+// Record ties a key to the request it was first sent with.
+type Record struct {
+	Key string
+	// Fingerprint identifies the request, so a key reused for another request is caught.
+	Fingerprint string
+	// Response is nil while the first request is still being handled.
+	Response  *Response
+	ExpiresAt time.Time
+}
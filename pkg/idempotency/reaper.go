@@ -0,0 +1,25 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/periodic"
+)
+
+// Reap deletes expired keys every interval until ctx is done.
+func Reap(ctx context.Context, svc Service, interval time.Duration) {
+	periodic.Run(ctx, "idempotency key reaper", interval, func(ctx context.Context) {
+		expired, err := svc.ExpireStale(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("Fail to delete expired idempotency keys")
+			return
+		}
+		if expired > 0 {
+			log.Info().Msg(fmt.Sprintf("Deleted %d expired idempotency keys", expired))
+		}
+	})
+}
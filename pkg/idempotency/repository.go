@@ -0,0 +1,102 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	// errDuplicateEntry is the MySQL error number of a unique key violation.
+	errDuplicateEntry = 1062
+)
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func (r *repository) Create(ctx context.Context, key string, fingerprint string, ttlSeconds int) (bool, error) {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM idempotency_record WHERE idempotency_key = ? AND expires_at <= UTC_TIMESTAMP()", key)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		"INSERT INTO idempotency_record (idempotency_key, fingerprint, expires_at) VALUES(?,?,DATE_ADD(UTC_TIMESTAMP(), INTERVAL ? SECOND))",
+		key, fingerprint, ttlSeconds,
+	)
+	if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == errDuplicateEntry {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *repository) Find(ctx context.Context, key string) (*Record, error) {
+	record := &Record{Key: key}
+	var statusCode sql.NullInt64
+	var contentType sql.NullString
+	var headers, body []byte
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT fingerprint, status_code, content_type, headers, body, expires_at FROM idempotency_record "+
+			"WHERE idempotency_key = ? AND expires_at > UTC_TIMESTAMP()",
+		key,
+	).Scan(&record.Fingerprint, &statusCode, &contentType, &headers, &body, &record.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode.Valid {
+		record.Response = &Response{
+			StatusCode:  int(statusCode.Int64),
+			ContentType: contentType.String,
+			Body:        body,
+		}
+		if headers != nil {
+			if err := json.Unmarshal(headers, &record.Response.Header); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return record, nil
+}
+
+func (r *repository) Complete(ctx context.Context, key string, response *Response) error {
+	headers, err := json.Marshal(response.Header)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		"UPDATE idempotency_record SET status_code = ?, content_type = ?, headers = ?, body = ? WHERE idempotency_key = ?",
+		response.StatusCode, response.ContentType, headers, response.Body, key,
+	)
+	return err
+}
+
+func (r *repository) Delete(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM idempotency_record WHERE idempotency_key = ?", key)
+	return err
+}
+
+func (r *repository) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM idempotency_record WHERE expires_at <= UTC_TIMESTAMP()")
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
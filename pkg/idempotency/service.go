@@ -0,0 +1,121 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"coding-challenge-go/pkg/requestinfo"
+)
+
+const (
+	maxKeyLength = 255
+)
+
+type (
+	Service interface {
+		// Keys are scoped to the actor of ctx, so clients that name themselves apart
+		// do not collide on a key. The actor is not authenticated, so the scope is no
+		// security boundary: a retry is only replayed the response to the very same
+		// request, whoever sends it.
+		//
+		// Begin claims key for the request with fingerprint. It returns the stored
+		// response when the key was used for the same request before. Otherwise it
+		// returns nil and the caller handles the request, then calls Complete or Release.
+		Begin(ctx context.Context, key string, fingerprint string) (*Response, error)
+		// Complete stores the response to replay for retries until the key expires.
+		Complete(ctx context.Context, key string, response *Response) error
+		// Release forgets the key so the request can be retried, e.g. after a server error.
+		Release(ctx context.Context, key string) error
+		// ExpireStale deletes every key past its expiry.
+		ExpireStale(ctx context.Context) (int64, error)
+	}
+
+	Repository interface {
+		// Create stores a record without response. Expired records of the key are
+		// replaced. It reports false when an unexpired record of the key exists.
+		Create(ctx context.Context, key string, fingerprint string, ttlSeconds int) (bool, error)
+		// Find returns the unexpired record of key, or nil.
+		Find(ctx context.Context, key string) (*Record, error)
+		Complete(ctx context.Context, key string, response *Response) error
+		Delete(ctx context.Context, key string) error
+		DeleteExpired(ctx context.Context) (int64, error)
+	}
+
+	service struct {
+		repo Repository
+		ttl  time.Duration
+	}
+)
+
+// NewService keeps keys for ttl after the first request that used them.
+func NewService(repo Repository, ttl time.Duration) Service {
+	return &service{
+		repo: repo,
+		ttl:  ttl,
+	}
+}
+
+func (s *service) Begin(ctx context.Context, key string, fingerprint string) (*Response, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(ctx, scopedKey(ctx, key), fingerprint, int(s.ttl/time.Second))
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		return nil, nil
+	}
+
+	record, err := s.repo.Find(ctx, scopedKey(ctx, key))
+	if err != nil {
+		return nil, err
+	}
+	// The record expired between Create and Find; the retry will claim the key.
+	if record == nil {
+		return nil, NewKeyInProgressError(key)
+	}
+	if record.Fingerprint != fingerprint {
+		return nil, NewKeyMismatchError(key)
+	}
+	if record.Response == nil {
+		return nil, NewKeyInProgressError(key)
+	}
+	return record.Response, nil
+}
+
+func (s *service) Complete(ctx context.Context, key string, response *Response) error {
+	return s.repo.Complete(ctx, scopedKey(ctx, key), response)
+}
+
+func (s *service) Release(ctx context.Context, key string) error {
+	return s.repo.Delete(ctx, scopedKey(ctx, key))
+}
+
+func (s *service) ExpireStale(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpired(ctx)
+}
+
+// validateKey accepts 1 to 255 printable ASCII characters, which covers UUIDs and
+// the random strings clients usually send.
+func validateKey(key string) error {
+	if len(key) == 0 || len(key) > maxKeyLength {
+		return NewInvalidKeyError("must be between 1 and 255 characters")
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return NewInvalidKeyError("must be printable ASCII without spaces")
+		}
+	}
+	return nil
+}
+
+// scopedKey is the stored form of key: a hash of the actor and the key, which
+// fits the column whatever the length of both.
+func scopedKey(ctx context.Context, key string) string {
+	h := sha256.Sum256([]byte(requestinfo.Actor(ctx) + "\n" + key))
+	return hex.EncodeToString(h[:])
+}
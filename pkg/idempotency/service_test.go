@@ -0,0 +1,86 @@
+package idempotency
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/requestinfo"
+)
+
+func Test_validateKey(t *testing.T) {
+	assert.NoError(t, validateKey("5f1c7a52-0b7d-4c8e-9d3a-6f2e1b4c8a90"))
+	assert.Equal(t, NewInvalidKeyError("must be between 1 and 255 characters"), validateKey(strings.Repeat("k", 256)))
+	assert.Equal(t, NewInvalidKeyError("must be printable ASCII without spaces"), validateKey("order 42"))
+}
+
+func Test_serviceBegin(t *testing.T) {
+	repo := &repositoryMock{records: map[string]*Record{}}
+	svc := NewService(repo, time.Hour)
+	ctx := context.Background()
+
+	response, err := svc.Begin(ctx, "k1", "f1")
+	assert.NoError(t, err)
+	assert.Nil(t, response)
+	assert.Equal(t, 3600, repo.ttlSeconds)
+
+	_, err = svc.Begin(ctx, "k1", "f1")
+	assert.Equal(t, NewKeyInProgressError("k1"), err)
+
+	stored := &Response{StatusCode: 200, ContentType: "application/json; charset=utf-8", Body: []byte(`{"uuid":"p1"}`)}
+	assert.NoError(t, svc.Complete(ctx, "k1", stored))
+
+	response, err = svc.Begin(ctx, "k1", "f1")
+	assert.NoError(t, err)
+	assert.Equal(t, stored, response)
+
+	_, err = svc.Begin(ctx, "k1", "f2")
+	assert.Equal(t, NewKeyMismatchError("k1"), err)
+
+	// A released key can be claimed again, even by another request.
+	assert.NoError(t, svc.Release(ctx, "k1"))
+	response, err = svc.Begin(ctx, "k1", "f2")
+	assert.NoError(t, err)
+	assert.Nil(t, response)
+
+	// Another actor has keys of its own.
+	response, err = svc.Begin(requestinfo.WithActor(ctx, "alice"), "k1", "f1")
+	assert.NoError(t, err)
+	assert.Nil(t, response)
+	assert.Len(t, repo.records, 2)
+}
+
+type repositoryMock struct {
+	records    map[string]*Record
+	ttlSeconds int
+}
+
+func (m *repositoryMock) Create(ctx context.Context, key string, fingerprint string, ttlSeconds int) (bool, error) {
+	if _, ok := m.records[key]; ok {
+		return false, nil
+	}
+	m.ttlSeconds = ttlSeconds
+	m.records[key] = &Record{Key: key, Fingerprint: fingerprint}
+	return true, nil
+}
+
+func (m *repositoryMock) Find(ctx context.Context, key string) (*Record, error) {
+	return m.records[key], nil
+}
+
+func (m *repositoryMock) Complete(ctx context.Context, key string, response *Response) error {
+	m.records[key].Response = response
+	return nil
+}
+
+func (m *repositoryMock) Delete(ctx context.Context, key string) error {
+	delete(m.records, key)
+	return nil
+}
+
+func (m *repositoryMock) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
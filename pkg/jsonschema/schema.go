@@ -0,0 +1,202 @@
+// Package jsonschema validates JSON values against the subset of JSON Schema
+// that describes flat records: types, enums, bounds, lengths, patterns and the
+// properties of objects.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a parsed schema. Keywords outside the supported subset are rejected
+// when parsing, so a schema never silently accepts values it looks like it forbids.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+
+	// Annotations are accepted but have no effect on validation.
+	SchemaURI   string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+var types = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true, "null": true,
+}
+
+// Parse reads a schema document.
+func Parse(data []byte) (*Schema, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	schema := &Schema{}
+	if err := dec.Decode(schema); err != nil {
+		return nil, fmt.Errorf("schema is not supported: %s", strings.TrimPrefix(err.Error(), "json: "))
+	}
+	if err := schema.compile(""); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (s *Schema) compile(path string) error {
+	if s.Type != "" && !types[s.Type] {
+		return fmt.Errorf("%s: unknown type %q", location(path), s.Type)
+	}
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern: %s", location(path), err.Error())
+		}
+		s.pattern = pattern
+	}
+	for name, property := range s.Properties {
+		if property == nil {
+			return fmt.Errorf("%s: property %s has no schema", location(path), name)
+		}
+		if err := property.compile(join(path, name)); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile(path + "[]")
+	}
+	return nil
+}
+
+// Validate checks value, as decoded by encoding/json, against the schema and
+// returns the first violation found.
+func (s *Schema) Validate(value interface{}) error {
+	return s.validate("", value)
+}
+
+func (s *Schema) validate(path string, value interface{}) error {
+	if s.Type != "" && !hasType(value, s.Type) {
+		return fmt.Errorf("%s must be of type %s", location(path), s.Type)
+	}
+	if len(s.Enum) > 0 && !inEnum(value, s.Enum) {
+		return fmt.Errorf("%s must be one of %s", location(path), enumString(s.Enum))
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			return fmt.Errorf("%s must be at least %d characters", location(path), *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			return fmt.Errorf("%s must be at most %d characters", location(path), *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			return fmt.Errorf("%s must match %s", location(path), s.Pattern)
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			return fmt.Errorf("%s must be at least %v", location(path), *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			return fmt.Errorf("%s must be at most %v", location(path), *s.Maximum)
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		return s.validateObject(path, v)
+	}
+	return nil
+}
+
+func (s *Schema) validateObject(path string, object map[string]interface{}) error {
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s is required", location(join(path, name)))
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := s.Properties[name]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				return fmt.Errorf("%s is not allowed", location(join(path, name)))
+			}
+			continue
+		}
+		if err := property.validate(join(path, name), object[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasType(value interface{}, typ string) bool {
+	switch v := value.(type) {
+	case nil:
+		return typ == "null"
+	case bool:
+		return typ == "boolean"
+	case string:
+		return typ == "string"
+	case float64:
+		return typ == "number" || (typ == "integer" && v == math.Trunc(v))
+	case []interface{}:
+		return typ == "array"
+	case map[string]interface{}:
+		return typ == "object"
+	}
+	return false
+}
+
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(value, e) {
+			return true
+		}
+	}
+	return false
+}
+
+func enumString(enum []interface{}) string {
+	data, _ := json.Marshal(enum)
+	return string(data)
+}
+
+func join(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func location(path string) string {
+	if path == "" {
+		return "value"
+	}
+	return path
+}
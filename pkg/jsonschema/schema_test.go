@@ -0,0 +1,59 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Parse(t *testing.T) {
+	_, err := Parse([]byte(`{"type":"object","properties":{"voltage":{"type":"integer","minimum":0}}}`))
+	assert.NoError(t, err)
+
+	_, err = Parse([]byte(`{"type":"object","oneOf":[]}`))
+	assert.EqualError(t, err, `schema is not supported: unknown field "oneOf"`)
+
+	_, err = Parse([]byte(`{"properties":{"size":{"type":"text"}}}`))
+	assert.EqualError(t, err, `size: unknown type "text"`)
+
+	_, err = Parse([]byte(`{"properties":{"size":{"pattern":"("}}}`))
+	assert.Error(t, err)
+}
+
+func Test_SchemaValidate(t *testing.T) {
+	schema, err := Parse([]byte(`{
+		"type": "object",
+		"required": ["material"],
+		"additionalProperties": false,
+		"properties": {
+			"material": {"type": "string", "enum": ["cotton", "linen"]},
+			"voltage": {"type": "integer", "minimum": 100, "maximum": 240},
+			"color": {"type": "string", "maxLength": 10, "pattern": "^[a-z]+$"}
+		}
+	}`))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		value string
+		want  error
+	}{
+		{name: "valid", value: `{"material":"cotton","voltage":220,"color":"blue"}`},
+		{name: "missing required", value: `{"voltage":220}`, want: errors.New("material is required")},
+		{name: "not in enum", value: `{"material":"wool"}`, want: errors.New(`material must be one of ["cotton","linen"]`)},
+		{name: "not an integer", value: `{"material":"linen","voltage":220.5}`, want: errors.New("voltage must be of type integer")},
+		{name: "above maximum", value: `{"material":"linen","voltage":380}`, want: errors.New("voltage must be at most 240")},
+		{name: "pattern", value: `{"material":"linen","color":"Blue"}`, want: errors.New("color must match ^[a-z]+$")},
+		{name: "additional property", value: `{"material":"linen","fit":"slim"}`, want: errors.New("fit is not allowed")},
+		{name: "not an object", value: `"cotton"`, want: errors.New("value must be of type object")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value interface{}
+			assert.NoError(t, json.Unmarshal([]byte(tt.value), &value))
+			assert.Equal(t, tt.want, schema.Validate(value))
+		})
+	}
+}
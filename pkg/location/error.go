@@ -0,0 +1,63 @@
+package location
+
+import "fmt"
+
+type LocationNotFoundError struct {
+	id string
+}
+
+func (e LocationNotFoundError) Error() string {
+	return fmt.Sprintf("Location is not found with id=%s", e.id)
+}
+
+func NewLocationNotFoundError(uuid string) error {
+	return &LocationNotFoundError{
+		id: uuid,
+	}
+}
+
+type InvalidLocationError struct {
+	reason string
+}
+
+func (e InvalidLocationError) Error() string {
+	return fmt.Sprintf("Location is invalid: %s", e.reason)
+}
+
+func NewInvalidLocationError(reason string) error {
+	return &InvalidLocationError{
+		reason: reason,
+	}
+}
+
+type LocationConflictError struct {
+	name     string
+	existing string
+}
+
+func (e LocationConflictError) Error() string {
+	return fmt.Sprintf("Location %s already exists with id=%s", e.name, e.existing)
+}
+
+func NewLocationConflictError(name string, existingUUID string) error {
+	return &LocationConflictError{
+		name:     name,
+		existing: existingUUID,
+	}
+}
+
+type LocationInUseError struct {
+	id       string
+	products int
+}
+
+func (e LocationInUseError) Error() string {
+	return fmt.Sprintf("Location id=%s still holds stock of %d products", e.id, e.products)
+}
+
+func NewLocationInUseError(uuid string, products int) error {
+	return &LocationInUseError{
+		id:       uuid,
+		products: products,
+	}
+}
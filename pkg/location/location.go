@@ -0,0 +1,24 @@
+package location
+
+import (
+	"fmt"
+	"strings"
+)
+
+const maxNameLength = 200
+
+// Location is a warehouse of a seller that holds stock.
+type Location struct {
+	LocationID int    `json:"-"`
+	UUID       string `json:"uuid"`
+	SellerUUID string `json:"seller_uuid"`
+	Name       string `json:"name"`
+}
+
+func (l *Location) validate() error {
+	l.Name = strings.TrimSpace(l.Name)
+	if l.Name == "" || len(l.Name) > maxNameLength {
+		return NewInvalidLocationError(fmt.Sprintf("name must be between 1 and %d characters", maxNameLength))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package location
+
+import (
+	"context"
+	"database/sql"
+)
+
+const (
+	selectLocationQuery = "SELECT l.id_location, l.uuid, s.uuid, l.name FROM location l " +
+		"INNER JOIN seller s ON(s.id_seller = l.fk_seller)"
+)
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func (r *repository) List(ctx context.Context, sellerUUID string) ([]*Location, error) {
+	if sellerUUID == "" {
+		return r.query(ctx, selectLocationQuery+" ORDER BY l.name")
+	}
+	return r.query(ctx, selectLocationQuery+" WHERE s.uuid = ? ORDER BY l.name", sellerUUID)
+}
+
+func (r *repository) FindByUUID(ctx context.Context, uuid string) (*Location, error) {
+	locations, err := r.query(ctx, selectLocationQuery+" WHERE l.uuid = ?", uuid)
+	if err != nil || len(locations) == 0 {
+		return nil, err
+	}
+	return locations[0], nil
+}
+
+func (r *repository) Create(ctx context.Context, location *Location) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO location (uuid, fk_seller, name) VALUES(?,(SELECT id_seller FROM seller WHERE uuid = ?),?)",
+		location.UUID, location.SellerUUID, location.Name,
+	)
+
+	return err
+}
+
+func (r *repository) Update(ctx context.Context, location *Location) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE location SET name = ? WHERE uuid = ?", location.Name, location.UUID)
+
+	return err
+}
+
+func (r *repository) Delete(ctx context.Context, uuid string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM location WHERE uuid = ?", uuid)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *repository) CountProducts(ctx context.Context, uuid string) (int, error) {
+	var products int
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT COUNT(*) FROM product_stock ps INNER JOIN location l ON(l.id_location = ps.fk_location) "+
+			"WHERE l.uuid = ? AND ps.stock <> 0",
+		uuid,
+	).Scan(&products)
+	return products, err
+}
+
+func (r *repository) query(ctx context.Context, query string, args ...interface{}) ([]*Location, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	locations := []*Location{}
+
+	for rows.Next() {
+		location := &Location{}
+		if err := rows.Scan(&location.LocationID, &location.UUID, &location.SellerUUID, &location.Name); err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+
+	return locations, rows.Err()
+}
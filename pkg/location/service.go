@@ -0,0 +1,127 @@
+package location
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"coding-challenge-go/pkg/seller"
+)
+
+type (
+	Service interface {
+		// List returns the locations of a seller, or of every seller when sellerUUID is empty.
+		List(ctx context.Context, sellerUUID string) ([]*Location, error)
+		FindByUUID(ctx context.Context, uuid string) (*Location, error)
+		Create(ctx context.Context, location *Location) error
+		// Update renames a location. It stays with its seller.
+		Update(ctx context.Context, location *Location) error
+		// Delete removes a location that holds no stock.
+		Delete(ctx context.Context, uuid string) error
+	}
+
+	Repository interface {
+		// List returns locations ordered by name, only those of sellerUUID unless it is empty.
+		List(ctx context.Context, sellerUUID string) ([]*Location, error)
+		FindByUUID(ctx context.Context, uuid string) (*Location, error)
+		Create(ctx context.Context, location *Location) error
+		Update(ctx context.Context, location *Location) error
+		// Delete reports false when there was no such location.
+		Delete(ctx context.Context, uuid string) (bool, error)
+		// CountProducts counts the products with stock at a location.
+		CountProducts(ctx context.Context, uuid string) (int, error)
+	}
+
+	service struct {
+		repo       Repository
+		sellerRepo seller.Repository
+	}
+)
+
+func NewService(repo Repository, sellerRepo seller.Repository) Service {
+	return &service{repo: repo, sellerRepo: sellerRepo}
+}
+
+func (s *service) List(ctx context.Context, sellerUUID string) ([]*Location, error) {
+	return s.repo.List(ctx, sellerUUID)
+}
+
+func (s *service) FindByUUID(ctx context.Context, uuid string) (*Location, error) {
+	location, err := s.repo.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		return nil, NewLocationNotFoundError(uuid)
+	}
+	return location, nil
+}
+
+func (s *service) Create(ctx context.Context, location *Location) error {
+	if err := location.validate(); err != nil {
+		return err
+	}
+	sl, err := s.sellerRepo.FindByUUID(ctx, location.SellerUUID)
+	if err != nil {
+		return err
+	}
+	if sl == nil {
+		return NewInvalidLocationError("seller is not found")
+	}
+	if err := s.checkNameFree(ctx, location); err != nil {
+		return err
+	}
+
+	location.UUID = uuid.New().String()
+	return s.repo.Create(ctx, location)
+}
+
+func (s *service) Update(ctx context.Context, location *Location) error {
+	existing, err := s.FindByUUID(ctx, location.UUID)
+	if err != nil {
+		return err
+	}
+	if err := location.validate(); err != nil {
+		return err
+	}
+	location.SellerUUID = existing.SellerUUID
+	if err := s.checkNameFree(ctx, location); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, location)
+}
+
+func (s *service) Delete(ctx context.Context, uuid string) error {
+	products, err := s.repo.CountProducts(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if products > 0 {
+		return NewLocationInUseError(uuid, products)
+	}
+	deleted, err := s.repo.Delete(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return NewLocationNotFoundError(uuid)
+	}
+	return nil
+}
+
+// checkNameFree fails when another location of the same seller has the name of
+// location, ignoring case.
+func (s *service) checkNameFree(ctx context.Context, location *Location) error {
+	locations, err := s.repo.List(ctx, location.SellerUUID)
+	if err != nil {
+		return err
+	}
+	for _, other := range locations {
+		if other.UUID != location.UUID && strings.EqualFold(other.Name, location.Name) {
+			return NewLocationConflictError(location.Name, other.UUID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package location
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/seller"
+)
+
+func Test_LocationValidate(t *testing.T) {
+	l := &Location{Name: "  Berlin DC "}
+	assert.NoError(t, l.validate())
+	assert.Equal(t, "Berlin DC", l.Name)
+
+	assert.IsType(t, &InvalidLocationError{}, (&Location{Name: " "}).validate())
+}
+
+func Test_serviceCRUD(t *testing.T) {
+	repo := &repositoryMock{}
+	svc := NewService(repo, &sellerRepositoryMock{sellers: []string{"s1", "s2"}})
+	ctx := context.Background()
+
+	berlin := &Location{Name: "Berlin DC", SellerUUID: "s1"}
+	assert.NoError(t, svc.Create(ctx, berlin))
+	assert.NotEmpty(t, berlin.UUID)
+	hamburg := &Location{Name: "Hamburg", SellerUUID: "s1"}
+	assert.NoError(t, svc.Create(ctx, hamburg))
+
+	// Names are unique per seller only.
+	assert.NoError(t, svc.Create(ctx, &Location{Name: "Berlin DC", SellerUUID: "s2"}))
+	assert.Equal(t, NewLocationConflictError("berlin dc", berlin.UUID), svc.Create(ctx, &Location{Name: "berlin dc", SellerUUID: "s1"}))
+	assert.Equal(t, NewInvalidLocationError("seller is not found"), svc.Create(ctx, &Location{Name: "Paris", SellerUUID: "s3"}))
+
+	assert.Equal(t, NewLocationConflictError("Hamburg", hamburg.UUID), svc.Update(ctx, &Location{UUID: berlin.UUID, Name: "Hamburg"}))
+	assert.NoError(t, svc.Update(ctx, &Location{UUID: berlin.UUID, Name: "Berlin South", SellerUUID: "s2"}))
+	found, err := svc.FindByUUID(ctx, berlin.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, &Location{UUID: berlin.UUID, Name: "Berlin South", SellerUUID: "s1"}, found)
+
+	repo.products = map[string]int{hamburg.UUID: 3}
+	assert.Equal(t, NewLocationInUseError(hamburg.UUID, 3), svc.Delete(ctx, hamburg.UUID))
+	assert.NoError(t, svc.Delete(ctx, berlin.UUID))
+	assert.Equal(t, NewLocationNotFoundError(berlin.UUID), svc.Delete(ctx, berlin.UUID))
+	_, err = svc.FindByUUID(ctx, berlin.UUID)
+	assert.Equal(t, NewLocationNotFoundError(berlin.UUID), err)
+}
+
+type repositoryMock struct {
+	locations []*Location
+	products  map[string]int
+}
+
+func (m *repositoryMock) List(ctx context.Context, sellerUUID string) ([]*Location, error) {
+	var locations []*Location
+	for _, l := range m.locations {
+		if sellerUUID == "" || l.SellerUUID == sellerUUID {
+			cp := *l
+			locations = append(locations, &cp)
+		}
+	}
+	return locations, nil
+}
+
+func (m *repositoryMock) FindByUUID(ctx context.Context, uuid string) (*Location, error) {
+	for _, l := range m.locations {
+		if l.UUID == uuid {
+			cp := *l
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *repositoryMock) Create(ctx context.Context, location *Location) error {
+	cp := *location
+	m.locations = append(m.locations, &cp)
+	return nil
+}
+
+func (m *repositoryMock) Update(ctx context.Context, location *Location) error {
+	for _, l := range m.locations {
+		if l.UUID == location.UUID {
+			l.Name = location.Name
+		}
+	}
+	return nil
+}
+
+func (m *repositoryMock) Delete(ctx context.Context, uuid string) (bool, error) {
+	for i, l := range m.locations {
+		if l.UUID == uuid {
+			m.locations = append(m.locations[:i], m.locations[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *repositoryMock) CountProducts(ctx context.Context, uuid string) (int, error) {
+	return m.products[uuid], nil
+}
+
+type sellerRepositoryMock struct {
+	sellers []string
+}
+
+func (m *sellerRepositoryMock) List(ctx context.Context) ([]*seller.Seller, error) {
+	return nil, nil
+}
+
+func (m *sellerRepositoryMock) FindByUUID(ctx context.Context, uuid string) (*seller.Seller, error) {
+	for _, s := range m.sellers {
+		if s == uuid {
+			return &seller.Seller{UUID: uuid}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *sellerRepositoryMock) TopByProduct(ctx context.Context, limit int) ([]*seller.Seller, error) {
+	return nil, nil
+}
@@ -0,0 +1,81 @@
+// Package money represents prices as integer minor units of an ISO-4217 currency,
+// so amounts never go through floating point.
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Money is an amount in the minor unit of its currency, e.g. 1999 EUR is 19.99 EUR.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// minorUnits maps the supported ISO-4217 currency codes to the number of digits of their minor unit.
+var minorUnits = map[string]int{
+	"AUD": 2,
+	"BRL": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CNY": 2,
+	"CZK": 2,
+	"DKK": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HKD": 2,
+	"IDR": 2,
+	"INR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"MYR": 2,
+	"NOK": 2,
+	"NZD": 2,
+	"PHP": 2,
+	"PLN": 2,
+	"SEK": 2,
+	"SGD": 2,
+	"THB": 2,
+	"USD": 2,
+	"VND": 0,
+}
+
+// IsCurrency reports whether code is a supported ISO-4217 currency code.
+func IsCurrency(code string) bool {
+	_, ok := minorUnits[code]
+	return ok
+}
+
+// Validate checks that the currency is supported and the amount is not negative.
+func (m Money) Validate() error {
+	if !IsCurrency(m.Currency) {
+		return fmt.Errorf("currency %q is not a supported ISO-4217 code", m.Currency)
+	}
+	if m.Amount < 0 {
+		return fmt.Errorf("amount must not be negative")
+	}
+	return nil
+}
+
+// String formats the amount in major units, e.g. "19.99 EUR".
+func (m Money) String() string {
+	digits := minorUnits[m.Currency]
+	if digits == 0 {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	unit := int64(1)
+	for i := 0; i < digits; i++ {
+		unit *= 10
+	}
+	minor := fmt.Sprintf("%d", amount%unit)
+	return fmt.Sprintf("%s%d.%s%s %s", sign, amount/unit, strings.Repeat("0", digits-len(minor)), minor, m.Currency)
+}
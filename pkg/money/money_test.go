@@ -0,0 +1,33 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoney_String(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		want  string
+	}{
+		{name: "test two digits", money: Money{Amount: 1999, Currency: "EUR"}, want: "19.99 EUR"},
+		{name: "test leading zero", money: Money{Amount: 105, Currency: "USD"}, want: "1.05 USD"},
+		{name: "test no minor unit", money: Money{Amount: 1500, Currency: "JPY"}, want: "1500 JPY"},
+		{name: "test three digits", money: Money{Amount: 1234, Currency: "KWD"}, want: "1.234 KWD"},
+		{name: "test negative", money: Money{Amount: -5, Currency: "EUR"}, want: "-0.05 EUR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.money.String())
+		})
+	}
+}
+
+func TestMoney_Validate(t *testing.T) {
+	assert.NoError(t, Money{Amount: 0, Currency: "EUR"}.Validate())
+	assert.EqualError(t, Money{Amount: 100, Currency: "EURO"}.Validate(), `currency "EURO" is not a supported ISO-4217 code`)
+	assert.EqualError(t, Money{Amount: 100, Currency: "eur"}.Validate(), `currency "eur" is not a supported ISO-4217 code`)
+	assert.EqualError(t, Money{Amount: -1, Currency: "EUR"}.Validate(), "amount must not be negative")
+}
@@ -0,0 +1,29 @@
+// Package periodic runs background jobs on a fixed interval.
+package periodic
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Run calls job every interval until ctx is done. An interval that is not
+// positive cannot drive a ticker, so the job is not started at all.
+func Run(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context)) {
+	if interval <= 0 {
+		log.Error().Msg("Fail to start " + name + ": interval must be positive, not " + interval.String())
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job(ctx)
+		}
+	}
+}
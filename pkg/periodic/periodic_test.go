@@ -0,0 +1,29 @@
+package periodic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := 0
+	done := make(chan struct{})
+	go func() {
+		Run(ctx, "test job", time.Millisecond, func(ctx context.Context) {
+			runs++
+			if runs == 3 {
+				cancel()
+			}
+		})
+		close(done)
+	}()
+	<-done
+	assert.Equal(t, 3, runs)
+
+	// A non-positive interval returns instead of panicking.
+	Run(context.Background(), "test job", 0, func(ctx context.Context) { t.Fatal("job must not run") })
+}
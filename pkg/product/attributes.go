@@ -0,0 +1,103 @@
+package product
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"coding-challenge-go/pkg/category"
+)
+
+const (
+	maxAttributes           = 50
+	maxAttributeValueLength = 200
+)
+
+// attributeNamePattern keeps names usable as `attr.<name>` filters and as JSON paths.
+var attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// Attributes are free-form properties of a product such as material or voltage.
+// Values are strings, numbers or booleans.
+type Attributes map[string]interface{}
+
+// IsAttributeName reports whether name can name an attribute.
+func IsAttributeName(name string) bool {
+	return attributeNamePattern.MatchString(name)
+}
+
+func (a Attributes) validate() error {
+	if len(a) > maxAttributes {
+		return NewInvalidAttributesError(fmt.Sprintf("at most %d attributes are allowed", maxAttributes))
+	}
+	for name, value := range a {
+		if !IsAttributeName(name) {
+			return NewInvalidAttributesError(fmt.Sprintf("%s is not a valid name, use lowercase letters, digits and underscores", name))
+		}
+		switch v := value.(type) {
+		case string:
+			if utf8.RuneCountInString(v) > maxAttributeValueLength {
+				return NewInvalidAttributesError(fmt.Sprintf("%s must be at most %d characters", name, maxAttributeValueLength))
+			}
+		case float64, bool:
+		default:
+			return NewInvalidAttributesError(fmt.Sprintf("%s must be a string, number or boolean", name))
+		}
+	}
+	return nil
+}
+
+// checkSchemas validates the attributes against the schema of each category.
+func (a Attributes) checkSchemas(categories []*category.Category) error {
+	var document interface{} = map[string]interface{}(a)
+	if a == nil {
+		document = map[string]interface{}{}
+	}
+	for _, c := range categories {
+		schema, err := c.Schema()
+		if err != nil {
+			return err
+		}
+		if schema == nil {
+			continue
+		}
+		if err := schema.Validate(document); err != nil {
+			return NewInvalidAttributesError(fmt.Sprintf("%s (required by category %s)", err.Error(), c.Path))
+		}
+	}
+	return nil
+}
+
+// clone returns a copy that can be changed without touching a.
+func (a Attributes) clone() Attributes {
+	if a == nil {
+		return nil
+	}
+	cp := make(Attributes, len(a))
+	for name, value := range a {
+		cp[name] = value
+	}
+	return cp
+}
+
+// marshalAttributes returns the column value for a, NULL when there are none.
+func marshalAttributes(a Attributes) (interface{}, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(a)
+}
+
+func unmarshalAttributes(data []byte) (Attributes, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	a := Attributes{}
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+	if len(a) == 0 {
+		return nil, nil
+	}
+	return a, nil
+}
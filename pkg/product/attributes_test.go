@@ -0,0 +1,68 @@
+package product
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/category"
+)
+
+func Test_AttributesValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes Attributes
+		wantErr    error
+	}{
+		{name: "scalars are valid", attributes: Attributes{"material": "cotton", "voltage": float64(220), "organic": true}},
+		{name: "no attributes", attributes: nil},
+		{
+			name:       "name with uppercase",
+			attributes: Attributes{"Material": "cotton"},
+			wantErr:    NewInvalidAttributesError("Material is not a valid name, use lowercase letters, digits and underscores"),
+		},
+		{
+			name:       "nested value",
+			attributes: Attributes{"size": map[string]interface{}{"eu": float64(42)}},
+			wantErr:    NewInvalidAttributesError("size must be a string, number or boolean"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, tt.attributes.validate())
+		})
+	}
+}
+
+func Test_serviceAttributes(t *testing.T) {
+	shirts := &category.Category{UUID: "c1", Name: "Shirts", Path: "shirts",
+		AttributeSchema: json.RawMessage(`{"required":["material"],"properties":{"material":{"enum":["cotton","linen"]}}}`)}
+	lamps := &category.Category{UUID: "c2", Name: "Lamps", Path: "lamps",
+		AttributeSchema: json.RawMessage(`{"required":["voltage"]}`)}
+	categoryRepo := &categoryRepositoryMock{
+		categories: map[string]*category.Category{"c1": shirts, "c2": lamps},
+		assigned:   map[string][]string{"p1": {"c1"}},
+	}
+	repo := &repositoryMock{products: map[string]*Product{
+		"p1": {UUID: "p1", Name: "Berlin New Shirt", Brand: "Berlin", SellerUUID: "s1", Version: 1, Attributes: Attributes{"material": "cotton"}},
+	}}
+	svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{}, WithCategoryRepository(categoryRepo))
+	ctx := context.Background()
+
+	_, err := svc.Patch(ctx, "p1", 0, &Patch{Attributes: &AttributesPatch{Set: Attributes{"material": "wool"}}})
+	assert.Equal(t, NewInvalidAttributesError(`material must be one of ["cotton","linen"] (required by category shirts)`), err)
+
+	info, err := svc.Patch(ctx, "p1", 0, &Patch{Attributes: &AttributesPatch{Set: Attributes{"fit": "slim"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, Attributes{"material": "cotton", "fit": "slim"}, info.Attributes)
+
+	// PUT without attributes keeps them.
+	assert.NoError(t, svc.Update(ctx, &Product{UUID: "p1", Name: "Berlin Shirt", Brand: "Berlin"}))
+	assert.Equal(t, Attributes{"material": "cotton", "fit": "slim"}, repo.products["p1"].Attributes)
+
+	_, err = svc.SetCategories(ctx, "p1", []string{"c1", "c2"})
+	assert.Equal(t, NewInvalidAttributesError("voltage is required (required by category lamps)"), err)
+	assert.Equal(t, []string{"c1"}, categoryRepo.assigned["p1"])
+}
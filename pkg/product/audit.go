@@ -0,0 +1,125 @@
+package product
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate          AuditAction = "create"
+	AuditActionUpdate          AuditAction = "update"
+	AuditActionDelete          AuditAction = "delete"
+	AuditActionRestore         AuditAction = "restore"
+	AuditActionStockAdjustment AuditAction = "stock_adjustment"
+	AuditActionRevert          AuditAction = "revert"
+	AuditActionStatusChange    AuditAction = "status_change"
+
+	AuditActionVariantCreate          AuditAction = "variant_create"
+	AuditActionVariantStockAdjustment AuditAction = "variant_stock_adjustment"
+	AuditActionVariantDelete          AuditAction = "variant_delete"
+
+	AuditActionLocationStockAdjustment AuditAction = "location_stock_adjustment"
+	AuditActionStockMove               AuditAction = "stock_move"
+
+	AuditActionComponentsSet AuditAction = "components_set"
+)
+
+type (
+	// AuditEntry records one change of a product. Revision is the product version
+	// the change produced.
+	AuditEntry struct {
+		AuditID     int            `json:"-"`
+		ProductUUID string         `json:"product_uuid"`
+		Revision    int            `json:"revision"`
+		Action      AuditAction    `json:"action"`
+		Actor       string         `json:"actor"`
+		RequestID   string         `json:"request_id"`
+		Changes     []*FieldChange `json:"changes"`
+		Snapshot    *Product       `json:"-"`
+		CreatedAt   time.Time      `json:"created_at"`
+	}
+
+	FieldChange struct {
+		Field string      `json:"field"`
+		From  interface{} `json:"from"`
+		To    interface{} `json:"to"`
+	}
+
+	AuditRepository interface {
+		Create(ctx context.Context, entry *AuditEntry) error
+		// ListByProduct returns the entries of a product, newest first.
+		ListByProduct(ctx context.Context, productUUID string, offset int, limit int) ([]*AuditEntry, error)
+		// FindByRevision returns the entry that produced a revision of a product, or nil.
+		FindByRevision(ctx context.Context, productUUID string, revision int) (*AuditEntry, error)
+	}
+
+	// nopAuditRepository is used when the service is built without an audit repository.
+	nopAuditRepository struct{}
+
+	auditedField struct {
+		name  string
+		value func(p *Product) interface{}
+	}
+)
+
+// auditedFields are the product fields compared when recording a change.
+var auditedFields = []auditedField{
+	{name: "name", value: func(p *Product) interface{} { return p.Name }},
+	{name: "brand", value: func(p *Product) interface{} { return p.Brand }},
+	{name: "stock", value: func(p *Product) interface{} { return p.Stock }},
+	{name: "sku", value: func(p *Product) interface{} { return nullString(p.SKU) }},
+	{name: "gtin", value: func(p *Product) interface{} { return nullString(p.GTIN) }},
+	{name: "status", value: func(p *Product) interface{} { return nullString(string(p.Status)) }},
+	{name: "price", value: func(p *Product) interface{} {
+		if p.Price == nil {
+			return nil
+		}
+		return *p.Price
+	}},
+	{name: "attributes", value: func(p *Product) interface{} {
+		if len(p.Attributes) == 0 {
+			return nil
+		}
+		return p.Attributes
+	}},
+	{name: "deleted_at", value: func(p *Product) interface{} {
+		if p.DeletedAt == nil {
+			return nil
+		}
+		return *p.DeletedAt
+	}},
+}
+
+// diffProducts lists the audited fields that differ between before and after.
+// A nil before or after reports every field as added or removed.
+func diffProducts(before *Product, after *Product) []*FieldChange {
+	changes := []*FieldChange{}
+	for _, f := range auditedFields {
+		var from, to interface{}
+		if before != nil {
+			from = f.value(before)
+		}
+		if after != nil {
+			to = f.value(after)
+		}
+		if !reflect.DeepEqual(from, to) {
+			changes = append(changes, &FieldChange{Field: f.name, From: from, To: to})
+		}
+	}
+	return changes
+}
+
+func (nopAuditRepository) Create(ctx context.Context, entry *AuditEntry) error {
+	return nil
+}
+
+func (nopAuditRepository) ListByProduct(ctx context.Context, productUUID string, offset int, limit int) ([]*AuditEntry, error) {
+	return []*AuditEntry{}, nil
+}
+
+func (nopAuditRepository) FindByRevision(ctx context.Context, productUUID string, revision int) (*AuditEntry, error) {
+	return nil, nil
+}
@@ -0,0 +1,103 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+func (r *auditRepository) Create(ctx context.Context, entry *AuditEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+	snapshot, err := json.Marshal(entry.Snapshot)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		"INSERT INTO product_audit (product_uuid, revision, action, actor, request_id, changes, snapshot, created_at) VALUES(?,?,?,?,?,?,?,?)",
+		entry.ProductUUID, entry.Revision, entry.Action, entry.Actor, entry.RequestID, changes, snapshot, entry.CreatedAt,
+	)
+
+	return err
+}
+
+func (r *auditRepository) ListByProduct(ctx context.Context, productUUID string, offset int, limit int) ([]*AuditEntry, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT id_product_audit, product_uuid, revision, action, actor, request_id, changes, snapshot, created_at FROM product_audit "+
+			"WHERE product_uuid = ? ORDER BY revision DESC LIMIT ? OFFSET ?",
+		productUUID, limit, offset,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entries := []*AuditEntry{}
+
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *auditRepository) FindByRevision(ctx context.Context, productUUID string, revision int) (*AuditEntry, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT id_product_audit, product_uuid, revision, action, actor, request_id, changes, snapshot, created_at FROM product_audit "+
+			"WHERE product_uuid = ? AND revision = ?",
+		productUUID, revision,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, nil
+	}
+
+	return scanAuditEntry(rows)
+}
+
+func scanAuditEntry(rows *sql.Rows) (*AuditEntry, error) {
+	var changes, snapshot []byte
+	entry := &AuditEntry{}
+
+	err := rows.Scan(
+		&entry.AuditID, &entry.ProductUUID, &entry.Revision, &entry.Action, &entry.Actor, &entry.RequestID,
+		&changes, &snapshot, &entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(changes, &entry.Changes); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(snapshot, &entry.Snapshot); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,150 @@
+package product
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/requestinfo"
+)
+
+func Test_diffProducts(t *testing.T) {
+	tests := []struct {
+		name   string
+		before *Product
+		after  *Product
+		want   []*FieldChange
+	}{
+		{
+			name:   "test diff created product",
+			before: nil,
+			after:  &Product{Name: "Plano Tee", Brand: "TeeCo", Stock: 10},
+			want: []*FieldChange{
+				{Field: "name", From: nil, To: "Plano Tee"},
+				{Field: "brand", From: nil, To: "TeeCo"},
+				{Field: "stock", From: nil, To: 10},
+			},
+		},
+		{
+			name:   "test diff changed stock",
+			before: &Product{Name: "Plano Tee", Brand: "TeeCo", Stock: 10},
+			after:  &Product{Name: "Plano Tee", Brand: "TeeCo", Stock: 7},
+			want: []*FieldChange{
+				{Field: "stock", From: 10, To: 7},
+			},
+		},
+		{
+			name:   "test diff unchanged product",
+			before: &Product{Name: "Plano Tee", Brand: "TeeCo", Stock: 10},
+			after:  &Product{Name: "Plano Tee", Brand: "TeeCo", Stock: 10},
+			want:   []*FieldChange{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, diffProducts(tt.before, tt.after))
+		})
+	}
+}
+
+func Test_serviceRecordsAudit(t *testing.T) {
+	repo := &repositoryMock{products: map[string]*Product{}}
+	auditRepo := &auditRepositoryMock{}
+	svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{}, WithAuditRepository(auditRepo))
+
+	ctx := requestinfo.WithActor(context.Background(), "admin@gfg.com")
+	ctx = requestinfo.WithRequestID(ctx, "req-1")
+
+	p := &Product{UUID: "p1", Name: "Plano Tee", Brand: "TeeCo", Stock: 10, SellerUUID: "s1"}
+	_, err := svc.Create(ctx, p)
+	assert.NoError(t, err)
+
+	stock := 7
+	_, err = svc.Patch(ctx, "p1", 0, &Patch{Stock: &stock})
+	assert.NoError(t, err)
+	assert.NoError(t, svc.Delete(ctx, "p1", 0))
+
+	if assert.Len(t, auditRepo.entries, 3) {
+		assert.Equal(t, AuditActionCreate, auditRepo.entries[0].Action)
+		assert.Equal(t, AuditActionUpdate, auditRepo.entries[1].Action)
+		assert.Equal(t, 2, auditRepo.entries[1].Revision)
+		assert.Equal(t, []*FieldChange{{Field: "stock", From: 10, To: 7}}, auditRepo.entries[1].Changes)
+		assert.Equal(t, 7, auditRepo.entries[1].Snapshot.Stock)
+		assert.Equal(t, AuditActionDelete, auditRepo.entries[2].Action)
+		assert.Equal(t, 3, auditRepo.entries[2].Revision)
+		if assert.Len(t, auditRepo.entries[2].Changes, 1) {
+			assert.Equal(t, "deleted_at", auditRepo.entries[2].Changes[0].Field)
+			assert.Nil(t, auditRepo.entries[2].Changes[0].From)
+			assert.NotNil(t, auditRepo.entries[2].Changes[0].To)
+		}
+		for _, entry := range auditRepo.entries {
+			assert.Equal(t, "admin@gfg.com", entry.Actor)
+			assert.Equal(t, "req-1", entry.RequestID)
+		}
+	}
+}
+
+func Test_serviceRevert(t *testing.T) {
+	repo := &repositoryMock{products: map[string]*Product{}}
+	auditRepo := &auditRepositoryMock{}
+	noti := &notiProviderMock{}
+	svc := NewService(repo, &sellerRepositoryMock{}, noti, WithAuditRepository(auditRepo))
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, &Product{UUID: "p1", Name: "Plano Tee", Brand: "TeeCo", Stock: 10, SellerUUID: "s1"})
+	assert.NoError(t, err)
+	name := "Plano Tee v2"
+	stock := 4
+	amount := int64(1999)
+	currency := "EUR"
+	_, err = svc.Patch(ctx, "p1", 0, &Patch{Name: &name, Stock: &stock, Price: &PricePatch{Amount: &amount, Currency: &currency}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, noti.calls)
+
+	_, err = svc.Revert(ctx, "p1", 1, 1)
+	assert.Equal(t, NewPreconditionFailedError("p1"), err)
+
+	reverted, err := svc.Revert(ctx, "p1", 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Plano Tee", reverted.Name)
+	assert.Equal(t, 10, reverted.Stock)
+	assert.Nil(t, reverted.Price)
+	assert.Equal(t, 3, reverted.Version)
+	assert.Equal(t, 2, noti.calls)
+
+	last := auditRepo.entries[len(auditRepo.entries)-1]
+	assert.Equal(t, AuditActionRevert, last.Action)
+	assert.Equal(t, 3, last.Revision)
+
+	_, err = svc.Revert(ctx, "p1", 9, 0)
+	assert.Equal(t, NewRevisionNotFoundError("p1", 9), err)
+}
+
+type auditRepositoryMock struct {
+	entries []*AuditEntry
+}
+
+func (m *auditRepositoryMock) Create(ctx context.Context, entry *AuditEntry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *auditRepositoryMock) ListByProduct(ctx context.Context, productUUID string, offset int, limit int) ([]*AuditEntry, error) {
+	entries := []*AuditEntry{}
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		if m.entries[i].ProductUUID == productUUID {
+			entries = append(entries, m.entries[i])
+		}
+	}
+	return entries, nil
+}
+
+func (m *auditRepositoryMock) FindByRevision(ctx context.Context, productUUID string, revision int) (*AuditEntry, error) {
+	for _, entry := range m.entries {
+		if entry.ProductUUID == productUUID && entry.Revision == revision {
+			return entry, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,48 @@
+package product
+
+import (
+	"context"
+	"fmt"
+
+	"coding-challenge-go/pkg/brand"
+)
+
+type (
+	// BrandResolver turns the brand sent by a client, a name or a UUID, into a brand.
+	// An empty value resolves to nil.
+	BrandResolver interface {
+		Resolve(ctx context.Context, nameOrUUID string) (*brand.Brand, error)
+	}
+
+	// nopBrandResolver keeps the brand as the free text it was sent as.
+	nopBrandResolver struct{}
+
+	BrandLink struct {
+		Href  string `json:"href"`
+		Title string `json:"title"`
+	}
+)
+
+func (nopBrandResolver) Resolve(ctx context.Context, nameOrUUID string) (*brand.Brand, error) {
+	if nameOrUUID == "" {
+		return nil, nil
+	}
+	return &brand.Brand{Name: nameOrUUID}, nil
+}
+
+// resolveBrand replaces product.Brand with the canonical name of the brand it refers to.
+func (s *service) resolveBrand(ctx context.Context, product *Product) error {
+	b, err := s.brandResolver.Resolve(ctx, product.Brand)
+	if err != nil {
+		return err
+	}
+	product.Brand, product.BrandUUID = "", ""
+	if b != nil {
+		product.Brand, product.BrandUUID = b.Name, b.UUID
+	}
+	return nil
+}
+
+func generateBrandLink(brandUUID string) string {
+	return fmt.Sprintf("%s/api/v2/brands/%s", serverAddress, brandUUID)
+}
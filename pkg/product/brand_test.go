@@ -0,0 +1,64 @@
+package product
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/brand"
+)
+
+func Test_serviceBrand(t *testing.T) {
+	resolver := &brandResolverMock{brands: []*brand.Brand{{UUID: "b1", Name: "ShirtsCo"}, {UUID: "b2", Name: "TeeCo"}}}
+	repo := &repositoryMock{products: map[string]*Product{}}
+	svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{}, WithBrandResolver(resolver))
+	ctx := context.Background()
+
+	p := &Product{UUID: "p1", Name: "Berlin New Shirt", Brand: "shirts co", Stock: 10, SellerUUID: "s1"}
+	_, err := svc.Create(ctx, p)
+	assert.NoError(t, err)
+	assert.Equal(t, "ShirtsCo", repo.products["p1"].Brand)
+	assert.Equal(t, "b1", repo.products["p1"].BrandUUID)
+
+	_, err = svc.Create(ctx, &Product{UUID: "p2", Name: "Plano Tee", Brand: "b9", SellerUUID: "s1"})
+	assert.Equal(t, brand.NewBrandNotFoundError("b9"), err)
+
+	teeCo := "b2"
+	info, err := svc.Patch(ctx, "p1", 0, &Patch{Brand: &teeCo})
+	assert.NoError(t, err)
+	assert.Equal(t, "TeeCo", info.Brand)
+
+	links, err := json.Marshal(info.Links)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"brand":{"href":"http://localhost:8080/api/v2/brands/b2","title":"TeeCo"}}`, string(links))
+
+	// Patching other fields keeps the brand reference.
+	name := "Berlin Shirt"
+	info, err = svc.Patch(ctx, "p1", 0, &Patch{Name: &name})
+	assert.NoError(t, err)
+	assert.Equal(t, "b2", info.BrandUUID)
+
+	// A product always has a brand, as when it is created.
+	none := ""
+	_, err = svc.Patch(ctx, "p1", 0, &Patch{Brand: &none})
+	assert.IsType(t, &InvalidFieldsError{}, err)
+	assert.Equal(t, "b2", repo.products["p1"].BrandUUID)
+}
+
+type brandResolverMock struct {
+	brands []*brand.Brand
+}
+
+func (m *brandResolverMock) Resolve(ctx context.Context, nameOrUUID string) (*brand.Brand, error) {
+	if nameOrUUID == "" {
+		return nil, nil
+	}
+	for _, b := range m.brands {
+		if b.UUID == nameOrUUID || brand.Normalize(b.Name) == brand.Normalize(nameOrUUID) {
+			return b, nil
+		}
+	}
+	return nil, brand.NewBrandNotFoundError(nameOrUUID)
+}
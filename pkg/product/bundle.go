@@ -0,0 +1,200 @@
+package product
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	maxBundleComponents = 50
+)
+
+type (
+	// Component is a product a bundle is built from and the units of it one bundle takes.
+	Component struct {
+		ProductUUID string `json:"product_uuid"`
+		Name        string `json:"name"`
+		Quantity    int    `json:"quantity"`
+		// Stock is the stock of the component product; zero while it is in the trash.
+		Stock int `json:"stock"`
+	}
+
+	BundleRepository interface {
+		// ListByProducts returns the components of each of the products that is a
+		// bundle, ordered by name.
+		ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*Component, error)
+		// ListByComponents returns the live bundles built from any of the products.
+		ListByComponents(ctx context.Context, productUUIDs []string) ([]*Product, error)
+		// SetComponents replaces the components of a bundle and returns the bundle with
+		// its version bumped. version is the one the caller read, or 0 to skip the check.
+		SetComponents(ctx context.Context, bundleUUID string, components []*Component, version int) (*Product, error)
+	}
+
+	// nopBundleRepository is used when the service is built without a bundle repository.
+	nopBundleRepository struct{}
+)
+
+// buildable returns how many bundles the components make. before overrides the
+// stock of components that have just changed, to tell what the bundles made before.
+func buildable(components []*Component, before map[string]int) int {
+	bundles := -1
+	for _, c := range components {
+		stock := c.Stock
+		if old, ok := before[c.ProductUUID]; ok {
+			stock = old
+		}
+		if n := stock / c.Quantity; bundles < 0 || n < bundles {
+			bundles = n
+		}
+	}
+	if bundles < 0 {
+		return 0
+	}
+	return bundles
+}
+
+func (s *service) SetComponents(ctx context.Context, uuid string, components []*Component, version int) (*ProductInfo, error) {
+	bundle, err := s.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(bundle.Product, version); err != nil {
+		return nil, err
+	}
+	if len(bundle.Variants) > 0 {
+		return nil, NewDerivedStockError(uuid, StockSourceVariant)
+	}
+	if len(bundle.Locations) > 0 {
+		return nil, NewDerivedStockError(uuid, StockSourceLocation)
+	}
+	if len(components) == 0 || len(components) > maxBundleComponents {
+		return nil, NewInvalidBundleError(fmt.Sprintf("a bundle needs between 1 and %d components", maxBundleComponents))
+	}
+
+	seen := map[string]bool{}
+	for _, c := range components {
+		if c.Quantity < 1 {
+			return nil, NewInvalidBundleError(fmt.Sprintf("quantity of component %s must be at least 1", c.ProductUUID))
+		}
+		if c.ProductUUID == uuid {
+			return nil, NewInvalidBundleError("a bundle cannot contain itself")
+		}
+		if seen[c.ProductUUID] {
+			return nil, NewInvalidBundleError(fmt.Sprintf("component %s is listed more than once", c.ProductUUID))
+		}
+		seen[c.ProductUUID] = true
+	}
+
+	// The repository checks the components, nesting and the bundle's own stock under lock.
+	updated, err := s.bundleRepo.SetComponents(ctx, uuid, components, bundle.Version)
+	if err != nil {
+		return nil, err
+	}
+	s.recordAudit(ctx, AuditActionComponentsSet, bundle.Product, updated)
+
+	return s.FindByUUID(ctx, uuid)
+}
+
+// adjustBundleStock builds or breaks up bundles: every component changes by
+// adjustment.Delta times its quantity, all in one batch.
+func (s *service) adjustBundleStock(ctx context.Context, uuid string, adjustment *StockAdjustment) (*ProductInfo, error) {
+	components, err := s.bundleRepo.ListByProducts(ctx, []string{uuid})
+	if err != nil {
+		return nil, err
+	}
+	batch := &StockBatch{Reason: adjustment.Reason, Code: adjustment.Code}
+	for _, c := range components[uuid] {
+		batch.Deltas = append(batch.Deltas, &StockDelta{ProductUUID: c.ProductUUID, Delta: adjustment.Delta * c.Quantity})
+	}
+	if _, err := s.AdjustStockBatch(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	product, err := s.repo.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, &ProductNotFoundError{id: uuid}
+	}
+	return &ProductInfo{
+		Product: product,
+		Seller:  generateSellerInfo(product.SellerUUID),
+	}, nil
+}
+
+// checkNotComponent refuses to split the stock of a product by source while it
+// is a component of a live bundle. The repositories check it again under lock.
+func (s *service) checkNotComponent(ctx context.Context, uuid string, source StockSource) error {
+	bundles, err := s.bundleRepo.ListByComponents(ctx, []string{uuid})
+	if err != nil {
+		return err
+	}
+	if len(bundles) > 0 {
+		return NewBundleComponentError(uuid, bundles[0].UUID, source)
+	}
+	return nil
+}
+
+// alertLowStock warns sellers about changed products, and bundles built from
+// them, whose stock fell to the low-stock threshold. before holds the stock of
+// each changed product before the change. Like auditing, failures are only logged.
+func (s *service) alertLowStock(ctx context.Context, before map[string]int, changed ...*Product) {
+	if s.lowStockThreshold <= 0 {
+		return
+	}
+	uuids := make([]string, 0, len(changed))
+	for _, product := range changed {
+		s.alertIfLow(ctx, before[product.UUID], product)
+		uuids = append(uuids, product.UUID)
+	}
+
+	bundles, err := s.bundleRepo.ListByComponents(ctx, uuids)
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to find bundles for low-stock alerts")
+		return
+	}
+	if len(bundles) == 0 {
+		return
+	}
+	bundleUUIDs := make([]string, len(bundles))
+	for i, bundle := range bundles {
+		bundleUUIDs[i] = bundle.UUID
+	}
+	components, err := s.bundleRepo.ListByProducts(ctx, bundleUUIDs)
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to find bundle components for low-stock alerts")
+		return
+	}
+	for _, bundle := range bundles {
+		s.alertIfLow(ctx, buildable(components[bundle.UUID], before), bundle)
+	}
+}
+
+// alertIfLow sends a low-stock alert when the stock of product went from above
+// the threshold to at or below it.
+func (s *service) alertIfLow(ctx context.Context, oldStock int, product *Product) {
+	if !notifiesStock(product) || oldStock <= s.lowStockThreshold || product.Stock > s.lowStockThreshold {
+		return
+	}
+	sl, err := s.findSeller(ctx, product.SellerUUID)
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to send low-stock alert for product %s", product.UUID))
+		return
+	}
+	s.notiProvider.StockLow(product.Stock, product.Name, sl)
+}
+
+func (nopBundleRepository) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*Component, error) {
+	return map[string][]*Component{}, nil
+}
+
+func (nopBundleRepository) ListByComponents(ctx context.Context, productUUIDs []string) ([]*Product, error) {
+	return []*Product{}, nil
+}
+
+func (nopBundleRepository) SetComponents(ctx context.Context, bundleUUID string, components []*Component, version int) (*Product, error) {
+	return nil, NewInvalidBundleError("bundles are not enabled")
+}
@@ -0,0 +1,219 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func NewBundleRepository(db *sql.DB) BundleRepository {
+	return &bundleRepository{db: db}
+}
+
+type bundleRepository struct {
+	db *sql.DB
+}
+
+func (r *bundleRepository) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*Component, error) {
+	components := map[string][]*Component{}
+	if len(productUUIDs) == 0 {
+		return components, nil
+	}
+
+	args := make([]interface{}, len(productUUIDs))
+	for i, uuid := range productUUIDs {
+		args[i] = uuid
+	}
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT b.uuid, c.uuid, c.name, bc.quantity, IF(c.deleted_at IS NULL, c.stock, 0) FROM product_bundle_component bc "+
+			"INNER JOIN product b ON(b.id_product = bc.fk_bundle) "+
+			"INNER JOIN product c ON(c.id_product = bc.fk_component) "+
+			"WHERE b.uuid IN (?"+strings.Repeat(",?", len(productUUIDs)-1)+") ORDER BY c.name",
+		args...,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var bundleUUID string
+		component := &Component{}
+		if err := rows.Scan(&bundleUUID, &component.ProductUUID, &component.Name, &component.Quantity, &component.Stock); err != nil {
+			return nil, err
+		}
+		components[bundleUUID] = append(components[bundleUUID], component)
+	}
+
+	return components, rows.Err()
+}
+
+func (r *bundleRepository) ListByComponents(ctx context.Context, productUUIDs []string) ([]*Product, error) {
+	bundles := []*Product{}
+	if len(productUUIDs) == 0 {
+		return bundles, nil
+	}
+
+	args := make([]interface{}, len(productUUIDs))
+	for i, uuid := range productUUIDs {
+		args[i] = uuid
+	}
+	rows, err := r.db.QueryContext(
+		ctx,
+		selectProductQuery+" WHERE p.deleted_at IS NULL AND p.id_product IN (SELECT bc.fk_bundle FROM product_bundle_component bc "+
+			"INNER JOIN product c ON(c.id_product = bc.fk_component) WHERE c.uuid IN (?"+strings.Repeat(",?", len(productUUIDs)-1)+")) "+
+			"ORDER BY p.id_product",
+		args...,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		bundle := &Product{}
+		if err := scanProduct(rows, bundle); err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, bundle)
+	}
+
+	return bundles, rows.Err()
+}
+
+// SetComponents checks the bundle and its components while their rows are locked,
+// so no other write can nest bundles or move stock between the checks and the insert.
+func (r *bundleRepository) SetComponents(ctx context.Context, bundleUUID string, components []*Component, version int) (*Product, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var bundleID, sellerID, stock, current int
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT id_product, fk_seller, stock, version FROM product WHERE uuid = ? AND deleted_at IS NULL FOR UPDATE",
+		bundleUUID,
+	).Scan(&bundleID, &sellerID, &stock, &current)
+	if err == sql.ErrNoRows {
+		return nil, NewProductNotFoundError(bundleUUID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != current {
+		return nil, NewPreconditionFailedError(bundleUUID)
+	}
+
+	// Bundles are not nested, so a bundle's stock only ever depends on plain products.
+	parentUUID, err := findParentBundle(ctx, tx, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	if parentUUID != "" {
+		return nil, NewInvalidBundleError("product is a component of bundle " + parentUUID)
+	}
+	// The stock of a bundle is derived, so units of its own would drop out of the ledger.
+	if stock != 0 {
+		return nil, NewInvalidBundleError(fmt.Sprintf("product has its own stock of %d; adjust it to 0 first", stock))
+	}
+
+	// Components are locked in uuid order, like stock batches, so concurrent writes cannot deadlock.
+	sorted := make([]*Component, len(components))
+	copy(sorted, components)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ProductUUID < sorted[j].ProductUUID })
+	componentIDs := map[string]int{}
+	for _, c := range sorted {
+		var componentID, componentSellerID int
+		var nested, variants, locations bool
+		err := tx.QueryRowContext(
+			ctx,
+			"SELECT id_product, fk_seller, EXISTS(SELECT 1 FROM product_bundle_component WHERE fk_bundle = id_product), "+
+				"EXISTS(SELECT 1 FROM product_variant WHERE fk_product = id_product), "+
+				"EXISTS(SELECT 1 FROM product_stock WHERE fk_product = id_product) "+
+				"FROM product WHERE uuid = ? AND deleted_at IS NULL FOR UPDATE",
+			c.ProductUUID,
+		).Scan(&componentID, &componentSellerID, &nested, &variants, &locations)
+		if err == sql.ErrNoRows {
+			return nil, NewInvalidBundleError("component " + c.ProductUUID + " is not found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if componentSellerID != sellerID {
+			return nil, NewInvalidBundleError("component " + c.ProductUUID + " belongs to another seller")
+		}
+		if nested {
+			return nil, NewInvalidBundleError("component " + c.ProductUUID + " is a bundle itself")
+		}
+		// Building a bundle changes the stock of each component directly, which a
+		// stock split by variant or location does not allow.
+		if variants {
+			return nil, NewInvalidBundleError("component " + c.ProductUUID + " has its stock split by variant")
+		}
+		if locations {
+			return nil, NewInvalidBundleError("component " + c.ProductUUID + " has its stock split by location")
+		}
+		componentIDs[c.ProductUUID] = componentID
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_bundle_component WHERE fk_bundle = ?", bundleID); err != nil {
+		return nil, err
+	}
+	for _, c := range components {
+		_, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO product_bundle_component (fk_bundle, fk_component, quantity) VALUES (?, ?, ?)",
+			bundleID, componentIDs[c.ProductUUID], c.Quantity,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE product SET version = version + 1 WHERE id_product = ?", bundleID); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, selectProductQuery+" WHERE p.uuid = ?", bundleUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, NewProductNotFoundError(bundleUUID)
+	}
+	product := &Product{}
+	if err = scanProduct(rows, product); err != nil {
+		return nil, err
+	}
+	if err = rows.Close(); err != nil {
+		return nil, err
+	}
+
+	return product, tx.Commit()
+}
+
+// findParentBundle returns the uuid of a live bundle the product is a component
+// of, or an empty string if there is none.
+func findParentBundle(ctx context.Context, tx *sql.Tx, productID int) (string, error) {
+	var parentUUID string
+	err := tx.QueryRowContext(
+		ctx,
+		"SELECT b.uuid FROM product_bundle_component bc INNER JOIN product b ON(b.id_product = bc.fk_bundle) "+
+			"WHERE bc.fk_component = ? AND b.deleted_at IS NULL ORDER BY b.id_product LIMIT 1 LOCK IN SHARE MODE",
+		productID,
+	).Scan(&parentUUID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return parentUUID, err
+}
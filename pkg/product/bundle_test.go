@@ -0,0 +1,364 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"coding-challenge-go/pkg/location"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newBundleRepo() *repositoryMock {
+	return &repositoryMock{products: map[string]*Product{
+		"p1": {UUID: "p1", Name: "Berlin New Shirt", SellerUUID: "s1", Stock: 20, Status: StatusPublished, Version: 1},
+		"p2": {UUID: "p2", Name: "Plano Tee", SellerUUID: "s1", Stock: 12, Status: StatusPublished, Version: 1},
+		"p3": {UUID: "p3", Name: "Storm Jacket", SellerUUID: "s2", Stock: 3, Status: StatusPublished, Version: 1},
+		"k1": {UUID: "k1", Name: "Shirt and Tee Kit", SellerUUID: "s1", Status: StatusPublished, Version: 1},
+		"k2": {UUID: "k2", Name: "Summer Kit", SellerUUID: "s1", Status: StatusPublished, Version: 1},
+	}}
+}
+
+func Test_serviceSetComponents(t *testing.T) {
+	tests := []struct {
+		name       string
+		uuid       string
+		components []*Component
+		version    int
+		wantErr    error
+	}{
+		{
+			name:       "test bundle of two products",
+			uuid:       "k1",
+			components: []*Component{{ProductUUID: "p1", Quantity: 2}, {ProductUUID: "p2", Quantity: 1}},
+			version:    1,
+		},
+		{
+			name:       "test stale version",
+			uuid:       "k1",
+			components: []*Component{{ProductUUID: "p1", Quantity: 2}},
+			version:    2,
+			wantErr:    NewPreconditionFailedError("k1"),
+		},
+		{
+			name:    "test no components",
+			uuid:    "k1",
+			wantErr: NewInvalidBundleError("a bundle needs between 1 and 50 components"),
+		},
+		{
+			name:       "test zero quantity",
+			uuid:       "k1",
+			components: []*Component{{ProductUUID: "p1"}},
+			wantErr:    NewInvalidBundleError("quantity of component p1 must be at least 1"),
+		},
+		{
+			name:       "test bundle containing itself",
+			uuid:       "k1",
+			components: []*Component{{ProductUUID: "k1", Quantity: 1}},
+			wantErr:    NewInvalidBundleError("a bundle cannot contain itself"),
+		},
+		{
+			name:       "test component listed twice",
+			uuid:       "k1",
+			components: []*Component{{ProductUUID: "p1", Quantity: 1}, {ProductUUID: "p1", Quantity: 2}},
+			wantErr:    NewInvalidBundleError("component p1 is listed more than once"),
+		},
+		{
+			name:       "test unknown component",
+			uuid:       "k1",
+			components: []*Component{{ProductUUID: "p9", Quantity: 1}},
+			wantErr:    NewInvalidBundleError("component p9 is not found"),
+		},
+		{
+			name:       "test component of another seller",
+			uuid:       "k1",
+			components: []*Component{{ProductUUID: "p3", Quantity: 1}},
+			wantErr:    NewInvalidBundleError("component p3 belongs to another seller"),
+		},
+		{
+			name:       "test bundle as component",
+			uuid:       "k1",
+			components: []*Component{{ProductUUID: "k2", Quantity: 1}},
+			wantErr:    NewInvalidBundleError("component k2 is a bundle itself"),
+		},
+		{
+			name:       "test component turned into bundle",
+			uuid:       "p2",
+			components: []*Component{{ProductUUID: "p1", Quantity: 1}},
+			wantErr:    NewInvalidBundleError("product is a component of bundle k2"),
+		},
+		{
+			name:       "test product with its own stock",
+			uuid:       "p1",
+			components: []*Component{{ProductUUID: "p2", Quantity: 1}},
+			wantErr:    NewInvalidBundleError("product has its own stock of 20; adjust it to 0 first"),
+		},
+		{
+			name:    "test unknown bundle",
+			uuid:    "k9",
+			wantErr: NewProductNotFoundError("k9"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newBundleRepo()
+			bundleRepo := newBundleRepositoryMock(repo)
+			bundleRepo.components["k2"] = []*Component{{ProductUUID: "p2", Quantity: 1}}
+			auditRepo := &auditRepositoryMock{}
+			svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{},
+				WithBundleRepository(bundleRepo), WithAuditRepository(auditRepo))
+
+			got, err := svc.SetComponents(context.Background(), tt.uuid, tt.components, tt.version)
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr != nil {
+				assert.NotContains(t, bundleRepo.components, tt.uuid)
+				assert.Empty(t, auditRepo.entries)
+				return
+			}
+			assert.Equal(t, []*Component{
+				{ProductUUID: "p1", Name: "Berlin New Shirt", Quantity: 2, Stock: 20},
+				{ProductUUID: "p2", Name: "Plano Tee", Quantity: 1, Stock: 12},
+			}, got.Components)
+			assert.Equal(t, 2, got.Version)
+			if assert.Len(t, auditRepo.entries, 1) {
+				assert.Equal(t, AuditActionComponentsSet, auditRepo.entries[0].Action)
+				assert.Equal(t, 2, auditRepo.entries[0].Revision)
+				assert.Equal(t, []*FieldChange{{Field: "stock", From: 0, To: 10}}, auditRepo.entries[0].Changes)
+			}
+		})
+	}
+
+	t.Run("test product with variants", func(t *testing.T) {
+		repo := newBundleRepo()
+		svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{},
+			WithBundleRepository(newBundleRepositoryMock(repo)),
+			WithVariantRepository(&variantRepositoryMock{repo: repo, variants: []*Variant{
+				{UUID: "v1", ProductUUID: "k1", SKU: "KIT-M", Stock: 2},
+			}}))
+
+		_, err := svc.SetComponents(context.Background(), "k1", []*Component{{ProductUUID: "p1", Quantity: 1}}, 0)
+		assert.Equal(t, NewDerivedStockError("k1", StockSourceVariant), err)
+	})
+
+	t.Run("test component with variants or locations", func(t *testing.T) {
+		repo := newBundleRepo()
+		bundleRepo := newBundleRepositoryMock(repo)
+		bundleRepo.variants = &variantRepositoryMock{repo: repo, variants: []*Variant{
+			{UUID: "v1", ProductUUID: "p2", SKU: "TEE-M", Stock: 12},
+		}}
+		bundleRepo.locationStock = &locationStockRepositoryMock{
+			repo:      repo,
+			locations: &locationRepositoryMock{locations: []*location.Location{{UUID: "l1", SellerUUID: "s1", Name: "Berlin"}}},
+			stock:     map[string]int{"l1": 20},
+		}
+		svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{}, WithBundleRepository(bundleRepo))
+
+		_, err := svc.SetComponents(context.Background(), "k1", []*Component{{ProductUUID: "p1", Quantity: 1}}, 0)
+		assert.Equal(t, NewInvalidBundleError("component p1 has its stock split by location"), err)
+		_, err = svc.SetComponents(context.Background(), "k1", []*Component{{ProductUUID: "p2", Quantity: 1}}, 0)
+		assert.Equal(t, NewInvalidBundleError("component p2 has its stock split by variant"), err)
+		assert.Empty(t, bundleRepo.components)
+	})
+}
+
+func Test_buildable(t *testing.T) {
+	components := []*Component{{ProductUUID: "p1", Quantity: 2, Stock: 9}, {ProductUUID: "p2", Quantity: 3, Stock: 30}}
+	assert.Equal(t, 4, buildable(components, nil))
+	assert.Equal(t, 2, buildable(components, map[string]int{"p2": 6}))
+	assert.Equal(t, 0, buildable([]*Component{{ProductUUID: "p1", Quantity: 1, Stock: -3}}, nil))
+	assert.Equal(t, 0, buildable(nil, nil))
+}
+
+func Test_serviceAdjustBundleStock(t *testing.T) {
+	repo := newBundleRepo()
+	bundleRepo := newBundleRepositoryMock(repo)
+	bundleRepo.components["k1"] = []*Component{{ProductUUID: "p1", Quantity: 2}, {ProductUUID: "p2", Quantity: 1}}
+	movementRepo := &stockMovementRepositoryMock{repo: repo}
+	noti := &notiProviderMock{}
+	svc := NewService(repo, &sellerRepositoryMock{}, noti,
+		WithBundleRepository(bundleRepo), WithStockMovementRepository(movementRepo))
+
+	_, err := svc.AdjustStock(context.Background(), "k1", &StockAdjustment{Delta: -3, Reason: "order 1001", Code: StockReasonSale})
+	assert.NoError(t, err)
+	assert.Equal(t, 14, repo.products["p1"].Stock)
+	assert.Equal(t, 9, repo.products["p2"].Stock)
+	assert.Equal(t, 0, repo.products["k1"].Stock)
+	assert.Len(t, repo.movements, 2)
+	for _, movement := range repo.movements {
+		assert.Equal(t, StockReasonSale, movement.Reason)
+		assert.Equal(t, "order 1001", movement.Reference)
+	}
+	assert.Len(t, noti.summaries["s1"], 2)
+
+	// No component changes when one of them runs out.
+	repo.products["p2"].Stock = 2
+	_, err = svc.AdjustStock(context.Background(), "k1", &StockAdjustment{Delta: -3, Reason: "order 1002"})
+	assert.Equal(t, NewInsufficientStockError("p2", 2, -3), err)
+	assert.Equal(t, 14, repo.products["p1"].Stock)
+
+	// The stock of a bundle is never written directly.
+	_, err = svc.AdjustStockBatch(context.Background(), &StockBatch{Deltas: []*StockDelta{{"k1", 1}}, Reason: "count 2021-09"})
+	assert.Equal(t, NewDerivedStockError("k1", StockSourceComponent), err)
+	err = svc.CreateVariant(context.Background(), "k1", &Variant{SKU: "KIT-M", Options: map[string]string{"size": "M"}})
+	assert.Equal(t, NewDerivedStockError("k1", StockSourceComponent), err)
+}
+
+func Test_serviceSplitComponentStock(t *testing.T) {
+	repo := newBundleRepo()
+	repo.products["p2"].Stock = 0
+	bundleRepo := newBundleRepositoryMock(repo)
+	bundleRepo.components["k1"] = []*Component{{ProductUUID: "p1", Quantity: 2}, {ProductUUID: "p2", Quantity: 1}}
+	variantRepo := &variantRepositoryMock{repo: repo}
+	locationRepo := &locationRepositoryMock{locations: []*location.Location{{UUID: "l1", SellerUUID: "s1", Name: "Berlin"}, {UUID: "l2", SellerUUID: "s1", Name: "Hamburg"}}}
+	stockRepo := &locationStockRepositoryMock{repo: repo, locations: locationRepo, stock: map[string]int{}}
+	svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{}, WithBundleRepository(bundleRepo),
+		WithVariantRepository(variantRepo), WithLocationRepository(locationRepo), WithLocationStockRepository(stockRepo))
+
+	// Building a kit changes the stock of its components directly, so neither may be split.
+	err := svc.CreateVariant(context.Background(), "p2", &Variant{SKU: "TEE-M", Options: map[string]string{"size": "M"}})
+	assert.Equal(t, NewBundleComponentError("p2", "k1", StockSourceVariant), err)
+	_, err = svc.AdjustLocationStock(context.Background(), "p1", "l1", &StockAdjustment{Delta: 1, Reason: "count 2021-09"})
+	assert.Equal(t, NewBundleComponentError("p1", "k1", StockSourceLocation), err)
+	_, err = svc.MoveStock(context.Background(), "p1", &StockMove{From: "l1", To: "l2", Quantity: 1, Reason: "rebalance"})
+	assert.Equal(t, NewBundleComponentError("p1", "k1", StockSourceLocation), err)
+	assert.Empty(t, variantRepo.variants)
+	assert.Empty(t, stockRepo.stock)
+
+	// Once the kit is deleted, its components are plain products again.
+	delete(repo.products, "k1")
+	err = svc.CreateVariant(context.Background(), "p2", &Variant{SKU: "TEE-M", Options: map[string]string{"size": "M"}})
+	assert.NoError(t, err)
+}
+
+func Test_serviceLowStockAlert(t *testing.T) {
+	repo := newBundleRepo()
+	bundleRepo := newBundleRepositoryMock(repo)
+	bundleRepo.components["k1"] = []*Component{{ProductUUID: "p1", Quantity: 2}, {ProductUUID: "p2", Quantity: 1}}
+	noti := &notiProviderMock{}
+	svc := NewService(repo, &sellerRepositoryMock{}, noti,
+		WithBundleRepository(bundleRepo), WithLowStockAlert(5))
+
+	// The shirt itself is still fine, but only five kits can be built from it.
+	_, err := svc.AdjustStock(context.Background(), "p1", &StockAdjustment{Delta: -10, Reason: "order 1001"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Shirt and Tee Kit"}, noti.lowStock)
+
+	// Neither is alerted again while they stay low.
+	_, err = svc.AdjustStock(context.Background(), "k1", &StockAdjustment{Delta: -1, Reason: "order 1002"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Shirt and Tee Kit"}, noti.lowStock)
+
+	_, err = svc.AdjustStock(context.Background(), "p2", &StockAdjustment{Delta: -8, Reason: "order 1003"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Shirt and Tee Kit", "Plano Tee"}, noti.lowStock)
+
+	// Without a threshold nothing is alerted.
+	repo = newBundleRepo()
+	noti = &notiProviderMock{}
+	svc = NewService(repo, &sellerRepositoryMock{}, noti)
+	_, err = svc.AdjustStock(context.Background(), "p2", &StockAdjustment{Delta: -12, Reason: "order 1001"})
+	assert.NoError(t, err)
+	assert.Empty(t, noti.lowStock)
+}
+
+// bundleRepositoryMock reads the stock of components from repo, like the real
+// repository joins the product table. variants and locationStock, when set,
+// stand in for the variant and stock rows the real repository checks.
+type bundleRepositoryMock struct {
+	repo          *repositoryMock
+	components    map[string][]*Component
+	variants      VariantRepository
+	locationStock LocationStockRepository
+}
+
+func newBundleRepositoryMock(repo *repositoryMock) *bundleRepositoryMock {
+	return &bundleRepositoryMock{repo: repo, components: map[string][]*Component{}}
+}
+
+func (m *bundleRepositoryMock) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*Component, error) {
+	result := map[string][]*Component{}
+	for _, uuid := range productUUIDs {
+		for _, c := range m.components[uuid] {
+			cp := &Component{ProductUUID: c.ProductUUID, Quantity: c.Quantity}
+			if p, ok := m.repo.products[c.ProductUUID]; ok {
+				cp.Name, cp.Stock = p.Name, p.Stock
+			}
+			result[uuid] = append(result[uuid], cp)
+		}
+	}
+	return result, nil
+}
+
+func (m *bundleRepositoryMock) ListByComponents(ctx context.Context, productUUIDs []string) ([]*Product, error) {
+	wanted := map[string]bool{}
+	for _, uuid := range productUUIDs {
+		wanted[uuid] = true
+	}
+	var uuids []string
+	for uuid, components := range m.components {
+		for _, c := range components {
+			if _, ok := m.repo.products[uuid]; ok && wanted[c.ProductUUID] {
+				uuids = append(uuids, uuid)
+				break
+			}
+		}
+	}
+	sort.Strings(uuids)
+
+	components, _ := m.ListByProducts(ctx, uuids)
+	bundles := make([]*Product, len(uuids))
+	for i, uuid := range uuids {
+		cp := *m.repo.products[uuid]
+		cp.Stock = buildable(components[uuid], nil)
+		bundles[i] = &cp
+	}
+	return bundles, nil
+}
+
+func (m *bundleRepositoryMock) SetComponents(ctx context.Context, bundleUUID string, components []*Component, version int) (*Product, error) {
+	bundle, ok := m.repo.products[bundleUUID]
+	if !ok {
+		return nil, NewProductNotFoundError(bundleUUID)
+	}
+	if err := checkVersion(bundle, version); err != nil {
+		return nil, err
+	}
+	parents, _ := m.ListByComponents(ctx, []string{bundleUUID})
+	if len(parents) > 0 {
+		return nil, NewInvalidBundleError("product is a component of bundle " + parents[0].UUID)
+	}
+	if bundle.Stock != 0 {
+		return nil, NewInvalidBundleError(fmt.Sprintf("product has its own stock of %d; adjust it to 0 first", bundle.Stock))
+	}
+	for _, c := range components {
+		p, ok := m.repo.products[c.ProductUUID]
+		if !ok {
+			return nil, NewInvalidBundleError("component " + c.ProductUUID + " is not found")
+		}
+		if p.SellerUUID != bundle.SellerUUID {
+			return nil, NewInvalidBundleError("component " + c.ProductUUID + " belongs to another seller")
+		}
+		if len(m.components[c.ProductUUID]) > 0 {
+			return nil, NewInvalidBundleError("component " + c.ProductUUID + " is a bundle itself")
+		}
+		if m.variants != nil {
+			if variants, _ := m.variants.ListByProducts(ctx, []string{c.ProductUUID}); len(variants[c.ProductUUID]) > 0 {
+				return nil, NewInvalidBundleError("component " + c.ProductUUID + " has its stock split by variant")
+			}
+		}
+		if m.locationStock != nil {
+			if stocks, _ := m.locationStock.ListByProducts(ctx, []string{c.ProductUUID}); len(stocks[c.ProductUUID]) > 0 {
+				return nil, NewInvalidBundleError("component " + c.ProductUUID + " has its stock split by location")
+			}
+		}
+	}
+
+	m.components[bundleUUID] = components
+	bundle.Version++
+	cp := *bundle
+	bundles, _ := m.ListByProducts(ctx, []string{bundleUUID})
+	cp.Stock = buildable(bundles[bundleUUID], nil)
+	return &cp, nil
+}
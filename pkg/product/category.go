@@ -0,0 +1,75 @@
+package product
+
+import (
+	"context"
+	"fmt"
+
+	"coding-challenge-go/pkg/category"
+)
+
+type (
+	// CategoryRepository is the part of category.Repository the product service uses.
+	CategoryRepository interface {
+		FindByUUID(ctx context.Context, uuid string) (*category.Category, error)
+		ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*category.Category, error)
+		SetProductCategories(ctx context.Context, productUUID string, categoryUUIDs []string) error
+	}
+
+	// nopCategoryRepository is used when the service is built without a category repository.
+	nopCategoryRepository struct{}
+
+	CategoryInfo struct {
+		UUID  string         `json:"uuid"`
+		Name  string         `json:"name"`
+		Path  string         `json:"path"`
+		Links *CategoryLinks `json:"_links"`
+	}
+
+	// CategoryLinks point at the category and, as breadcrumbs, at every category
+	// from the root down to it.
+	CategoryLinks struct {
+		Self        *CategoryLink   `json:"self"`
+		Breadcrumbs []*CategoryLink `json:"breadcrumbs"`
+	}
+
+	CategoryLink struct {
+		Href  string `json:"href"`
+		Title string `json:"title"`
+	}
+)
+
+func generateCategoryInfo(c *category.Category) *CategoryInfo {
+	breadcrumbs := make([]*CategoryLink, 0, len(c.Ancestors)+1)
+	for _, ancestor := range append(c.Ancestors, c) {
+		breadcrumbs = append(breadcrumbs, &CategoryLink{
+			Href:  generateCategoryLink(ancestor.UUID),
+			Title: ancestor.Name,
+		})
+	}
+
+	return &CategoryInfo{
+		UUID: c.UUID,
+		Name: c.Name,
+		Path: c.Path,
+		Links: &CategoryLinks{
+			Self:        breadcrumbs[len(breadcrumbs)-1],
+			Breadcrumbs: breadcrumbs,
+		},
+	}
+}
+
+func generateCategoryLink(categoryUUID string) string {
+	return fmt.Sprintf("%s/api/v2/categories/%s", serverAddress, categoryUUID)
+}
+
+func (nopCategoryRepository) FindByUUID(ctx context.Context, uuid string) (*category.Category, error) {
+	return nil, nil
+}
+
+func (nopCategoryRepository) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*category.Category, error) {
+	return map[string][]*category.Category{}, nil
+}
+
+func (nopCategoryRepository) SetProductCategories(ctx context.Context, productUUID string, categoryUUIDs []string) error {
+	return nil
+}
@@ -0,0 +1,66 @@
+package product
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/category"
+)
+
+func Test_serviceSetCategories(t *testing.T) {
+	men := &category.Category{UUID: "c1", Name: "Men", Path: "men"}
+	shirts := &category.Category{UUID: "c2", Name: "Shirts", Path: "men/shirts", Ancestors: []*category.Category{men}}
+	categoryRepo := &categoryRepositoryMock{categories: map[string]*category.Category{"c1": men, "c2": shirts}}
+	repo := &repositoryMock{products: map[string]*Product{
+		"p1": {UUID: "p1", Name: "Berlin New Shirt", SellerUUID: "s1", Version: 1},
+	}}
+	svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{}, WithCategoryRepository(categoryRepo))
+	ctx := context.Background()
+
+	_, err := svc.SetCategories(ctx, "p1", []string{"c2", "c9"})
+	assert.Equal(t, category.NewCategoryNotFoundError("c9"), err)
+
+	_, err = svc.SetCategories(ctx, "p2", []string{"c2"})
+	assert.Equal(t, NewProductNotFoundError("p2"), err)
+
+	got, err := svc.SetCategories(ctx, "p1", []string{"c2", "c2"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c2"}, categoryRepo.assigned["p1"])
+
+	categories, err := json.Marshal(got.Categories)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"uuid":"c2","name":"Shirts","path":"men/shirts","_links":{`+
+		`"self":{"href":"http://localhost:8080/api/v2/categories/c2","title":"Shirts"},`+
+		`"breadcrumbs":[{"href":"http://localhost:8080/api/v2/categories/c1","title":"Men"},`+
+		`{"href":"http://localhost:8080/api/v2/categories/c2","title":"Shirts"}]}}]`, string(categories))
+}
+
+type categoryRepositoryMock struct {
+	categories map[string]*category.Category
+	assigned   map[string][]string
+}
+
+func (m *categoryRepositoryMock) FindByUUID(ctx context.Context, uuid string) (*category.Category, error) {
+	return m.categories[uuid], nil
+}
+
+func (m *categoryRepositoryMock) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*category.Category, error) {
+	assigned := map[string][]*category.Category{}
+	for _, productUUID := range productUUIDs {
+		for _, categoryUUID := range m.assigned[productUUID] {
+			assigned[productUUID] = append(assigned[productUUID], m.categories[categoryUUID])
+		}
+	}
+	return assigned, nil
+}
+
+func (m *categoryRepositoryMock) SetProductCategories(ctx context.Context, productUUID string, categoryUUIDs []string) error {
+	if m.assigned == nil {
+		m.assigned = map[string][]string{}
+	}
+	m.assigned[productUUID] = categoryUUIDs
+	return nil
+}
@@ -0,0 +1,222 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"coding-challenge-go/pkg/brand"
+)
+
+// DuplicateMode decides what happens when a new product looks like one its seller already has.
+type DuplicateMode string
+
+const (
+	DuplicateModeOff    DuplicateMode = "off"
+	DuplicateModeWarn   DuplicateMode = "warn"
+	DuplicateModeReject DuplicateMode = "reject"
+
+	defaultDuplicateThreshold = 0.9
+	// duplicatePrefixLength is how many leading letters and digits of their names
+	// two products must share to be compared at all.
+	duplicatePrefixLength = 3
+	// defaultDuplicatePageSize is the number of groups in a page of the report.
+	defaultDuplicatePageSize = 20
+)
+
+type (
+	// Duplicate is an existing product a new one looks like.
+	Duplicate struct {
+		UUID       string  `json:"uuid"`
+		Name       string  `json:"name"`
+		Brand      string  `json:"brand"`
+		Similarity float64 `json:"similarity"`
+	}
+
+	// DuplicateGroup lists products of one seller that look like each other.
+	DuplicateGroup struct {
+		SellerUUID string     `json:"seller_uuid"`
+		Products   []*Product `json:"products"`
+	}
+)
+
+func ParseDuplicateMode(s string) (DuplicateMode, error) {
+	switch mode := DuplicateMode(s); mode {
+	case DuplicateModeOff, DuplicateModeWarn, DuplicateModeReject:
+		return mode, nil
+	}
+	return "", fmt.Errorf("duplicate mode must be off, warn or reject, not %q", s)
+}
+
+// similarity is 1 for products whose name and brand only differ in case, spacing
+// or punctuation. Otherwise it is the share of characters of their normalized
+// name and brand that do not need an edit to turn one into the other.
+func similarity(a *Product, b *Product) float64 {
+	nameA, nameB := brand.Normalize(a.Name), brand.Normalize(b.Name)
+	brandA, brandB := brand.Normalize(a.Brand), brand.Normalize(b.Brand)
+	if nameA == nameB && brandA == brandB {
+		return 1
+	}
+
+	x, y := []rune(nameA+" "+brandA), []rune(nameB+" "+brandB)
+	longest := len(x)
+	if len(y) > longest {
+		longest = len(y)
+	}
+	return 1 - float64(levenshtein(x, y))/float64(longest)
+}
+
+// levenshtein counts the insertions, deletions and substitutions that turn a into b.
+func levenshtein(a []rune, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minOf(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func minOf(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// namePrefix returns the first duplicatePrefixLength letters and digits of the
+// normalized name, which similarity compares, so names that only differ in
+// spacing or punctuation share it. Look-alikes are only searched among the
+// products sharing it, so the database narrows them down instead of the service
+// comparing every product of a seller.
+func namePrefix(name string) string {
+	normalized := []rune(brand.Normalize(name))
+	if len(normalized) > duplicatePrefixLength {
+		normalized = normalized[:duplicatePrefixLength]
+	}
+	return string(normalized)
+}
+
+// findDuplicate returns the live product of the same seller most like product,
+// or nil when none reaches the similarity threshold.
+func (s *service) findDuplicate(ctx context.Context, product *Product) (*Duplicate, error) {
+	var best *Duplicate
+	params := &FilterParams{SellerUUID: product.SellerUUID, NamePrefix: namePrefix(product.Name)}
+	err := s.repo.Iterate(ctx, params, func(existing *Product) error {
+		if existing.UUID == product.UUID {
+			return nil
+		}
+		score := similarity(product, existing)
+		if score >= s.duplicateThreshold && (best == nil || score > best.Similarity) {
+			best = &Duplicate{UUID: existing.UUID, Name: existing.Name, Brand: existing.Brand, Similarity: score}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return best, nil
+}
+
+// Duplicates only compares products of the same seller with the same namePrefix,
+// like findDuplicate does.
+func (s *service) Duplicates(ctx context.Context, sellerUUID string, page int) ([]*DuplicateGroup, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	type bucket struct {
+		seller string
+		prefix string
+	}
+	buckets := map[bucket][]*Product{}
+	var keys []bucket
+	err := s.repo.Iterate(ctx, &FilterParams{SellerUUID: sellerUUID}, func(product *Product) error {
+		// Iterate reuses product for every row.
+		cp := *product
+		key := bucket{seller: cp.SellerUUID, prefix: namePrefix(cp.Name)}
+		if _, ok := buckets[key]; !ok {
+			keys = append(keys, key)
+		}
+		buckets[key] = append(buckets[key], &cp)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].seller != keys[j].seller {
+			return keys[i].seller < keys[j].seller
+		}
+		return keys[i].prefix < keys[j].prefix
+	})
+	offset := (page - 1) * defaultDuplicatePageSize
+	groups := []*DuplicateGroup{}
+	for _, key := range keys {
+		for _, products := range s.groupDuplicates(buckets[key]) {
+			if offset > 0 {
+				offset--
+				continue
+			}
+			groups = append(groups, &DuplicateGroup{SellerUUID: key.seller, Products: products})
+			if len(groups) == defaultDuplicatePageSize {
+				return groups, nil
+			}
+		}
+	}
+	return groups, nil
+}
+
+// groupDuplicates joins products that look alike, directly or through another
+// product, and returns the groups with more than one product in their original order.
+func (s *service) groupDuplicates(products []*Product) [][]*Product {
+	parent := make([]int, len(products))
+	for i := range parent {
+		parent[i] = i
+	}
+	root := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	for i := range products {
+		for j := i + 1; j < len(products); j++ {
+			if similarity(products[i], products[j]) >= s.duplicateThreshold {
+				parent[root(j)] = root(i)
+			}
+		}
+	}
+
+	members := map[int][]*Product{}
+	var roots []int
+	for i, p := range products {
+		r := root(i)
+		if _, ok := members[r]; !ok {
+			roots = append(roots, r)
+		}
+		members[r] = append(members[r], p)
+	}
+	var groups [][]*Product
+	for _, r := range roots {
+		if len(members[r]) > 1 {
+			groups = append(groups, members[r])
+		}
+	}
+	return groups
+}
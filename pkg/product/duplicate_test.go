@@ -0,0 +1,120 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_similarity(t *testing.T) {
+	shirt := &Product{Name: "Berlin New Shirt", Brand: "ShirtsCo"}
+
+	assert.Equal(t, 1.0, similarity(shirt, &Product{Name: "berlin new-shirt", Brand: "Shirts Co."}))
+	assert.InDelta(t, 0.95, similarity(shirt, &Product{Name: "Berlin New Shirts", Brand: "ShirtsCo"}), 0.01)
+	assert.Less(t, similarity(shirt, &Product{Name: "Plano Tee", Brand: "TeeCo"}), 0.5)
+	assert.Equal(t, 3, levenshtein([]rune("kitten"), []rune("sitting")))
+}
+
+func Test_namePrefix(t *testing.T) {
+	assert.Equal(t, "ber", namePrefix(" Berlin New Shirt"))
+	assert.Equal(t, "2pa", namePrefix("2 Pack Bikini Brief"))
+	assert.Equal(t, "sas", namePrefix("'Sasha'"))
+	assert.Equal(t, namePrefix("LeShirt"), namePrefix("Le Shirt"))
+	assert.Equal(t, namePrefix("TShirt"), namePrefix("T-Shirt"))
+}
+
+func Test_ParseDuplicateMode(t *testing.T) {
+	mode, err := ParseDuplicateMode("reject")
+	assert.NoError(t, err)
+	assert.Equal(t, DuplicateModeReject, mode)
+
+	_, err = ParseDuplicateMode("block")
+	assert.Error(t, err)
+}
+
+func Test_serviceDuplicates(t *testing.T) {
+	newRepo := func() *repositoryMock {
+		return &repositoryMock{products: map[string]*Product{
+			"p1": {UUID: "p1", Name: "Berlin New Shirt", Brand: "ShirtsCo", SellerUUID: "s1", Version: 1},
+			"p2": {UUID: "p2", Name: "Plano Tee", Brand: "TeeCo", SellerUUID: "s1", Version: 1},
+		}}
+	}
+	ctx := context.Background()
+
+	t.Run("test reject points to the existing product", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{}, WithDuplicateCheck(DuplicateModeReject, 0))
+
+		_, err := svc.Create(ctx, &Product{UUID: "p3", Name: "berlin new shirt", Brand: "Shirts Co", SellerUUID: "s1"})
+		assert.Equal(t, NewDuplicateProductError("p1"), err)
+		assert.Len(t, repo.products, 2)
+
+		// Other sellers may sell the same product.
+		_, err = svc.Create(ctx, &Product{UUID: "p3", Name: "Berlin New Shirt", Brand: "ShirtsCo", SellerUUID: "s2"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("test names that only differ in spacing are compared", func(t *testing.T) {
+		repo := newRepo()
+		repo.products["p4"] = &Product{UUID: "p4", Name: "TShirt Classic", Brand: "TeeCo", SellerUUID: "s1", Version: 1}
+		svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{}, WithDuplicateCheck(DuplicateModeReject, 0))
+
+		_, err := svc.Create(ctx, &Product{UUID: "p3", Name: "T-Shirt Classic", Brand: "TeeCo", SellerUUID: "s1"})
+		assert.Equal(t, NewDuplicateProductError("p4"), err)
+	})
+
+	t.Run("test warn creates the product", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{}, WithDuplicateCheck(DuplicateModeWarn, 0.9))
+
+		duplicate, err := svc.Create(ctx, &Product{UUID: "p3", Name: "Berlin New Shirts", Brand: "ShirtsCo", SellerUUID: "s1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "p1", duplicate.UUID)
+		assert.Len(t, repo.products, 3)
+
+		duplicate, err = svc.Create(ctx, &Product{UUID: "p4", Name: "Oslo Hoodie", Brand: "ShirtsCo", SellerUUID: "s1"})
+		assert.NoError(t, err)
+		assert.Nil(t, duplicate)
+	})
+
+	t.Run("test report groups look-alikes per seller", func(t *testing.T) {
+		repo := newRepo()
+		repo.products["p3"] = &Product{UUID: "p3", Name: "Berlin New Shirts", Brand: "ShirtsCo", SellerUUID: "s1"}
+		repo.products["p4"] = &Product{UUID: "p4", Name: "Berlin New Shirt", Brand: "ShirtsCo", SellerUUID: "s2"}
+		svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{})
+
+		groups, err := svc.Duplicates(ctx, "", 1)
+		assert.NoError(t, err)
+		if assert.Len(t, groups, 1) {
+			assert.Equal(t, "s1", groups[0].SellerUUID)
+			assert.Equal(t, "p1", groups[0].Products[0].UUID)
+			assert.Equal(t, "p3", groups[0].Products[1].UUID)
+		}
+
+		groups, err = svc.Duplicates(ctx, "", 2)
+		assert.NoError(t, err)
+		assert.Empty(t, groups)
+	})
+
+	t.Run("test report is paginated", func(t *testing.T) {
+		repo := &repositoryMock{products: map[string]*Product{}}
+		for i := 0; i < defaultDuplicatePageSize+1; i++ {
+			name := fmt.Sprintf("%c%c%c Shirt", 'a'+i, 'a'+i, 'a'+i)
+			repo.products[fmt.Sprintf("p%02da", i)] = &Product{UUID: fmt.Sprintf("p%02da", i), Name: name, SellerUUID: "s1"}
+			repo.products[fmt.Sprintf("p%02db", i)] = &Product{UUID: fmt.Sprintf("p%02db", i), Name: name, SellerUUID: "s1"}
+		}
+		svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{})
+
+		groups, err := svc.Duplicates(ctx, "s1", 1)
+		assert.NoError(t, err)
+		assert.Len(t, groups, defaultDuplicatePageSize)
+
+		groups, err = svc.Duplicates(ctx, "s1", 2)
+		assert.NoError(t, err)
+		if assert.Len(t, groups, 1) {
+			assert.Equal(t, "p20a", groups[0].Products[0].UUID)
+		}
+	})
+}
@@ -1,6 +1,11 @@
 package product
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
 
 type ProductNotFoundError struct {
 	id string
@@ -23,3 +28,486 @@ type SellerNotFoundError struct {
 func (e SellerNotFoundError) Error() string {
 	return fmt.Sprintf("Seller is not found with id=%s", e.id)
 }
+
+type UnsupportedExportFormatError struct {
+	format string
+}
+
+func (e UnsupportedExportFormatError) Error() string {
+	return fmt.Sprintf("Export format is not supported: %s", e.format)
+}
+
+func NewUnsupportedExportFormatError(format string) error {
+	return &UnsupportedExportFormatError{
+		format: format,
+	}
+}
+
+type InvalidPatchError struct {
+	field  string
+	reason string
+}
+
+func (e InvalidPatchError) Error() string {
+	if e.field == "" {
+		return fmt.Sprintf("Patch is invalid: %s", e.reason)
+	}
+	return fmt.Sprintf("Patch is invalid for field=%s: %s", e.field, e.reason)
+}
+
+func NewInvalidPatchError(field string, reason string) error {
+	return &InvalidPatchError{
+		field:  field,
+		reason: reason,
+	}
+}
+
+type PreconditionFailedError struct {
+	id string
+}
+
+func (e PreconditionFailedError) Error() string {
+	return fmt.Sprintf("Product has been modified since it was read with id=%s", e.id)
+}
+
+func NewPreconditionFailedError(uuid string) error {
+	return &PreconditionFailedError{
+		id: uuid,
+	}
+}
+
+type InvalidStockAdjustmentError struct {
+	reason string
+}
+
+func (e InvalidStockAdjustmentError) Error() string {
+	return fmt.Sprintf("Stock adjustment is invalid: %s", e.reason)
+}
+
+func NewInvalidStockAdjustmentError(reason string) error {
+	return &InvalidStockAdjustmentError{
+		reason: reason,
+	}
+}
+
+type InsufficientStockError struct {
+	id    string
+	stock int
+	delta int
+}
+
+func (e InsufficientStockError) Error() string {
+	return fmt.Sprintf("Stock of product id=%s is %d and cannot be adjusted by %d", e.id, e.stock, e.delta)
+}
+
+func NewInsufficientStockError(uuid string, stock int, delta int) error {
+	return &InsufficientStockError{
+		id:    uuid,
+		stock: stock,
+		delta: delta,
+	}
+}
+
+type HeldStockError struct {
+	id        string
+	available int
+	delta     int
+}
+
+func (e HeldStockError) Error() string {
+	return fmt.Sprintf("Product id=%s has %d units not held by reservations and cannot be adjusted by %d", e.id, e.available, e.delta)
+}
+
+func NewHeldStockError(uuid string, available int, delta int) error {
+	return &HeldStockError{
+		id:        uuid,
+		available: available,
+		delta:     delta,
+	}
+}
+
+type ReservationNotActiveError struct {
+	id string
+}
+
+func (e ReservationNotActiveError) Error() string {
+	return fmt.Sprintf("Reservation id=%s is not active", e.id)
+}
+
+func NewReservationNotActiveError(uuid string) error {
+	return &ReservationNotActiveError{
+		id: uuid,
+	}
+}
+
+type RevisionNotFoundError struct {
+	id       string
+	revision int
+}
+
+func (e RevisionNotFoundError) Error() string {
+	return fmt.Sprintf("Revision %d is not found for product id=%s", e.revision, e.id)
+}
+
+func NewRevisionNotFoundError(uuid string, revision int) error {
+	return &RevisionNotFoundError{
+		id:       uuid,
+		revision: revision,
+	}
+}
+
+type InvalidPriceError struct {
+	reason string
+}
+
+func (e InvalidPriceError) Error() string {
+	return fmt.Sprintf("Price is invalid: %s", e.reason)
+}
+
+func NewInvalidPriceError(reason string) error {
+	return &InvalidPriceError{
+		reason: reason,
+	}
+}
+
+// Reason tells what is wrong with the price.
+func (e InvalidPriceError) Reason() string {
+	return e.reason
+}
+
+type InvalidFilterError struct {
+	field  string
+	reason string
+}
+
+func (e InvalidFilterError) Error() string {
+	return fmt.Sprintf("Filter %s is invalid: %s", e.field, e.reason)
+}
+
+func NewInvalidFilterError(field string, reason string) error {
+	return &InvalidFilterError{
+		field:  field,
+		reason: reason,
+	}
+}
+
+type InvalidVariantError struct {
+	reason string
+}
+
+func (e InvalidVariantError) Error() string {
+	return fmt.Sprintf("Variant is invalid: %s", e.reason)
+}
+
+func NewInvalidVariantError(reason string) error {
+	return &InvalidVariantError{
+		reason: reason,
+	}
+}
+
+type VariantNotFoundError struct {
+	productID string
+	id        string
+}
+
+func (e VariantNotFoundError) Error() string {
+	return fmt.Sprintf("Variant is not found with id=%s for product id=%s", e.id, e.productID)
+}
+
+func NewVariantNotFoundError(productUUID string, uuid string) error {
+	return &VariantNotFoundError{
+		productID: productUUID,
+		id:        uuid,
+	}
+}
+
+type DerivedStockError struct {
+	id     string
+	source StockSource
+}
+
+func (e DerivedStockError) Error() string {
+	if e.source == StockSourceComponent {
+		return fmt.Sprintf("Stock of bundle id=%s follows from the stock of its components", e.id)
+	}
+	return fmt.Sprintf("Stock of product id=%s is the sum of its %ss and must be changed per %s", e.id, e.source, e.source)
+}
+
+func NewDerivedStockError(uuid string, source StockSource) error {
+	return &DerivedStockError{
+		id:     uuid,
+		source: source,
+	}
+}
+
+type InvalidStockMoveError struct {
+	reason string
+}
+
+func (e InvalidStockMoveError) Error() string {
+	return fmt.Sprintf("Stock move is invalid: %s", e.reason)
+}
+
+func NewInvalidStockMoveError(reason string) error {
+	return &InvalidStockMoveError{
+		reason: reason,
+	}
+}
+
+type DuplicateProductError struct {
+	id string
+}
+
+func (e DuplicateProductError) Error() string {
+	return fmt.Sprintf("Product looks like a duplicate of product id=%s", e.id)
+}
+
+// DuplicateOf returns the UUID of the existing product.
+func (e DuplicateProductError) DuplicateOf() string {
+	return e.id
+}
+
+func NewDuplicateProductError(existingUUID string) error {
+	return &DuplicateProductError{
+		id: existingUUID,
+	}
+}
+
+type InvalidImageError struct {
+	reason string
+}
+
+func (e InvalidImageError) Error() string {
+	return fmt.Sprintf("Image is invalid: %s", e.reason)
+}
+
+func NewInvalidImageError(reason string) error {
+	return &InvalidImageError{
+		reason: reason,
+	}
+}
+
+type ImageTooLargeError struct {
+	limit int64
+}
+
+func (e ImageTooLargeError) Error() string {
+	return fmt.Sprintf("Image is larger than %d bytes", e.limit)
+}
+
+func NewImageTooLargeError(limit int64) error {
+	return &ImageTooLargeError{
+		limit: limit,
+	}
+}
+
+type UnsupportedImageTypeError struct {
+	contentType string
+}
+
+func (e UnsupportedImageTypeError) Error() string {
+	return fmt.Sprintf("Image type %s is not supported, use JPEG, PNG or GIF", e.contentType)
+}
+
+func NewUnsupportedImageTypeError(contentType string) error {
+	return &UnsupportedImageTypeError{
+		contentType: contentType,
+	}
+}
+
+type ImageNotFoundError struct {
+	productID string
+	id        string
+}
+
+func (e ImageNotFoundError) Error() string {
+	return fmt.Sprintf("Image is not found with id=%s for product id=%s", e.id, e.productID)
+}
+
+func NewImageNotFoundError(productUUID string, uuid string) error {
+	return &ImageNotFoundError{
+		productID: productUUID,
+		id:        uuid,
+	}
+}
+
+type InvalidAttributesError struct {
+	reason string
+}
+
+func (e InvalidAttributesError) Error() string {
+	return fmt.Sprintf("Attributes are invalid: %s", e.reason)
+}
+
+func NewInvalidAttributesError(reason string) error {
+	return &InvalidAttributesError{
+		reason: reason,
+	}
+}
+
+type InvalidIdentifierError struct {
+	field  string
+	reason string
+}
+
+func (e InvalidIdentifierError) Error() string {
+	return fmt.Sprintf("Identifier %s is invalid: %s", e.field, e.reason)
+}
+
+func NewInvalidIdentifierError(field string, reason string) error {
+	return &InvalidIdentifierError{
+		field:  field,
+		reason: reason,
+	}
+}
+
+// Field is the identifier that is invalid, sku or gtin.
+func (e InvalidIdentifierError) Field() string {
+	return e.field
+}
+
+// Reason tells what is wrong with the identifier.
+func (e InvalidIdentifierError) Reason() string {
+	return e.reason
+}
+
+type IdentifierConflictError struct {
+	field   string
+	value   string
+	otherID string
+}
+
+func (e IdentifierConflictError) Error() string {
+	if e.otherID == "" {
+		return fmt.Sprintf("Another product already uses %s=%s", e.field, e.value)
+	}
+	return fmt.Sprintf("Product id=%s already uses %s=%s", e.otherID, e.field, e.value)
+}
+
+func NewIdentifierConflictError(field string, value string, otherUUID string) error {
+	return &IdentifierConflictError{
+		field:   field,
+		value:   value,
+		otherID: otherUUID,
+	}
+}
+
+type InvalidStatusError struct {
+	status string
+}
+
+func (e InvalidStatusError) Error() string {
+	return fmt.Sprintf("Status must be draft, published or archived, not %q", e.status)
+}
+
+func NewInvalidStatusError(status string) error {
+	return &InvalidStatusError{
+		status: status,
+	}
+}
+
+type StatusTransitionError struct {
+	id   string
+	from Status
+	to   Status
+}
+
+func (e StatusTransitionError) Error() string {
+	if e.from == "" {
+		return fmt.Sprintf("Product id=%s cannot be created as %s", e.id, e.to)
+	}
+	return fmt.Sprintf("Product id=%s cannot move from %s to %s", e.id, e.from, e.to)
+}
+
+func NewStatusTransitionError(uuid string, from Status, to Status) error {
+	return &StatusTransitionError{
+		id:   uuid,
+		from: from,
+		to:   to,
+	}
+}
+
+type InvalidBundleError struct {
+	reason string
+}
+
+func (e InvalidBundleError) Error() string {
+	return fmt.Sprintf("Bundle is invalid: %s", e.reason)
+}
+
+func NewInvalidBundleError(reason string) error {
+	return &InvalidBundleError{
+		reason: reason,
+	}
+}
+
+type BundleComponentError struct {
+	id     string
+	bundle string
+	source StockSource
+}
+
+func (e BundleComponentError) Error() string {
+	return fmt.Sprintf("Product id=%s is a component of bundle id=%s and cannot split its stock by %s", e.id, e.bundle, e.source)
+}
+
+func NewBundleComponentError(uuid string, bundleUUID string, source StockSource) error {
+	return &BundleComponentError{
+		id:     uuid,
+		bundle: bundleUUID,
+		source: source,
+	}
+}
+
+type InvalidPriceAlertError struct {
+	reason string
+}
+
+func (e InvalidPriceAlertError) Error() string {
+	return fmt.Sprintf("Price alert is invalid: %s", e.reason)
+}
+
+func NewInvalidPriceAlertError(reason string) error {
+	return &InvalidPriceAlertError{
+		reason: reason,
+	}
+}
+
+type PriceAlertNotFoundError struct {
+	productID string
+	id        string
+}
+
+func (e PriceAlertNotFoundError) Error() string {
+	return fmt.Sprintf("Price alert id=%s is not found for product id=%s", e.id, e.productID)
+}
+
+func NewPriceAlertNotFoundError(productUUID string, uuid string) error {
+	return &PriceAlertNotFoundError{
+		productID: productUUID,
+		id:        uuid,
+	}
+}
+
+type InvalidFieldsError struct {
+	fields validator.ValidationErrors
+}
+
+func (e InvalidFieldsError) Error() string {
+	broken := make([]string, 0, len(e.fields))
+	for _, fe := range e.fields {
+		broken = append(broken, fmt.Sprintf("%s breaks rule %s", fe.Field(), fe.Tag()))
+	}
+	return fmt.Sprintf("Product is invalid: %s", strings.Join(broken, ", "))
+}
+
+func NewInvalidFieldsError(fields validator.ValidationErrors) error {
+	return &InvalidFieldsError{
+		fields: fields,
+	}
+}
+
+// Fields lists the rules broken, one per field.
+func (e InvalidFieldsError) Fields() validator.ValidationErrors {
+	return e.fields
+}
@@ -0,0 +1,114 @@
+package product
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+var csvHeader = []string{"uuid", "name", "brand", "stock", "seller_uuid", "price_amount", "price_currency"}
+
+type (
+	// RowWriter encodes products one at a time so an export never holds more than one row in memory.
+	RowWriter interface {
+		Write(product *Product) error
+		// Flush writes any buffered data to the underlying writer.
+		Flush() error
+	}
+
+	csvRowWriter struct {
+		w             *csv.Writer
+		headerWritten bool
+	}
+
+	ndjsonRowWriter struct {
+		w   *bufio.Writer
+		enc *json.Encoder
+	}
+)
+
+// ParseExportFormat returns the ExportFormat named by s.
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch ExportFormat(s) {
+	case ExportFormatCSV, ExportFormatNDJSON:
+		return ExportFormat(s), nil
+	}
+	return "", NewUnsupportedExportFormatError(s)
+}
+
+// ContentType returns the MIME type of the format.
+func (f ExportFormat) ContentType() string {
+	if f == ExportFormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+func NewRowWriter(format ExportFormat, w io.Writer) (RowWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		return &csvRowWriter{w: csv.NewWriter(w)}, nil
+	case ExportFormatNDJSON:
+		bw := bufio.NewWriter(w)
+		return &ndjsonRowWriter{w: bw, enc: json.NewEncoder(bw)}, nil
+	}
+	return nil, NewUnsupportedExportFormatError(string(format))
+}
+
+func (cw *csvRowWriter) Write(product *Product) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	var amount, currency string
+	if product.Price != nil {
+		amount = strconv.FormatInt(product.Price.Amount, 10)
+		currency = product.Price.Currency
+	}
+	return cw.w.Write([]string{
+		product.UUID,
+		product.Name,
+		product.Brand,
+		strconv.Itoa(product.Stock),
+		product.SellerUUID,
+		amount,
+		currency,
+	})
+}
+
+func (cw *csvRowWriter) Flush() error {
+	// An empty export still gets a header row.
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvRowWriter) writeHeader() error {
+	if cw.headerWritten {
+		return nil
+	}
+	cw.headerWritten = true
+	if err := cw.w.Write(csvHeader); err != nil {
+		return fmt.Errorf("write csv header: %w", err)
+	}
+	return nil
+}
+
+func (nw *ndjsonRowWriter) Write(product *Product) error {
+	return nw.enc.Encode(product)
+}
+
+func (nw *ndjsonRowWriter) Flush() error {
+	return nw.w.Flush()
+}
@@ -0,0 +1,89 @@
+package product
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/money"
+)
+
+func Test_RowWriter(t *testing.T) {
+	products := []*Product{
+		{
+			ProductID:      1,
+			UUID:           "e6461ea4-d698-11eb-890b-0242ac1a0002",
+			Name:           "Pure Linen, Plain Shirt",
+			Brand:          "ShirtsCo",
+			Stock:          44,
+			AvailableStock: 40,
+			SellerUUID:     "e6461ea4-d698-11eb-890b-0242ac1a0003",
+			Price:          &money.Money{Amount: 2999, Currency: "EUR"},
+		},
+		{
+			ProductID:      2,
+			UUID:           "e6461ea4-d698-11eb-890b-0242ac1a0004",
+			Name:           "Plano Tee",
+			Brand:          "TeeCo",
+			Stock:          10,
+			AvailableStock: 10,
+			SellerUUID:     "e6461ea4-d698-11eb-890b-0242ac1a0003",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		format   ExportFormat
+		products []*Product
+		want     string
+	}{
+		{
+			name:     "test csv export",
+			format:   ExportFormatCSV,
+			products: products,
+			want: "uuid,name,brand,stock,seller_uuid,price_amount,price_currency\n" +
+				"e6461ea4-d698-11eb-890b-0242ac1a0002,\"Pure Linen, Plain Shirt\",ShirtsCo,44,e6461ea4-d698-11eb-890b-0242ac1a0003,2999,EUR\n" +
+				"e6461ea4-d698-11eb-890b-0242ac1a0004,Plano Tee,TeeCo,10,e6461ea4-d698-11eb-890b-0242ac1a0003,,\n",
+		},
+		{
+			name:   "test empty csv export has header",
+			format: ExportFormatCSV,
+			want:   "uuid,name,brand,stock,seller_uuid,price_amount,price_currency\n",
+		},
+		{
+			name:     "test ndjson export",
+			format:   ExportFormatNDJSON,
+			products: products,
+			want: `{"uuid":"e6461ea4-d698-11eb-890b-0242ac1a0002","name":"Pure Linen, Plain Shirt","brand":"ShirtsCo","stock":44,"available_stock":40,"seller_uuid":"e6461ea4-d698-11eb-890b-0242ac1a0003","price":{"amount":2999,"currency":"EUR"}}` + "\n" +
+				`{"uuid":"e6461ea4-d698-11eb-890b-0242ac1a0004","name":"Plano Tee","brand":"TeeCo","stock":10,"available_stock":10,"seller_uuid":"e6461ea4-d698-11eb-890b-0242ac1a0003","price":null}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			rw, err := NewRowWriter(tt.format, buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range tt.products {
+				if err := rw.Write(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := rw.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func Test_ParseExportFormat(t *testing.T) {
+	_, err := ParseExportFormat("xml")
+	assert.Equal(t, NewUnsupportedExportFormatError("xml"), err)
+
+	format, err := ParseExportFormat("ndjson")
+	assert.NoError(t, err)
+	assert.Equal(t, ExportFormatNDJSON, format)
+}
@@ -0,0 +1,91 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// gtinLength is the length of a GTIN-14. Shorter GTINs are the same number
+// with leading zeros dropped.
+const gtinLength = 14
+
+// gtinLengths are the lengths of EAN-8, UPC-A, EAN-13 and GTIN-14 codes.
+var gtinLengths = map[int]bool{8: true, 12: true, 13: true, 14: true}
+
+// ValidateGTIN checks that code is an EAN-8, UPC-A, EAN-13 or GTIN-14 with a
+// correct check digit.
+func ValidateGTIN(code string) error {
+	if !gtinLengths[len(code)] {
+		return NewInvalidIdentifierError("gtin", "must have 8, 12, 13 or 14 digits")
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return NewInvalidIdentifierError("gtin", "must only contain digits")
+		}
+	}
+	if want := gtinCheckDigit(code[:len(code)-1]); int(code[len(code)-1]-'0') != want {
+		return NewInvalidIdentifierError("gtin", fmt.Sprintf("check digit must be %d", want))
+	}
+	return nil
+}
+
+// normalizeGTIN pads a valid code to a GTIN-14, so a UPC-A and the EAN-13 that
+// is the same code with a leading zero are found as one.
+func normalizeGTIN(code string) string {
+	return strings.Repeat("0", gtinLength-len(code)) + code
+}
+
+// gtinCheckDigit computes the GS1 check digit of the digits before it. Weights
+// alternate 3 and 1 starting from the rightmost digit.
+func gtinCheckDigit(digits string) int {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10 - sum%10) % 10
+}
+
+// validateIdentifiers checks the format of the SKU and the GTIN. Both are optional.
+func (p *Product) validateIdentifiers() error {
+	if len(p.SKU) > maxSKULength || strings.IndexFunc(p.SKU, unicode.IsSpace) >= 0 {
+		return NewInvalidIdentifierError("sku", fmt.Sprintf("must be at most %d characters without spaces", maxSKULength))
+	}
+	if p.GTIN != "" {
+		return ValidateGTIN(p.GTIN)
+	}
+	return nil
+}
+
+// checkIdentifiers fails when another product already uses the SKU of product
+// within its seller or its GTIN. Products in the trash keep their identifiers,
+// so they can always be restored.
+func (s *service) checkIdentifiers(ctx context.Context, product *Product) error {
+	if err := product.validateIdentifiers(); err != nil {
+		return err
+	}
+	if product.SKU != "" {
+		other, err := s.repo.FindBySKU(ctx, product.SellerUUID, product.SKU)
+		if err != nil {
+			return err
+		}
+		if other != nil && other.UUID != product.UUID {
+			return NewIdentifierConflictError("sku", product.SKU, other.UUID)
+		}
+	}
+	if product.GTIN != "" {
+		other, err := s.repo.FindByGTIN(ctx, normalizeGTIN(product.GTIN))
+		if err != nil {
+			return err
+		}
+		if other != nil && other.UUID != product.UUID {
+			return NewIdentifierConflictError("gtin", product.GTIN, other.UUID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package product
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ValidateGTIN(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		wantErr error
+	}{
+		{name: "EAN-13", code: "4006381333931"},
+		{name: "UPC-A", code: "036000291452"},
+		{name: "EAN-8", code: "96385074"},
+		{name: "GTIN-14", code: "10036000291459"},
+		{name: "wrong check digit", code: "4006381333932", wantErr: NewInvalidIdentifierError("gtin", "check digit must be 1")},
+		{name: "EAN-13 missing a digit", code: "400638133393", wantErr: NewInvalidIdentifierError("gtin", "check digit must be 0")},
+		{name: "unsupported length", code: "40063813339", wantErr: NewInvalidIdentifierError("gtin", "must have 8, 12, 13 or 14 digits")},
+		{name: "not digits", code: "40063813339X", wantErr: NewInvalidIdentifierError("gtin", "must only contain digits")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, ValidateGTIN(tt.code))
+		})
+	}
+}
+
+func Test_serviceIdentifiers(t *testing.T) {
+	repo := &repositoryMock{products: map[string]*Product{
+		"p1": {UUID: "p1", Name: "Berlin New Shirt", SellerUUID: "s1", Version: 1, SKU: "SHIRT-1", GTIN: "036000291452"},
+	}}
+	svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{})
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, &Product{UUID: "p2", Name: "Plano Tee", Brand: "Plano", SellerUUID: "s1", SKU: "SHIRT-1"})
+	assert.Equal(t, NewIdentifierConflictError("sku", "SHIRT-1", "p1"), err)
+
+	// SKUs are only unique per seller.
+	_, err = svc.Create(ctx, &Product{UUID: "p2", Name: "Plano Tee", Brand: "Plano", SellerUUID: "s2", SKU: "SHIRT-1"})
+	assert.NoError(t, err)
+
+	// The EAN-13 form of the UPC-A of p1 is the same code.
+	_, err = svc.Create(ctx, &Product{UUID: "p3", Name: "Plano Tee", Brand: "Plano", SellerUUID: "s2", GTIN: "0036000291452"})
+	assert.Equal(t, NewIdentifierConflictError("gtin", "0036000291452", "p1"), err)
+
+	_, err = svc.Create(ctx, &Product{UUID: "p3", Name: "Plano Tee", Brand: "Plano", SellerUUID: "s2", SKU: "TEE 1"})
+	assert.Equal(t, NewInvalidIdentifierError("sku", "must be at most 64 characters without spaces"), err)
+
+	info, err := svc.FindByGTIN(ctx, "0036000291452")
+	assert.NoError(t, err)
+	assert.Equal(t, "p1", info.UUID)
+
+	// PUT without identifiers keeps them.
+	assert.NoError(t, svc.Update(ctx, &Product{UUID: "p1", Name: "Berlin Shirt", Brand: "Plano"}))
+	assert.Equal(t, "SHIRT-1", repo.products["p1"].SKU)
+	assert.Equal(t, "036000291452", repo.products["p1"].GTIN)
+
+	none := ""
+	info, err = svc.Patch(ctx, "p1", 0, &Patch{GTIN: &none})
+	assert.NoError(t, err)
+	assert.Equal(t, "", info.GTIN)
+
+	_, err = svc.FindByGTIN(ctx, "036000291452")
+	assert.Equal(t, NewProductNotFoundError("036000291452"), err)
+}
@@ -0,0 +1,227 @@
+package product
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"time"
+
+	// Registered for image.Decode; thumbnails of GIFs are written as PNG.
+	_ "image/gif"
+)
+
+const (
+	// ImageSizeOriginal names the image as it was uploaded.
+	ImageSizeOriginal = "original"
+
+	defaultMaxImageSize = 5 << 20
+	// maxImagePixels bounds the decoded size, so a small file cannot expand into a huge bitmap.
+	maxImagePixels   = 50 * 1000 * 1000
+	thumbnailQuality = 85
+)
+
+// thumbnailSizes are the renditions generated for every image. Each fits in a
+// square of the given edge; images are never scaled up.
+var thumbnailSizes = []struct {
+	name string
+	edge int
+}{
+	{name: "small", edge: 160},
+	{name: "medium", edge: 480},
+	{name: "large", edge: 1024},
+}
+
+// imageContentTypes are the sniffed content types accepted for upload.
+var imageContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+type (
+	Image struct {
+		ImageID     int       `json:"-"`
+		UUID        string    `json:"uuid"`
+		ProductUUID string    `json:"-"`
+		ContentType string    `json:"content_type"`
+		Width       int       `json:"width"`
+		Height      int       `json:"height"`
+		Size        int64     `json:"size"`
+		CreatedAt   time.Time `json:"created_at"`
+	}
+
+	ImageRepository interface {
+		// ListByProducts returns the images of each product, oldest first.
+		ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*Image, error)
+		Create(ctx context.Context, image *Image) error
+		// Delete reports false when the product has no such image.
+		Delete(ctx context.Context, productUUID string, uuid string) (bool, error)
+	}
+
+	// nopImageRepository is used when the service is built without an image repository.
+	nopImageRepository struct{}
+
+	ImageInfo struct {
+		*Image
+		Links *ImageLinks `json:"_links"`
+	}
+
+	ImageLinks struct {
+		Self *ImageLink `json:"self"`
+	}
+
+	ImageLink struct {
+		Href       string            `json:"href"`
+		Type       string            `json:"type"`
+		Thumbnails map[string]string `json:"thumbnails"`
+	}
+)
+
+func (nopImageRepository) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*Image, error) {
+	return map[string][]*Image{}, nil
+}
+
+func (nopImageRepository) Create(ctx context.Context, image *Image) error {
+	return NewInvalidImageError("images are not supported")
+}
+
+func (nopImageRepository) Delete(ctx context.Context, productUUID string, uuid string) (bool, error) {
+	return false, nil
+}
+
+// ContentTypeOf returns the content type of the given size of the image.
+func (i *Image) ContentTypeOf(size string) string {
+	if size == ImageSizeOriginal || i.ContentType == "image/jpeg" {
+		return i.ContentType
+	}
+	return "image/png"
+}
+
+// isImageSize reports whether size names the original or one of the thumbnails.
+func isImageSize(size string) bool {
+	if size == ImageSizeOriginal {
+		return true
+	}
+	for _, t := range thumbnailSizes {
+		if t.name == size {
+			return true
+		}
+	}
+	return false
+}
+
+// imageKey is where the given size of an image is kept in storage.
+func imageKey(productUUID string, imageUUID string, size string) string {
+	return fmt.Sprintf("%s/%s", imagePrefix(productUUID, imageUUID), size)
+}
+
+func imagePrefix(productUUID string, imageUUID string) string {
+	return fmt.Sprintf("%s/%s", productImagesPrefix(productUUID), imageUUID)
+}
+
+// productImagesPrefix holds every image of a product in storage.
+func productImagesPrefix(productUUID string) string {
+	return fmt.Sprintf("products/%s/images", productUUID)
+}
+
+// decodeImage checks the dimensions of data before decoding it.
+func decodeImage(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, NewInvalidImageError(err.Error())
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, NewInvalidImageError(fmt.Sprintf("%dx%d pixels is more than %d", cfg.Width, cfg.Height, maxImagePixels))
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, NewInvalidImageError(err.Error())
+	}
+	return img, nil
+}
+
+// encodeThumbnail writes img as JPEG when the original is one, and as PNG otherwise
+// so transparency survives.
+func encodeThumbnail(w io.Writer, img image.Image, contentType string) error {
+	if contentType == "image/jpeg" {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: thumbnailQuality})
+	}
+	return png.Encode(w, img)
+}
+
+// thumbnail scales img down to fit in an edge by edge square. Each target pixel
+// is the average of the source pixels it covers.
+func thumbnail(img image.Image, edge int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	tw, th := w, h
+	switch {
+	case w <= edge && h <= edge:
+	case w >= h:
+		tw, th = edge, h*edge/w
+	default:
+		tw, th = w*edge/h, edge
+	}
+	if tw < 1 {
+		tw = 1
+	}
+	if th < 1 {
+		th = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := b.Min.Y+y*h/th, b.Min.Y+(y+1)*h/th
+		for x := 0; x < tw; x++ {
+			x0, x1 := b.Min.X+x*w/tw, b.Min.X+(x+1)*w/tw
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// storeImage writes the original data and every thumbnail of meta to storage.
+func (s *service) storeImage(ctx context.Context, meta *Image, data []byte, decoded image.Image) error {
+	err := s.imageStorage.Put(ctx, imageKey(meta.ProductUUID, meta.UUID, ImageSizeOriginal), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for _, t := range thumbnailSizes {
+		buf := &bytes.Buffer{}
+		if err := encodeThumbnail(buf, thumbnail(decoded, t.edge), meta.ContentType); err != nil {
+			return err
+		}
+		if err := s.imageStorage.Put(ctx, imageKey(meta.ProductUUID, meta.UUID, t.name), buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func generateImageInfo(image *Image) *ImageInfo {
+	return &ImageInfo{
+		Image: image,
+		Links: &ImageLinks{Self: generateImageLink(image)},
+	}
+}
+
+func generateImageLink(image *Image) *ImageLink {
+	href := fmt.Sprintf("%s/api/v2/products/%s/images/%s", serverAddress, image.ProductUUID, image.UUID)
+	link := &ImageLink{Href: href, Type: image.ContentType, Thumbnails: map[string]string{}}
+	for _, t := range thumbnailSizes {
+		link.Thumbnails[t.name] = fmt.Sprintf("%s?size=%s", href, t.name)
+	}
+	return link
+}
@@ -0,0 +1,88 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+func NewImageRepository(db *sql.DB) ImageRepository {
+	return &imageRepository{db: db}
+}
+
+type imageRepository struct {
+	db *sql.DB
+}
+
+func (r *imageRepository) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*Image, error) {
+	images := map[string][]*Image{}
+	if len(productUUIDs) == 0 {
+		return images, nil
+	}
+
+	args := make([]interface{}, len(productUUIDs))
+	for i, uuid := range productUUIDs {
+		args[i] = uuid
+	}
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT i.id_product_image, i.uuid, p.uuid, i.content_type, i.width, i.height, i.size, i.created_at FROM product_image i "+
+			"INNER JOIN product p ON(p.id_product = i.fk_product) "+
+			"WHERE p.uuid IN (?"+strings.Repeat(",?", len(productUUIDs)-1)+") ORDER BY i.id_product_image",
+		args...,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		image := &Image{}
+		err := rows.Scan(&image.ImageID, &image.UUID, &image.ProductUUID, &image.ContentType,
+			&image.Width, &image.Height, &image.Size, &image.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		images[image.ProductUUID] = append(images[image.ProductUUID], image)
+	}
+
+	return images, rows.Err()
+}
+
+func (r *imageRepository) Create(ctx context.Context, image *Image) error {
+	result, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO product_image (uuid, fk_product, content_type, width, height, size, created_at) "+
+			"SELECT ?, id_product, ?, ?, ?, ?, ? FROM product WHERE uuid = ? AND deleted_at IS NULL",
+		image.UUID, image.ContentType, image.Width, image.Height, image.Size, image.CreatedAt, image.ProductUUID,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return NewProductNotFoundError(image.ProductUUID)
+	}
+	return nil
+}
+
+func (r *imageRepository) Delete(ctx context.Context, productUUID string, uuid string) (bool, error) {
+	result, err := r.db.ExecContext(
+		ctx,
+		"DELETE i FROM product_image i INNER JOIN product p ON(p.id_product = i.fk_product) WHERE p.uuid = ? AND i.uuid = ?",
+		productUUID, uuid,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
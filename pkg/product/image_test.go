@@ -0,0 +1,168 @@
+package product
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/storage"
+)
+
+func Test_serviceAddImage(t *testing.T) {
+	store := &storageMock{}
+	imageRepo := &imageRepositoryMock{}
+	repo := &repositoryMock{products: map[string]*Product{
+		"p1": {UUID: "p1", Name: "Berlin New Shirt", SellerUUID: "s1", Version: 1},
+	}}
+	svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{},
+		WithImageRepository(imageRepo), WithImageStorage(store), WithMaxImageSize(64<<10))
+	ctx := context.Background()
+
+	_, err := svc.AddImage(ctx, "p1", strings.NewReader("just some text"))
+	assert.Equal(t, NewUnsupportedImageTypeError("text/plain; charset=utf-8"), err)
+
+	_, err = svc.AddImage(ctx, "p1", bytes.NewReader(make([]byte, 64<<10+1)))
+	assert.Equal(t, NewImageTooLargeError(64<<10), err)
+
+	_, err = svc.AddImage(ctx, "p2", encodePNG(t, 10, 10))
+	assert.Equal(t, NewProductNotFoundError("p2"), err)
+
+	info, err := svc.AddImage(ctx, "p1", encodePNG(t, 600, 300))
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", info.ContentType)
+	assert.Equal(t, 600, info.Width)
+	assert.Len(t, store.objects, 4)
+
+	small, err := png.DecodeConfig(bytes.NewReader(store.objects[imageKey("p1", info.UUID, "small")]))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{160, 80}, []int{small.Width, small.Height})
+	large, err := png.DecodeConfig(bytes.NewReader(store.objects[imageKey("p1", info.UUID, "large")]))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{600, 300}, []int{large.Width, large.Height})
+
+	product, err := svc.FindByUUID(ctx, "p1")
+	assert.NoError(t, err)
+	links, err := json.Marshal(product.Links)
+	assert.NoError(t, err)
+	href := "http://localhost:8080/api/v2/products/p1/images/" + info.UUID
+	assert.Equal(t, `{"images":[{"href":"`+href+`","type":"image/png","thumbnails":{`+
+		`"large":"`+href+`?size=large","medium":"`+href+`?size=medium","small":"`+href+`?size=small"}}]}`, string(links))
+
+	_, object, err := svc.OpenImage(ctx, "p1", info.UUID, "medium")
+	assert.NoError(t, err)
+	assert.NoError(t, object.Close())
+	_, _, err = svc.OpenImage(ctx, "p1", info.UUID, "huge")
+	assert.Equal(t, NewInvalidImageError("unknown size huge"), err)
+
+	assert.NoError(t, svc.DeleteImage(ctx, "p1", info.UUID))
+	assert.Empty(t, store.objects)
+	assert.Equal(t, NewImageNotFoundError("p1", info.UUID), svc.DeleteImage(ctx, "p1", info.UUID))
+
+	// Purging a product removes the files of its images.
+	_, err = svc.AddImage(ctx, "p1", encodePNG(t, 10, 10))
+	assert.NoError(t, err)
+	assert.NoError(t, svc.Delete(ctx, "p1", 0))
+	purged, err := svc.PurgeDeleted(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	assert.Empty(t, store.objects)
+}
+
+func Test_serviceAddImageWithoutStorage(t *testing.T) {
+	repo := &repositoryMock{products: map[string]*Product{"p1": {UUID: "p1", SellerUUID: "s1", Version: 1}}}
+	svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{})
+
+	_, err := svc.AddImage(context.Background(), "p1", encodePNG(t, 10, 10))
+	assert.Equal(t, NewInvalidImageError("images are not supported"), err)
+}
+
+func Test_thumbnail(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	src.Set(0, 0, color.White)
+	src.Set(1, 0, color.White)
+	src.Set(0, 1, color.White)
+	src.Set(1, 1, color.White)
+
+	dst := thumbnail(src, 2)
+	assert.Equal(t, image.Rect(0, 0, 2, 1), dst.Bounds())
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, dst.At(0, 0))
+	assert.Equal(t, color.RGBA{}, dst.At(1, 0))
+
+	assert.Equal(t, image.Rect(0, 0, 4, 2), thumbnail(src, 10).Bounds())
+}
+
+func encodePNG(t *testing.T, width int, height int) io.Reader {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, png.Encode(buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf
+}
+
+type storageMock struct {
+	objects map[string][]byte
+}
+
+func (m *storageMock) Put(ctx context.Context, key string, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if m.objects == nil {
+		m.objects = map[string][]byte{}
+	}
+	m.objects[key] = data
+	return nil
+}
+
+func (m *storageMock) Open(ctx context.Context, key string) (*storage.Object, error) {
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, storage.NewObjectNotFoundError(key)
+	}
+	return &storage.Object{ReadCloser: ioutil.NopCloser(bytes.NewReader(data)), Size: int64(len(data)), ModTime: time.Now()}, nil
+}
+
+func (m *storageMock) Delete(ctx context.Context, prefix string) error {
+	for key := range m.objects {
+		if strings.HasPrefix(key, prefix+"/") {
+			delete(m.objects, key)
+		}
+	}
+	return nil
+}
+
+type imageRepositoryMock struct {
+	images []*Image
+}
+
+func (m *imageRepositoryMock) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*Image, error) {
+	images := map[string][]*Image{}
+	for _, image := range m.images {
+		images[image.ProductUUID] = append(images[image.ProductUUID], image)
+	}
+	return images, nil
+}
+
+func (m *imageRepositoryMock) Create(ctx context.Context, image *Image) error {
+	m.images = append(m.images, image)
+	return nil
+}
+
+func (m *imageRepositoryMock) Delete(ctx context.Context, productUUID string, uuid string) (bool, error) {
+	for i, image := range m.images {
+		if image.ProductUUID == productUUID && image.UUID == uuid {
+			m.images = append(m.images[:i], m.images[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,24 @@
+package product
+
+type ProductLinks struct {
+	Brand  *BrandLink   `json:"brand,omitempty"`
+	Images []*ImageLink `json:"images,omitempty"`
+}
+
+// generateProductLinks returns the links of a product, or nil when it has none.
+func generateProductLinks(product *Product, images []*Image) *ProductLinks {
+	if product.BrandUUID == "" && len(images) == 0 {
+		return nil
+	}
+	links := &ProductLinks{}
+	if product.BrandUUID != "" {
+		links.Brand = &BrandLink{
+			Href:  generateBrandLink(product.BrandUUID),
+			Title: product.Brand,
+		}
+	}
+	for _, image := range images {
+		links.Images = append(links.Images, generateImageLink(image))
+	}
+	return links
+}
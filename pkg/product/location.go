@@ -0,0 +1,152 @@
+package product
+
+import (
+	"context"
+	"fmt"
+
+	"coding-challenge-go/pkg/location"
+)
+
+// StockSource names what the stock of a product is derived from.
+type StockSource string
+
+const (
+	StockSourceVariant  StockSource = "variant"
+	StockSourceLocation StockSource = "location"
+	// StockSourceComponent is the stock of a bundle, the number of bundles its components make.
+	StockSourceComponent StockSource = "component"
+)
+
+type (
+	// LocationStock is the stock of a product held at one location.
+	LocationStock struct {
+		LocationUUID string `json:"location_uuid"`
+		Name         string `json:"name"`
+		Stock        int    `json:"stock"`
+	}
+
+	// StockMove takes Quantity units of a product from one location to another.
+	StockMove struct {
+		From     string
+		To       string
+		Quantity int
+		Reason   string
+	}
+
+	// LocationRepository is the part of location.Repository the product service uses.
+	LocationRepository interface {
+		FindByUUID(ctx context.Context, uuid string) (*location.Location, error)
+	}
+
+	LocationStockRepository interface {
+		// ListByProducts returns the stock of each product per location, ordered by location name.
+		ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*LocationStock, error)
+		// AdjustStock adds delta to the stock at a location unless it would drop below
+		// zero and allowNegative is false. It returns the location stock and the
+		// product with its new total stock, whose change is written as movement in
+		// the same transaction.
+		AdjustStock(ctx context.Context, productUUID string, locationUUID string, delta int, allowNegative bool, movement *StockMovement) (*LocationStock, *Product, error)
+		// Move takes move.Quantity units from move.From to move.To in one transaction.
+		// It returns the stock at both locations and the product.
+		Move(ctx context.Context, productUUID string, move *StockMove) (*LocationStock, *LocationStock, *Product, error)
+	}
+
+	// nopLocationRepository and nopLocationStockRepository are used when the
+	// service is built without locations.
+	nopLocationRepository      struct{}
+	nopLocationStockRepository struct{}
+)
+
+// Label names the stock at the location in notifications, e.g. "Berlin New Shirt (location: Berlin DC)".
+func (l *LocationStock) Label(productName string) string {
+	return fmt.Sprintf("%s (location: %s)", productName, l.Name)
+}
+
+func (m *StockMove) validate() error {
+	if m.Quantity <= 0 {
+		return NewInvalidStockMoveError("quantity must be positive")
+	}
+	if m.From == "" || m.To == "" {
+		return NewInvalidStockMoveError("from and to are required")
+	}
+	if m.From == m.To {
+		return NewInvalidStockMoveError("from and to must be different locations")
+	}
+	if m.Reason == "" {
+		return NewInvalidStockMoveError("reason is required")
+	}
+	if len(m.Reason) > maxStockAdjustmentReasonLength {
+		return NewInvalidStockMoveError("reason is too long")
+	}
+	return nil
+}
+
+// stockSource reports what the stock of a product is derived from, or "" when
+// the stock is written directly.
+func (s *service) stockSource(ctx context.Context, uuid string) (StockSource, error) {
+	variants, err := s.variantRepo.ListByProducts(ctx, []string{uuid})
+	if err != nil {
+		return "", err
+	}
+	if len(variants[uuid]) > 0 {
+		return StockSourceVariant, nil
+	}
+	locations, err := s.locationStockRepo.ListByProducts(ctx, []string{uuid})
+	if err != nil {
+		return "", err
+	}
+	if len(locations[uuid]) > 0 {
+		return StockSourceLocation, nil
+	}
+	components, err := s.bundleRepo.ListByProducts(ctx, []string{uuid})
+	if err != nil {
+		return "", err
+	}
+	if len(components[uuid]) > 0 {
+		return StockSourceComponent, nil
+	}
+	return "", nil
+}
+
+// findLocation returns the location when it belongs to the seller of product.
+// Locations of other sellers are reported as not found.
+func (s *service) findLocation(ctx context.Context, uuid string, product *Product) (*location.Location, error) {
+	l, err := s.locationRepo.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil || l.SellerUUID != product.SellerUUID {
+		return nil, location.NewLocationNotFoundError(uuid)
+	}
+	return l, nil
+}
+
+// notifyLocationStockChanged warns the seller of product that its stock at a
+// location changed from oldStock. The notification names the location.
+func (s *service) notifyLocationStockChanged(ctx context.Context, oldStock int, stock *LocationStock, product *Product) error {
+	if !notifiesStock(product) {
+		return nil
+	}
+	sl, err := s.findSeller(ctx, product.SellerUUID)
+	if err != nil {
+		return err
+	}
+	s.notiProvider.StockChanged(oldStock, stock.Stock, stock.Label(product.Name), sl)
+	return nil
+}
+
+func (nopLocationRepository) FindByUUID(ctx context.Context, uuid string) (*location.Location, error) {
+	return nil, nil
+}
+
+func (nopLocationStockRepository) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*LocationStock, error) {
+	return map[string][]*LocationStock{}, nil
+}
+
+func (nopLocationStockRepository) AdjustStock(ctx context.Context, productUUID string, locationUUID string, delta int, allowNegative bool, movement *StockMovement) (*LocationStock, *Product, error) {
+	return nil, nil, location.NewLocationNotFoundError(locationUUID)
+}
+
+func (nopLocationStockRepository) Move(ctx context.Context, productUUID string, move *StockMove) (*LocationStock, *LocationStock, *Product, error) {
+	return nil, nil, nil, location.NewLocationNotFoundError(move.From)
+}
@@ -0,0 +1,224 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"coding-challenge-go/pkg/location"
+)
+
+const (
+	selectLocationStockQuery = "SELECT p.uuid, l.uuid, l.name, ps.stock FROM product_stock ps " +
+		"INNER JOIN product p ON(p.id_product = ps.fk_product) " +
+		"INNER JOIN location l ON(l.id_location = ps.fk_location)"
+)
+
+func NewLocationStockRepository(db *sql.DB) LocationStockRepository {
+	return &locationStockRepository{db: db}
+}
+
+type locationStockRepository struct {
+	db *sql.DB
+}
+
+func (r *locationStockRepository) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*LocationStock, error) {
+	stocks := map[string][]*LocationStock{}
+	if len(productUUIDs) == 0 {
+		return stocks, nil
+	}
+
+	args := make([]interface{}, len(productUUIDs))
+	for i, uuid := range productUUIDs {
+		args[i] = uuid
+	}
+	rows, err := r.db.QueryContext(
+		ctx,
+		selectLocationStockQuery+" WHERE p.uuid IN (?"+strings.Repeat(",?", len(productUUIDs)-1)+") ORDER BY l.name",
+		args...,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var productUUID string
+		stock := &LocationStock{}
+		if err := rows.Scan(&productUUID, &stock.LocationUUID, &stock.Name, &stock.Stock); err != nil {
+			return nil, err
+		}
+		stocks[productUUID] = append(stocks[productUUID], stock)
+	}
+
+	return stocks, rows.Err()
+}
+
+func (r *locationStockRepository) AdjustStock(ctx context.Context, productUUID string, locationUUID string, delta int, allowNegative bool, movement *StockMovement) (*LocationStock, *Product, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	productID, err := lockLocationProduct(ctx, tx, productUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	locationID, err := ensureLocationStock(ctx, tx, productID, locationUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := tx.ExecContext(
+		ctx,
+		"UPDATE product_stock SET stock = stock + ? WHERE fk_product = ? AND fk_location = ? AND (? OR stock + ? >= 0)",
+		delta, productID, locationID, allowNegative, delta,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stock, err := findLocationStock(ctx, tx, productID, locationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if affected == 0 {
+		return nil, nil, NewInsufficientStockError(locationUUID, stock.Stock, delta)
+	}
+
+	product, err := sumStock(ctx, tx, "product_stock", productID, productUUID, movement)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return stock, product, tx.Commit()
+}
+
+func (r *locationStockRepository) Move(ctx context.Context, productUUID string, move *StockMove) (*LocationStock, *LocationStock, *Product, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer tx.Rollback()
+
+	productID, err := lockLocationProduct(ctx, tx, productUUID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fromID, err := ensureLocationStock(ctx, tx, productID, move.From)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	toID, err := ensureLocationStock(ctx, tx, productID, move.To)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// A move never takes a location below zero, whether or not backorders are allowed.
+	result, err := tx.ExecContext(
+		ctx,
+		"UPDATE product_stock SET stock = stock - ? WHERE fk_product = ? AND fk_location = ? AND stock >= ?",
+		move.Quantity, productID, fromID, move.Quantity,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if affected == 0 {
+		from, err := findLocationStock(ctx, tx, productID, fromID)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return nil, nil, nil, NewInsufficientStockError(move.From, from.Stock, -move.Quantity)
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE product_stock SET stock = stock + ? WHERE fk_product = ? AND fk_location = ?",
+		move.Quantity, productID, toID,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	from, err := findLocationStock(ctx, tx, productID, fromID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	to, err := findLocationStock(ctx, tx, productID, toID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// A move keeps the total, so it has no movement of its own.
+	product, err := sumStock(ctx, tx, "product_stock", productID, productUUID, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return from, to, product, tx.Commit()
+}
+
+// lockLocationProduct locks the product like lockProduct, but refuses products
+// that are a component of a live bundle: bundles change the stock of their
+// components directly, which a stock split by location would not allow.
+func lockLocationProduct(ctx context.Context, tx *sql.Tx, uuid string) (int, error) {
+	productID, err := lockProduct(ctx, tx, uuid)
+	if err != nil {
+		return 0, err
+	}
+	bundleUUID, err := findParentBundle(ctx, tx, productID)
+	if err != nil {
+		return 0, err
+	}
+	if bundleUUID != "" {
+		return 0, NewBundleComponentError(uuid, bundleUUID, StockSourceLocation)
+	}
+	return productID, nil
+}
+
+// ensureLocationStock creates the stock row of the product at a location if there
+// is none yet and returns the id of the location. The first location of a product
+// takes over the stock the product had, so deriving the total from the locations
+// does not lose it; later locations start empty. The product row must be locked.
+func ensureLocationStock(ctx context.Context, tx *sql.Tx, productID int, locationUUID string) (int, error) {
+	var locationID int
+	err := tx.QueryRowContext(ctx, "SELECT id_location FROM location WHERE uuid = ?", locationUUID).Scan(&locationID)
+	if err == sql.ErrNoRows {
+		return 0, location.NewLocationNotFoundError(locationUUID)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO product_stock (fk_product, fk_location, stock) "+
+			"SELECT p.id_product, ?, IF(EXISTS(SELECT 1 FROM product_stock ps WHERE ps.fk_product = p.id_product), 0, p.stock) "+
+			"FROM product p WHERE p.id_product = ? ON DUPLICATE KEY UPDATE stock = product_stock.stock",
+		locationID, productID,
+	)
+	return locationID, err
+}
+
+func findLocationStock(ctx context.Context, tx *sql.Tx, productID int, locationID int) (*LocationStock, error) {
+	stock := &LocationStock{}
+	var productUUID string
+	err := tx.QueryRowContext(
+		ctx,
+		selectLocationStockQuery+" WHERE ps.fk_product = ? AND ps.fk_location = ?",
+		productID, locationID,
+	).Scan(&productUUID, &stock.LocationUUID, &stock.Name, &stock.Stock)
+	if err != nil {
+		return nil, err
+	}
+	return stock, nil
+}
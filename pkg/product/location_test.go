@@ -0,0 +1,185 @@
+package product
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/location"
+)
+
+func Test_StockMoveValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		move    *StockMove
+		wantErr error
+	}{
+		{
+			name: "test valid move",
+			move: &StockMove{From: "l1", To: "l2", Quantity: 2, Reason: "rebalance"},
+		},
+		{
+			name:    "test zero quantity",
+			move:    &StockMove{From: "l1", To: "l2", Reason: "rebalance"},
+			wantErr: NewInvalidStockMoveError("quantity must be positive"),
+		},
+		{
+			name:    "test same location",
+			move:    &StockMove{From: "l1", To: "l1", Quantity: 2, Reason: "rebalance"},
+			wantErr: NewInvalidStockMoveError("from and to must be different locations"),
+		},
+		{
+			name:    "test missing reason",
+			move:    &StockMove{From: "l1", To: "l2", Quantity: 2},
+			wantErr: NewInvalidStockMoveError("reason is required"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, tt.move.validate())
+		})
+	}
+}
+
+func Test_serviceLocations(t *testing.T) {
+	repo := &repositoryMock{products: map[string]*Product{
+		"p1": {UUID: "p1", Name: "Berlin New Shirt", Stock: 10, SellerUUID: "s1", Version: 1},
+	}}
+	locationRepo := &locationRepositoryMock{locations: []*location.Location{
+		{UUID: "l1", Name: "Berlin DC", SellerUUID: "s1"},
+		{UUID: "l2", Name: "Hamburg", SellerUUID: "s1"},
+		{UUID: "l3", Name: "Paris", SellerUUID: "s2"},
+	}}
+	stockRepo := &locationStockRepositoryMock{repo: repo, locations: locationRepo, stock: map[string]int{}}
+	noti := &notiProviderMock{}
+	svc := NewService(repo, &sellerRepositoryMock{}, noti, WithLocationRepository(locationRepo), WithLocationStockRepository(stockRepo))
+	ctx := context.Background()
+
+	// The first location takes over the stock the product had, and from then on
+	// the product stock is the sum across locations.
+	stock, err := svc.AdjustLocationStock(ctx, "p1", "l1", &StockAdjustment{Delta: 6, Reason: "inbound"})
+	assert.NoError(t, err)
+	assert.Equal(t, &LocationStock{LocationUUID: "l1", Name: "Berlin DC", Stock: 16}, stock)
+	assert.Equal(t, 16, repo.products["p1"].Stock)
+	assert.Equal(t, 1, noti.calls)
+	assert.Equal(t, "Berlin New Shirt (location: Berlin DC)", noti.lastProduct)
+	if assert.Len(t, repo.movements, 1) {
+		assert.Equal(t, 6, repo.movements[0].Delta)
+		assert.Equal(t, 16, repo.movements[0].StockAfter)
+		assert.Equal(t, "inbound", repo.movements[0].Reference)
+	}
+
+	_, err = svc.AdjustLocationStock(ctx, "p1", "l3", &StockAdjustment{Delta: 1, Reason: "inbound"})
+	assert.Equal(t, location.NewLocationNotFoundError("l3"), err)
+
+	locations, err := svc.MoveStock(ctx, "p1", &StockMove{From: "l1", To: "l2", Quantity: 4, Reason: "rebalance"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*LocationStock{
+		{LocationUUID: "l1", Name: "Berlin DC", Stock: 12},
+		{LocationUUID: "l2", Name: "Hamburg", Stock: 4},
+	}, locations)
+	assert.Equal(t, 16, repo.products["p1"].Stock)
+	assert.Len(t, repo.movements, 1)
+	assert.Equal(t, 3, noti.calls)
+	assert.Equal(t, "Berlin New Shirt (location: Hamburg)", noti.lastProduct)
+
+	_, err = svc.MoveStock(ctx, "p1", &StockMove{From: "l1", To: "l2", Quantity: 13, Reason: "rebalance"})
+	assert.Equal(t, NewInsufficientStockError("l1", 12, -13), err)
+
+	_, err = svc.AdjustStock(ctx, "p1", &StockAdjustment{Delta: 1, Reason: "recount"})
+	assert.Equal(t, NewDerivedStockError("p1", StockSourceLocation), err)
+
+	total := 20
+	_, err = svc.Patch(ctx, "p1", 0, &Patch{Stock: &total})
+	assert.Equal(t, NewDerivedStockError("p1", StockSourceLocation), err)
+
+	err = svc.CreateVariant(ctx, "p1", &Variant{SKU: "BNS-M", Options: map[string]string{"size": "M"}})
+	assert.Equal(t, NewDerivedStockError("p1", StockSourceLocation), err)
+}
+
+type locationRepositoryMock struct {
+	locations []*location.Location
+}
+
+func (m *locationRepositoryMock) FindByUUID(ctx context.Context, uuid string) (*location.Location, error) {
+	for _, l := range m.locations {
+		if l.UUID == uuid {
+			cp := *l
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+// locationStockRepositoryMock keeps the stock of product p1 per location UUID. Like
+// the real repository, the first location takes over the stock of the product.
+type locationStockRepositoryMock struct {
+	repo      *repositoryMock
+	locations *locationRepositoryMock
+	stock     map[string]int
+}
+
+func (m *locationStockRepositoryMock) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*LocationStock, error) {
+	stocks := map[string][]*LocationStock{}
+	for _, uuid := range productUUIDs {
+		if uuid != "p1" {
+			continue
+		}
+		for _, l := range m.locations.locations {
+			if stock, ok := m.stock[l.UUID]; ok {
+				stocks[uuid] = append(stocks[uuid], &LocationStock{LocationUUID: l.UUID, Name: l.Name, Stock: stock})
+			}
+		}
+	}
+	return stocks, nil
+}
+
+func (m *locationStockRepositoryMock) AdjustStock(ctx context.Context, productUUID string, locationUUID string, delta int, allowNegative bool, movement *StockMovement) (*LocationStock, *Product, error) {
+	m.ensure(productUUID, locationUUID)
+	if !allowNegative && m.stock[locationUUID]+delta < 0 {
+		return nil, nil, NewInsufficientStockError(locationUUID, m.stock[locationUUID], delta)
+	}
+	m.stock[locationUUID] += delta
+	return m.find(locationUUID), m.sumStock(productUUID, movement), nil
+}
+
+func (m *locationStockRepositoryMock) Move(ctx context.Context, productUUID string, move *StockMove) (*LocationStock, *LocationStock, *Product, error) {
+	m.ensure(productUUID, move.From)
+	m.ensure(productUUID, move.To)
+	if m.stock[move.From] < move.Quantity {
+		return nil, nil, nil, NewInsufficientStockError(move.From, m.stock[move.From], -move.Quantity)
+	}
+	m.stock[move.From] -= move.Quantity
+	m.stock[move.To] += move.Quantity
+	return m.find(move.From), m.find(move.To), m.sumStock(productUUID, nil), nil
+}
+
+func (m *locationStockRepositoryMock) ensure(productUUID string, locationUUID string) {
+	if _, ok := m.stock[locationUUID]; ok {
+		return
+	}
+	if len(m.stock) == 0 {
+		m.stock[locationUUID] = m.repo.products[productUUID].Stock
+		return
+	}
+	m.stock[locationUUID] = 0
+}
+
+func (m *locationStockRepositoryMock) find(locationUUID string) *LocationStock {
+	l, _ := m.locations.FindByUUID(context.Background(), locationUUID)
+	return &LocationStock{LocationUUID: l.UUID, Name: l.Name, Stock: m.stock[locationUUID]}
+}
+
+func (m *locationStockRepositoryMock) sumStock(productUUID string, movement *StockMovement) *Product {
+	p := m.repo.products[productUUID]
+	oldStock := p.Stock
+	p.Stock = 0
+	for _, stock := range m.stock {
+		p.Stock += stock
+	}
+	p.Version++
+	m.repo.writeStockMovement(movement, p, p.Stock-oldStock)
+	cp := *p
+	return &cp
+}
@@ -0,0 +1,134 @@
+package product
+
+import "coding-challenge-go/pkg/storage"
+
+// Option configures optional behaviour of the product service.
+type Option func(s *service)
+
+// WithBackorders lets stock adjustments take stock below zero.
+func WithBackorders(allowed bool) Option {
+	return func(s *service) {
+		s.allowBackorders = allowed
+	}
+}
+
+// WithPriceHistoryRepository sets where price changes are recorded.
+func WithPriceHistoryRepository(repo PriceHistoryRepository) Option {
+	return func(s *service) {
+		s.priceHistoryRepo = repo
+	}
+}
+
+// WithPriceAlertRepository sets where subscriptions to price drops are stored.
+func WithPriceAlertRepository(repo PriceAlertRepository) Option {
+	return func(s *service) {
+		s.priceAlertRepo = repo
+	}
+}
+
+// WithStockMovementRepository sets where the stock ledger is kept.
+func WithStockMovementRepository(repo StockMovementRepository) Option {
+	return func(s *service) {
+		s.stockMovementRepo = repo
+	}
+}
+
+// WithPriceDropAlert notifies the subscribers of a product when its price falls
+// by at least percent. Zero disables the alert.
+func WithPriceDropAlert(percent float64) Option {
+	return func(s *service) {
+		s.priceDropAlertPercent = percent
+	}
+}
+
+// WithVariantRepository sets where product variants are stored.
+func WithVariantRepository(repo VariantRepository) Option {
+	return func(s *service) {
+		s.variantRepo = repo
+	}
+}
+
+// WithCategoryRepository sets where product categories are stored.
+func WithCategoryRepository(repo CategoryRepository) Option {
+	return func(s *service) {
+		s.categoryRepo = repo
+	}
+}
+
+// WithLocationRepository sets where the locations stock is held at are looked up.
+func WithLocationRepository(repo LocationRepository) Option {
+	return func(s *service) {
+		s.locationRepo = repo
+	}
+}
+
+// WithLocationStockRepository sets where the stock per location is stored.
+func WithLocationStockRepository(repo LocationStockRepository) Option {
+	return func(s *service) {
+		s.locationStockRepo = repo
+	}
+}
+
+// WithBrandResolver sets how the brand sent with a product is matched to a brand.
+func WithBrandResolver(resolver BrandResolver) Option {
+	return func(s *service) {
+		s.brandResolver = resolver
+	}
+}
+
+// WithAuditRepository sets where product changes are recorded.
+func WithAuditRepository(repo AuditRepository) Option {
+	return func(s *service) {
+		s.auditRepo = repo
+	}
+}
+
+// WithImageRepository sets where image metadata is stored.
+func WithImageRepository(repo ImageRepository) Option {
+	return func(s *service) {
+		s.imageRepo = repo
+	}
+}
+
+// WithImageStorage sets where image files and their thumbnails are stored.
+// Without it uploads are rejected.
+func WithImageStorage(store storage.Storage) Option {
+	return func(s *service) {
+		s.imageStorage = store
+	}
+}
+
+// WithDuplicateCheck makes Create look for a product of the same seller whose
+// name and brand match after normalizing, or are at least threshold (0 to 1)
+// similar. Mode decides whether a match is rejected or only reported. The
+// threshold also applies to the duplicates report; zero keeps the default.
+func WithDuplicateCheck(mode DuplicateMode, threshold float64) Option {
+	return func(s *service) {
+		s.duplicateMode = mode
+		if threshold > 0 {
+			s.duplicateThreshold = threshold
+		}
+	}
+}
+
+// WithMaxImageSize limits the size in bytes of an uploaded image.
+func WithMaxImageSize(bytes int64) Option {
+	return func(s *service) {
+		s.maxImageSize = bytes
+	}
+}
+
+// WithBundleRepository sets where the components of bundles are stored.
+func WithBundleRepository(repo BundleRepository) Option {
+	return func(s *service) {
+		s.bundleRepo = repo
+	}
+}
+
+// WithLowStockAlert makes stock changes that bring a product, or a bundle built
+// from it, to threshold or below alert its seller. Zero disables the alert.
+func WithLowStockAlert(threshold int) Option {
+	return func(s *service) {
+		s.lowStockThreshold = threshold
+	}
+}
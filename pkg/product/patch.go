@@ -0,0 +1,233 @@
+package product
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"coding-challenge-go/pkg/money"
+)
+
+// removableFields are the members that may be null in a patch.
+var removableFields = map[string]bool{"price": true, "attributes": true, "sku": true, "gtin": true}
+
+type (
+	// Patch is a JSON Merge Patch (RFC 7396) of a product. A nil field was
+	// absent from the patch document and is left unchanged. A null SKU or GTIN
+	// is patched as "", which removes it.
+	Patch struct {
+		Name       *string
+		Brand      *string
+		Stock      *int
+		SKU        *string
+		GTIN       *string
+		Price      *PricePatch
+		Attributes *AttributesPatch
+	}
+
+	// PricePatch is merged into the current price, so {"price":{"amount":999}}
+	// keeps the currency. Remove is set by {"price":null}.
+	PricePatch struct {
+		Remove   bool
+		Amount   *int64
+		Currency *string
+	}
+
+	// AttributesPatch is merged into the current attributes: {"attributes":{"color":null}}
+	// removes one attribute and keeps the rest. Remove is set by {"attributes":null}.
+	AttributesPatch struct {
+		Remove bool
+		Set    Attributes
+		Delete []string
+	}
+)
+
+// ParseMergePatch decodes a JSON Merge Patch document. Only the price, the
+// attributes and the identifiers can be removed, so any other null member is
+// rejected, as is any member that is not patchable.
+func ParseMergePatch(data []byte) (*Patch, error) {
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, NewInvalidPatchError("", "patch must be a JSON object")
+	}
+
+	patch := &Patch{}
+	for field, raw := range members {
+		if isNull(raw) && !removableFields[field] {
+			return nil, NewInvalidPatchError(field, "field cannot be removed")
+		}
+
+		var err error
+		switch field {
+		case "name":
+			patch.Name = new(string)
+			err = json.Unmarshal(raw, patch.Name)
+		case "brand":
+			patch.Brand = new(string)
+			err = json.Unmarshal(raw, patch.Brand)
+		case "stock":
+			patch.Stock = new(int)
+			err = json.Unmarshal(raw, patch.Stock)
+		case "sku":
+			patch.SKU = new(string)
+			err = unmarshalRemovable(raw, patch.SKU)
+		case "gtin":
+			patch.GTIN = new(string)
+			err = unmarshalRemovable(raw, patch.GTIN)
+		case "price":
+			if patch.Price, err = parsePricePatch(raw); err != nil {
+				return nil, err
+			}
+		case "attributes":
+			if patch.Attributes, err = parseAttributesPatch(raw); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, NewInvalidPatchError(field, "field cannot be patched")
+		}
+		if err != nil {
+			return nil, NewInvalidPatchError(field, "invalid value")
+		}
+	}
+
+	return patch, nil
+}
+
+// Apply changes the fields of product that are present in the patch.
+func (p *Patch) Apply(product *Product) {
+	if p.Name != nil {
+		product.Name = *p.Name
+	}
+	if p.Brand != nil {
+		product.Brand = *p.Brand
+	}
+	if p.Stock != nil {
+		product.Stock = *p.Stock
+	}
+	if p.SKU != nil {
+		product.SKU = *p.SKU
+	}
+	if p.GTIN != nil {
+		product.GTIN = *p.GTIN
+	}
+	if p.Price != nil {
+		p.Price.apply(product)
+	}
+	if p.Attributes != nil {
+		p.Attributes.apply(product)
+	}
+}
+
+func parsePricePatch(raw json.RawMessage) (*PricePatch, error) {
+	if isNull(raw) {
+		return &PricePatch{Remove: true}, nil
+	}
+
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, NewInvalidPatchError("price", "invalid value")
+	}
+
+	patch := &PricePatch{}
+	for field, value := range members {
+		if isNull(value) {
+			return nil, NewInvalidPatchError("price."+field, "field cannot be removed")
+		}
+
+		var err error
+		switch field {
+		case "amount":
+			patch.Amount = new(int64)
+			err = json.Unmarshal(value, patch.Amount)
+		case "currency":
+			patch.Currency = new(string)
+			err = json.Unmarshal(value, patch.Currency)
+		default:
+			return nil, NewInvalidPatchError("price."+field, "field cannot be patched")
+		}
+		if err != nil {
+			return nil, NewInvalidPatchError("price."+field, "invalid value")
+		}
+	}
+
+	return patch, nil
+}
+
+func (p *PricePatch) apply(product *Product) {
+	if p.Remove {
+		product.Price = nil
+		return
+	}
+
+	price := money.Money{}
+	if product.Price != nil {
+		price = *product.Price
+	}
+	if p.Amount != nil {
+		price.Amount = *p.Amount
+	}
+	if p.Currency != nil {
+		price.Currency = *p.Currency
+	}
+	product.Price = &price
+}
+
+func parseAttributesPatch(raw json.RawMessage) (*AttributesPatch, error) {
+	if isNull(raw) {
+		return &AttributesPatch{Remove: true}, nil
+	}
+
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, NewInvalidPatchError("attributes", "invalid value")
+	}
+
+	patch := &AttributesPatch{Set: Attributes{}}
+	for name, value := range members {
+		if isNull(value) {
+			patch.Delete = append(patch.Delete, name)
+			continue
+		}
+		var v interface{}
+		if err := json.Unmarshal(value, &v); err != nil {
+			return nil, NewInvalidPatchError("attributes."+name, "invalid value")
+		}
+		patch.Set[name] = v
+	}
+
+	return patch, nil
+}
+
+// apply works on a copy, so a product copied before the patch keeps its attributes.
+func (p *AttributesPatch) apply(product *Product) {
+	if p.Remove {
+		product.Attributes = nil
+		return
+	}
+
+	attributes := product.Attributes.clone()
+	if attributes == nil {
+		attributes = Attributes{}
+	}
+	for name, value := range p.Set {
+		attributes[name] = value
+	}
+	for _, name := range p.Delete {
+		delete(attributes, name)
+	}
+	if len(attributes) == 0 {
+		attributes = nil
+	}
+	product.Attributes = attributes
+}
+
+// unmarshalRemovable decodes a string member, leaving s empty for null.
+func unmarshalRemovable(raw json.RawMessage, s *string) error {
+	if isNull(raw) {
+		return nil
+	}
+	return json.Unmarshal(raw, s)
+}
+
+func isNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
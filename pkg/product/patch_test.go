@@ -0,0 +1,146 @@
+package product
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/money"
+)
+
+func Test_ParseMergePatch(t *testing.T) {
+	name := "Berlin New Shirt"
+	stock := 0
+	amount := int64(1999)
+
+	tests := []struct {
+		name    string
+		data    string
+		want    *Patch
+		wantErr error
+	}{
+		{
+			name: "test patch name only",
+			data: `{"name":"Berlin New Shirt"}`,
+			want: &Patch{Name: &name},
+		},
+		{
+			name: "test patch zero stock is kept",
+			data: `{"stock":0}`,
+			want: &Patch{Stock: &stock},
+		},
+		{
+			name: "test empty patch",
+			data: `{}`,
+			want: &Patch{},
+		},
+		{
+			name:    "test patch null removes field",
+			data:    `{"brand":null}`,
+			wantErr: NewInvalidPatchError("brand", "field cannot be removed"),
+		},
+		{
+			name: "test patch price amount only",
+			data: `{"price":{"amount":1999}}`,
+			want: &Patch{Price: &PricePatch{Amount: &amount}},
+		},
+		{
+			name: "test patch null removes price",
+			data: `{"price":null}`,
+			want: &Patch{Price: &PricePatch{Remove: true}},
+		},
+		{
+			name:    "test patch unknown price field",
+			data:    `{"price":{"value":1999}}`,
+			wantErr: NewInvalidPatchError("price.value", "field cannot be patched"),
+		},
+		{
+			name:    "test patch null price currency",
+			data:    `{"price":{"currency":null}}`,
+			wantErr: NewInvalidPatchError("price.currency", "field cannot be removed"),
+		},
+		{
+			name: "test patch attributes merges and removes",
+			data: `{"attributes":{"material":"linen","color":null}}`,
+			want: &Patch{Attributes: &AttributesPatch{Set: Attributes{"material": "linen"}, Delete: []string{"color"}}},
+		},
+		{
+			name: "test patch null removes attributes",
+			data: `{"attributes":null}`,
+			want: &Patch{Attributes: &AttributesPatch{Remove: true}},
+		},
+		{
+			name:    "test patch unknown field",
+			data:    `{"seller_uuid":"123"}`,
+			wantErr: NewInvalidPatchError("seller_uuid", "field cannot be patched"),
+		},
+		{
+			name:    "test patch wrong type",
+			data:    `{"stock":"ten"}`,
+			wantErr: NewInvalidPatchError("stock", "invalid value"),
+		},
+		{
+			name:    "test patch is not an object",
+			data:    `[1]`,
+			wantErr: NewInvalidPatchError("", "patch must be a JSON object"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMergePatch([]byte(tt.data))
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_PatchApply(t *testing.T) {
+	brand := "Shirts Inc."
+	p := &Product{UUID: "123", Name: "Berlin New Shirt", Brand: "ShirtsCo", Stock: 10}
+
+	(&Patch{Brand: &brand}).Apply(p)
+
+	assert.Equal(t, &Product{UUID: "123", Name: "Berlin New Shirt", Brand: "Shirts Inc.", Stock: 10}, p)
+}
+
+func Test_PricePatchApply(t *testing.T) {
+	amount := int64(999)
+	currency := "USD"
+
+	tests := []struct {
+		name  string
+		patch *PricePatch
+		price *money.Money
+		want  *money.Money
+	}{
+		{
+			name:  "test amount keeps currency",
+			patch: &PricePatch{Amount: &amount},
+			price: &money.Money{Amount: 1999, Currency: "EUR"},
+			want:  &money.Money{Amount: 999, Currency: "EUR"},
+		},
+		{
+			name:  "test currency keeps amount",
+			patch: &PricePatch{Currency: &currency},
+			price: &money.Money{Amount: 1999, Currency: "EUR"},
+			want:  &money.Money{Amount: 1999, Currency: "USD"},
+		},
+		{
+			name:  "test price added to unpriced product",
+			patch: &PricePatch{Amount: &amount, Currency: &currency},
+			want:  &money.Money{Amount: 999, Currency: "USD"},
+		},
+		{
+			name:  "test remove price",
+			patch: &PricePatch{Remove: true},
+			price: &money.Money{Amount: 1999, Currency: "EUR"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Product{UUID: "123", Price: tt.price}
+			(&Patch{Price: tt.patch}).Apply(p)
+			assert.Equal(t, tt.want, p.Price)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/money"
+	"coding-challenge-go/pkg/seller"
+)
+
+const (
+	maxPriceAlertEmailLength = 255
+	maxPriceAlertPhoneLength = 32
+)
+
+type (
+	// PriceAlert subscribes an email address or phone number to price drops of a
+	// product. Each notification provider delivers to the contact it supports.
+	PriceAlert struct {
+		PriceAlertID int       `json:"-"`
+		UUID         string    `json:"uuid"`
+		ProductUUID  string    `json:"product_uuid"`
+		Email        string    `json:"email,omitempty"`
+		Phone        string    `json:"phone,omitempty"`
+		CreatedAt    time.Time `json:"created_at"`
+	}
+
+	PriceAlertRepository interface {
+		// ListByProduct returns the subscriptions of a product, oldest first.
+		ListByProduct(ctx context.Context, productUUID string) ([]*PriceAlert, error)
+		Create(ctx context.Context, alert *PriceAlert) error
+		// Delete removes a subscription of a product and reports whether it existed.
+		Delete(ctx context.Context, productUUID string, uuid string) (bool, error)
+	}
+
+	// nopPriceAlertRepository is used when the service is built without a price alert repository.
+	nopPriceAlertRepository struct{}
+)
+
+func (a *PriceAlert) validate() error {
+	a.Email, a.Phone = strings.TrimSpace(a.Email), strings.TrimSpace(a.Phone)
+	if a.Email == "" && a.Phone == "" {
+		return NewInvalidPriceAlertError("email or phone is required")
+	}
+	if len(a.Email) > maxPriceAlertEmailLength {
+		return NewInvalidPriceAlertError("email is too long")
+	}
+	if a.Email != "" && !strings.Contains(a.Email, "@") {
+		return NewInvalidPriceAlertError(fmt.Sprintf("%q is not an email address", a.Email))
+	}
+	if len(a.Phone) > maxPriceAlertPhoneLength {
+		return NewInvalidPriceAlertError("phone is too long")
+	}
+	return nil
+}
+
+func (s *service) PriceAlerts(ctx context.Context, uuid string) ([]*PriceAlert, error) {
+	if _, err := s.FindByUUID(ctx, uuid); err != nil {
+		return nil, err
+	}
+	return s.priceAlertRepo.ListByProduct(ctx, uuid)
+}
+
+func (s *service) SubscribePriceAlert(ctx context.Context, productUUID string, alert *PriceAlert) error {
+	if err := alert.validate(); err != nil {
+		return err
+	}
+	if _, err := s.FindByUUID(ctx, productUUID); err != nil {
+		return err
+	}
+	alert.UUID = uuid.New().String()
+	alert.ProductUUID = productUUID
+	alert.CreatedAt = time.Now().UTC()
+	return s.priceAlertRepo.Create(ctx, alert)
+}
+
+func (s *service) UnsubscribePriceAlert(ctx context.Context, productUUID string, uuid string) error {
+	deleted, err := s.priceAlertRepo.Delete(ctx, productUUID, uuid)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return NewPriceAlertNotFoundError(productUUID, uuid)
+	}
+	return nil
+}
+
+// alertPriceDrop sends a price drop of product to its subscribers. The price is
+// already written, so failures are only logged.
+func (s *service) alertPriceDrop(ctx context.Context, oldPrice money.Money, product *Product) {
+	alerts, err := s.priceAlertRepo.ListByProduct(ctx, product.UUID)
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to find price alert subscribers of product %s", product.UUID))
+		return
+	}
+	if len(alerts) == 0 {
+		return
+	}
+	subscribers := make([]*seller.Subscriber, len(alerts))
+	for i, alert := range alerts {
+		subscribers[i] = &seller.Subscriber{UUID: alert.UUID, Email: alert.Email, Phone: alert.Phone}
+	}
+	s.notiProvider.PriceDropped(oldPrice, *product.Price, product.Name, subscribers)
+}
+
+func (nopPriceAlertRepository) ListByProduct(ctx context.Context, productUUID string) ([]*PriceAlert, error) {
+	return []*PriceAlert{}, nil
+}
+
+func (nopPriceAlertRepository) Create(ctx context.Context, alert *PriceAlert) error {
+	return nil
+}
+
+func (nopPriceAlertRepository) Delete(ctx context.Context, productUUID string, uuid string) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,78 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+)
+
+func NewPriceAlertRepository(db *sql.DB) PriceAlertRepository {
+	return &priceAlertRepository{db: db}
+}
+
+type priceAlertRepository struct {
+	db *sql.DB
+}
+
+func (r *priceAlertRepository) ListByProduct(ctx context.Context, productUUID string) ([]*PriceAlert, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT a.id_price_alert, a.uuid, p.uuid, a.email, a.phone, a.created_at FROM price_alert a "+
+			"INNER JOIN product p ON(p.id_product = a.fk_product) WHERE p.uuid = ? ORDER BY a.id_price_alert",
+		productUUID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	alerts := []*PriceAlert{}
+
+	for rows.Next() {
+		alert := &PriceAlert{}
+		err := rows.Scan(&alert.PriceAlertID, &alert.UUID, &alert.ProductUUID, &alert.Email, &alert.Phone, &alert.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, rows.Err()
+}
+
+func (r *priceAlertRepository) Create(ctx context.Context, alert *PriceAlert) error {
+	result, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO price_alert (uuid, fk_product, email, phone, created_at) "+
+			"SELECT ?, id_product, ?, ?, ? FROM product WHERE uuid = ? AND deleted_at IS NULL",
+		alert.UUID, alert.Email, alert.Phone, alert.CreatedAt, alert.ProductUUID,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return NewProductNotFoundError(alert.ProductUUID)
+	}
+	return nil
+}
+
+func (r *priceAlertRepository) Delete(ctx context.Context, productUUID string, uuid string) (bool, error) {
+	result, err := r.db.ExecContext(
+		ctx,
+		"DELETE a FROM price_alert a INNER JOIN product p ON(p.id_product = a.fk_product) WHERE p.uuid = ? AND a.uuid = ?",
+		productUUID, uuid,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
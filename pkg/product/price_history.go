@@ -0,0 +1,57 @@
+package product
+
+import (
+	"context"
+	"time"
+
+	"coding-challenge-go/pkg/money"
+)
+
+type (
+	// PriceChange records a product's price from ChangedAt on. A nil Price means
+	// the price was removed.
+	PriceChange struct {
+		PriceChangeID int          `json:"-"`
+		ProductUUID   string       `json:"product_uuid"`
+		Price         *money.Money `json:"price"`
+		ChangedAt     time.Time    `json:"changed_at"`
+	}
+
+	PriceHistoryRepository interface {
+		Create(ctx context.Context, change *PriceChange) error
+		// ListByProduct returns the price changes of a product, newest first.
+		ListByProduct(ctx context.Context, productUUID string, offset int, limit int) ([]*PriceChange, error)
+	}
+
+	// nopPriceHistoryRepository is used when the service is built without a price history repository.
+	nopPriceHistoryRepository struct{}
+)
+
+// priceEqual reports whether a and b are the same price, treating two missing prices as equal.
+func priceEqual(a *money.Money, b *money.Money) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// isPriceDrop reports whether newPrice is at least percent lower than oldPrice.
+// Prices in different currencies are not compared, and a zero percent disables alerts.
+func isPriceDrop(oldPrice *money.Money, newPrice *money.Money, percent float64) bool {
+	if percent <= 0 || oldPrice == nil || newPrice == nil {
+		return false
+	}
+	if oldPrice.Currency != newPrice.Currency || oldPrice.Amount <= 0 || newPrice.Amount >= oldPrice.Amount {
+		return false
+	}
+	drop := float64(oldPrice.Amount-newPrice.Amount) * 100 / float64(oldPrice.Amount)
+	return drop >= percent
+}
+
+func (nopPriceHistoryRepository) Create(ctx context.Context, change *PriceChange) error {
+	return nil
+}
+
+func (nopPriceHistoryRepository) ListByProduct(ctx context.Context, productUUID string, offset int, limit int) ([]*PriceChange, error) {
+	return []*PriceChange{}, nil
+}
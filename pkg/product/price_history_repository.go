@@ -0,0 +1,60 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+
+	"coding-challenge-go/pkg/money"
+)
+
+func NewPriceHistoryRepository(db *sql.DB) PriceHistoryRepository {
+	return &priceHistoryRepository{db: db}
+}
+
+type priceHistoryRepository struct {
+	db *sql.DB
+}
+
+func (r *priceHistoryRepository) Create(ctx context.Context, change *PriceChange) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO product_price_history (product_uuid, price_amount, price_currency, changed_at) VALUES(?,?,?,?)",
+		change.ProductUUID, priceAmount(change.Price), priceCurrency(change.Price), change.ChangedAt,
+	)
+
+	return err
+}
+
+func (r *priceHistoryRepository) ListByProduct(ctx context.Context, productUUID string, offset int, limit int) ([]*PriceChange, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT id_product_price_history, product_uuid, price_amount, price_currency, changed_at FROM product_price_history "+
+			"WHERE product_uuid = ? ORDER BY changed_at DESC, id_product_price_history DESC LIMIT ? OFFSET ?",
+		productUUID, limit, offset,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	changes := []*PriceChange{}
+
+	for rows.Next() {
+		var amount sql.NullInt64
+		var currency sql.NullString
+		change := &PriceChange{}
+
+		err := rows.Scan(&change.PriceChangeID, &change.ProductUUID, &amount, &currency, &change.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		if amount.Valid && currency.Valid {
+			change.Price = &money.Money{Amount: amount.Int64, Currency: currency.String}
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
@@ -0,0 +1,209 @@
+package product
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/money"
+	"coding-challenge-go/pkg/seller"
+)
+
+func Test_isPriceDrop(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldPrice *money.Money
+		newPrice *money.Money
+		percent  float64
+		want     bool
+	}{
+		{
+			name:     "test drop beyond threshold",
+			oldPrice: &money.Money{Amount: 2000, Currency: "EUR"},
+			newPrice: &money.Money{Amount: 1500, Currency: "EUR"},
+			percent:  10,
+			want:     true,
+		},
+		{
+			name:     "test drop at threshold",
+			oldPrice: &money.Money{Amount: 2000, Currency: "EUR"},
+			newPrice: &money.Money{Amount: 1800, Currency: "EUR"},
+			percent:  10,
+			want:     true,
+		},
+		{
+			name:     "test drop below threshold",
+			oldPrice: &money.Money{Amount: 2000, Currency: "EUR"},
+			newPrice: &money.Money{Amount: 1900, Currency: "EUR"},
+			percent:  10,
+		},
+		{
+			name:     "test price increase",
+			oldPrice: &money.Money{Amount: 2000, Currency: "EUR"},
+			newPrice: &money.Money{Amount: 2500, Currency: "EUR"},
+			percent:  10,
+		},
+		{
+			name:     "test currency change is not a drop",
+			oldPrice: &money.Money{Amount: 2000, Currency: "EUR"},
+			newPrice: &money.Money{Amount: 100, Currency: "JPY"},
+			percent:  10,
+		},
+		{
+			name:     "test new price",
+			newPrice: &money.Money{Amount: 100, Currency: "EUR"},
+			percent:  10,
+		},
+		{
+			name:     "test alert disabled",
+			oldPrice: &money.Money{Amount: 2000, Currency: "EUR"},
+			newPrice: &money.Money{Amount: 100, Currency: "EUR"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isPriceDrop(tt.oldPrice, tt.newPrice, tt.percent))
+		})
+	}
+}
+
+func Test_serviceRecordsPriceChanges(t *testing.T) {
+	repo := &repositoryMock{products: map[string]*Product{}}
+	historyRepo := &priceHistoryRepositoryMock{}
+	noti := &notiProviderMock{}
+	svc := NewService(repo, &sellerRepositoryMock{}, noti, WithPriceHistoryRepository(historyRepo),
+		WithPriceAlertRepository(&priceAlertRepositoryMock{}), WithPriceDropAlert(10))
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, &Product{UUID: "p1", Name: "Plano Tee", Brand: "Plano", SellerUUID: "s1", Price: &money.Money{Amount: 2000, Currency: "EUR"}})
+	assert.NoError(t, err)
+	alert := &PriceAlert{Email: "shopper@example.com"}
+	assert.NoError(t, svc.SubscribePriceAlert(ctx, "p1", alert))
+
+	name := "Plano Tee v2"
+	_, err = svc.Patch(ctx, "p1", 0, &Patch{Name: &name})
+	assert.NoError(t, err)
+
+	amount := int64(1900)
+	_, err = svc.Patch(ctx, "p1", 0, &Patch{Price: &PricePatch{Amount: &amount}})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, noti.priceDrops)
+
+	assert.NoError(t, svc.Update(ctx, &Product{UUID: "p1", Name: name, Brand: "Plano", Price: &money.Money{Amount: 1000, Currency: "EUR"}}))
+	assert.Equal(t, 1, noti.priceDrops)
+	assert.Equal(t, []*seller.Subscriber{{UUID: alert.UUID, Email: "shopper@example.com"}}, noti.subscribers)
+
+	// Without subscribers a drop is recorded but nobody is told.
+	assert.NoError(t, svc.UnsubscribePriceAlert(ctx, "p1", alert.UUID))
+	assert.NoError(t, svc.Update(ctx, &Product{UUID: "p1", Name: name, Brand: "Plano", Price: &money.Money{Amount: 500, Currency: "EUR"}}))
+	assert.Equal(t, 1, noti.priceDrops)
+
+	_, err = svc.Patch(ctx, "p1", 0, &Patch{Price: &PricePatch{Remove: true}})
+	assert.NoError(t, err)
+
+	changes, err := svc.PriceHistory(ctx, "p1", 1)
+	assert.NoError(t, err)
+	prices := []*money.Money{}
+	for _, change := range changes {
+		prices = append(prices, change.Price)
+	}
+	assert.Equal(t, []*money.Money{
+		nil,
+		{Amount: 500, Currency: "EUR"},
+		{Amount: 1000, Currency: "EUR"},
+		{Amount: 1900, Currency: "EUR"},
+		{Amount: 2000, Currency: "EUR"},
+	}, prices)
+
+	_, err = svc.PriceHistory(ctx, "p2", 1)
+	assert.Equal(t, NewProductNotFoundError("p2"), err)
+}
+
+type priceHistoryRepositoryMock struct {
+	changes []*PriceChange
+}
+
+func (m *priceHistoryRepositoryMock) Create(ctx context.Context, change *PriceChange) error {
+	m.changes = append(m.changes, change)
+	return nil
+}
+
+func (m *priceHistoryRepositoryMock) ListByProduct(ctx context.Context, productUUID string, offset int, limit int) ([]*PriceChange, error) {
+	changes := []*PriceChange{}
+	for i := len(m.changes) - 1; i >= 0; i-- {
+		if m.changes[i].ProductUUID == productUUID {
+			changes = append(changes, m.changes[i])
+		}
+	}
+	return changes, nil
+}
+
+func Test_serviceSubscribePriceAlert(t *testing.T) {
+	repo := &repositoryMock{products: map[string]*Product{
+		"p1": {UUID: "p1", Name: "Plano Tee", SellerUUID: "s1", Version: 1},
+	}}
+	alertRepo := &priceAlertRepositoryMock{}
+	svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{}, WithPriceAlertRepository(alertRepo))
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		alert   *PriceAlert
+		wantErr error
+	}{
+		{name: "test email", alert: &PriceAlert{Email: " shopper@example.com "}},
+		{name: "test phone", alert: &PriceAlert{Phone: "+4915112345678"}},
+		{name: "test no contact", alert: &PriceAlert{}, wantErr: NewInvalidPriceAlertError("email or phone is required")},
+		{name: "test not an email", alert: &PriceAlert{Email: "shopper"}, wantErr: NewInvalidPriceAlertError(`"shopper" is not an email address`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.SubscribePriceAlert(ctx, "p1", tt.alert)
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr == nil {
+				assert.NotEmpty(t, tt.alert.UUID)
+				assert.Equal(t, "p1", tt.alert.ProductUUID)
+			}
+		})
+	}
+
+	alerts, err := svc.PriceAlerts(ctx, "p1")
+	assert.NoError(t, err)
+	assert.Len(t, alerts, 2)
+	assert.Equal(t, "shopper@example.com", alerts[0].Email)
+
+	assert.Equal(t, NewProductNotFoundError("p2"), svc.SubscribePriceAlert(ctx, "p2", &PriceAlert{Email: "shopper@example.com"}))
+	_, err = svc.PriceAlerts(ctx, "p2")
+	assert.Equal(t, NewProductNotFoundError("p2"), err)
+	assert.Equal(t, NewPriceAlertNotFoundError("p1", "a9"), svc.UnsubscribePriceAlert(ctx, "p1", "a9"))
+}
+
+type priceAlertRepositoryMock struct {
+	alerts []*PriceAlert
+}
+
+func (m *priceAlertRepositoryMock) ListByProduct(ctx context.Context, productUUID string) ([]*PriceAlert, error) {
+	alerts := []*PriceAlert{}
+	for _, alert := range m.alerts {
+		if alert.ProductUUID == productUUID {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts, nil
+}
+
+func (m *priceAlertRepositoryMock) Create(ctx context.Context, alert *PriceAlert) error {
+	m.alerts = append(m.alerts, alert)
+	return nil
+}
+
+func (m *priceAlertRepositoryMock) Delete(ctx context.Context, productUUID string, uuid string) (bool, error) {
+	for i, alert := range m.alerts {
+		if alert.ProductUUID == productUUID && alert.UUID == uuid {
+			m.alerts = append(m.alerts[:i], m.alerts[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
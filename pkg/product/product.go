@@ -1,10 +1,27 @@
 package product
 
+import (
+	"time"
+
+	"coding-challenge-go/pkg/money"
+)
+
 type Product struct {
-	ProductID  int    `json:"-"`
-	UUID       string `json:"uuid"`
-	Name       string `json:"name"`
-	Brand      string `json:"brand"`
-	Stock      int    `json:"stock"`
-	SellerUUID string `json:"seller_uuid"`
+	ProductID      int          `json:"-"`
+	UUID           string       `json:"uuid"`
+	Name           string       `json:"name"`
+	Brand          string       `json:"brand"`
+	BrandUUID      string       `json:"-"` // empty while the brand string is not linked to a brand
+	Stock          int          `json:"stock"`
+	AvailableStock int          `json:"available_stock"` // Stock minus the units held by active reservations
+	SellerUUID     string       `json:"seller_uuid"`
+	SKU            string       `json:"sku,omitempty"`  // unique among the products of the seller
+	GTIN           string       `json:"gtin,omitempty"` // EAN-8, UPC-A, EAN-13 or GTIN-14, unique across sellers
+	Price          *money.Money `json:"price"`
+	Attributes     Attributes   `json:"attributes,omitempty"`
+	Version        int          `json:"-"`                    // incremented on every write and exposed as the ETag
+	DeletedAt      *time.Time   `json:"deleted_at,omitempty"` // set while the product is in the trash
+	Status         Status       `json:"status,omitempty"`
+	PublishedAt    *time.Time   `json:"published_at,omitempty"` // when the product was last published
+	ArchivedAt     *time.Time   `json:"archived_at,omitempty"`  // set while the product is archived
 }
@@ -0,0 +1,26 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/periodic"
+)
+
+// Purge permanently removes products that have been in the trash for longer than
+// retention, every interval until ctx is done.
+func Purge(ctx context.Context, svc Service, interval time.Duration, retention time.Duration) {
+	periodic.Run(ctx, "product purge", interval, func(ctx context.Context) {
+		purged, err := svc.PurgeDeleted(ctx, retention)
+		if err != nil {
+			log.Error().Err(err).Msg("Fail to purge deleted products")
+			return
+		}
+		if purged > 0 {
+			log.Info().Msg(fmt.Sprintf("Purged %d deleted products", purged))
+		}
+	})
+}
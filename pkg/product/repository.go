@@ -3,6 +3,39 @@ package product
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"coding-challenge-go/pkg/brand"
+	"coding-challenge-go/pkg/money"
+)
+
+const (
+	// errDuplicateEntry is the MySQL error number of a unique key violation.
+	errDuplicateEntry = 1062
+
+	// productStockExpression is the stock of a product, or for a bundle the number
+	// of bundles its components make. A component in the trash makes none.
+	productStockExpression = "COALESCE((SELECT GREATEST(0, MIN(IF(c.deleted_at IS NULL, FLOOR(c.stock / bc.quantity), 0))) " +
+		"FROM product_bundle_component bc INNER JOIN product c ON(c.id_product = bc.fk_component) " +
+		"WHERE bc.fk_bundle = p.id_product), p.stock)"
+
+	// heldStockQuery sums the units held by the unexpired reservations of the product row.
+	heldStockQuery = "(SELECT COALESCE(SUM(r.quantity), 0) FROM reservation r " +
+		"WHERE r.fk_product = product.id_product AND r.status = 'active' AND r.expires_at > UTC_TIMESTAMP())"
+
+	// selectProductQuery derives the available stock by subtracting units held by unexpired reservations.
+	selectProductQuery = "SELECT p.id_product, p.name, p.brand, " + productStockExpression + ", " +
+		productStockExpression + " - COALESCE((SELECT SUM(r.quantity) FROM reservation r " +
+		"WHERE r.fk_product = p.id_product AND r.status = 'active' AND r.expires_at > UTC_TIMESTAMP()), 0), " +
+		"s.uuid, p.uuid, p.version, p.deleted_at, p.price_amount, p.price_currency, COALESCE(b.uuid, ''), p.attributes, " +
+		"COALESCE(p.sku, ''), COALESCE(p.gtin, ''), p.status, p.published_at, p.archived_at FROM product p " +
+		"INNER JOIN seller s ON(s.id_seller = p.fk_seller) " +
+		"LEFT JOIN brand b ON(b.id_brand = p.fk_brand)"
 )
 
 func NewRepository(db *sql.DB) Repository {
@@ -14,53 +47,151 @@ type repository struct {
 }
 
 func (r *repository) Delete(ctx context.Context, product *Product) error {
-	rows, err := r.db.Query("DELETE FROM product WHERE uuid = ?", product.UUID)
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE product SET deleted_at = UTC_TIMESTAMP(), version = version + 1 WHERE uuid = ? AND version = ? AND deleted_at IS NULL",
+		product.UUID, product.Version,
+	)
 
 	if err != nil {
 		return err
 	}
 
-	defer rows.Close()
+	if err = checkVersionMatched(result, product); err != nil {
+		return err
+	}
+
+	deletedAt := time.Now().UTC()
+	product.DeletedAt = &deletedAt
+	product.Version++
 
 	return nil
 }
 
-func (r *repository) Create(ctx context.Context, product *Product) error {
-	rows, err := r.db.Query(
-		"INSERT INTO product (id_product, name, brand, stock, fk_seller, uuid) VALUES(?,?,?,?,(SELECT id_seller FROM seller WHERE uuid = ?),?)",
-		product.ProductID, product.Name, product.Brand, product.Stock, product.SellerUUID, product.UUID,
+func (r *repository) Create(ctx context.Context, product *Product, movement *StockMovement) error {
+	attributes, err := marshalAttributes(product.Attributes)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO product (id_product, name, normalized_name, brand, fk_brand, stock, fk_seller, uuid, price_amount, price_currency, attributes, "+
+			"sku, gtin, status, published_at, archived_at) "+
+			"VALUES(?,?,?,?,(SELECT id_brand FROM brand WHERE uuid = ?),?,(SELECT id_seller FROM seller WHERE uuid = ?),?,?,?,?,?,?,?,?,?)",
+		product.ProductID, product.Name, brand.Normalize(product.Name), product.Brand, product.BrandUUID, product.Stock, product.SellerUUID, product.UUID,
+		priceAmount(product.Price), priceCurrency(product.Price), attributes, nullString(product.SKU), nullString(product.GTIN),
+		product.Status, product.PublishedAt, product.ArchivedAt,
 	)
 
 	if err != nil {
+		return identifierConflict(err, product)
+	}
+	if err := writeStockMovement(ctx, tx, movement, product, product.Stock); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
 		return err
 	}
 
-	defer rows.Close()
+	product.Version = 1
+	product.AvailableStock = product.Stock
 
 	return nil
 }
 
-func (r *repository) Update(ctx context.Context, product *Product) error {
-	rows, err := r.db.Query(
-		"UPDATE product SET name = ?, brand = ?, stock = ? WHERE uuid = ?",
-		product.Name, product.Brand, product.Stock, product.UUID,
+func (r *repository) Update(ctx context.Context, product *Product, movement *StockMovement) error {
+	attributes, err := marshalAttributes(product.Attributes)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var stock, held int
+	var bundle bool
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT stock, "+heldStockQuery+", EXISTS(SELECT 1 FROM product_bundle_component WHERE fk_bundle = product.id_product) "+
+			"FROM product WHERE uuid = ? AND version = ? AND deleted_at IS NULL FOR UPDATE",
+		product.UUID, product.Version,
+	).Scan(&stock, &held, &bundle)
+	if err == sql.ErrNoRows {
+		return NewPreconditionFailedError(product.UUID)
+	}
+	if err != nil {
+		return err
+	}
+	// The stock of a bundle is derived from its components, so product.Stock is
+	// the buildable count and the column keeps what it holds.
+	newStock := product.Stock
+	if bundle {
+		newStock = stock
+	}
+	if newStock < stock && newStock < held {
+		return NewHeldStockError(product.UUID, stock-held, newStock-stock)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE product SET name = ?, normalized_name = ?, brand = ?, fk_brand = (SELECT id_brand FROM brand WHERE uuid = ?), stock = ?, "+
+			"price_amount = ?, price_currency = ?, attributes = ?, sku = ?, gtin = ?, version = version + 1 "+
+			"WHERE uuid = ?",
+		product.Name, brand.Normalize(product.Name), product.Brand, product.BrandUUID, newStock, priceAmount(product.Price), priceCurrency(product.Price),
+		attributes, nullString(product.SKU), nullString(product.GTIN), product.UUID,
 	)
 
 	if err != nil {
+		return identifierConflict(err, product)
+	}
+	if err := writeStockMovement(ctx, tx, movement, product, newStock-stock); err != nil {
 		return err
 	}
 
-	defer rows.Close()
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	product.Version++
 
 	return nil
 }
 
-func (r *repository) List(ctx context.Context, offset int, limit int) ([]*Product, error) {
-	rows, err := r.db.Query(
-		"SELECT p.id_product, p.name, p.brand, p.stock, s.uuid, p.uuid FROM product p "+
-			"INNER JOIN seller s ON(s.id_seller = p.fk_seller) LIMIT ? OFFSET ?",
-		limit, offset,
+func (r *repository) UpdateStatus(ctx context.Context, product *Product) error {
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE product SET status = ?, published_at = ?, archived_at = ?, version = version + 1 "+
+			"WHERE uuid = ? AND version = ? AND deleted_at IS NULL",
+		product.Status, product.PublishedAt, product.ArchivedAt, product.UUID, product.Version,
 	)
+	if err != nil {
+		return err
+	}
+
+	if err = checkVersionMatched(result, product); err != nil {
+		return err
+	}
+
+	product.Version++
+
+	return nil
+}
+
+func (r *repository) List(ctx context.Context, params *FilterParams, offset int, limit int) ([]*Product, error) {
+	where, args := buildFilterClause(params)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Query(selectProductQuery+where+buildOrderClause(params)+" LIMIT ? OFFSET ?", args...)
 
 	if err != nil {
 		return nil, err
@@ -73,7 +204,7 @@ func (r *repository) List(ctx context.Context, offset int, limit int) ([]*Produc
 	for rows.Next() {
 		product := &Product{}
 
-		err = rows.Scan(&product.ProductID, &product.Name, &product.Brand, &product.Stock, &product.SellerUUID, &product.UUID)
+		err = scanProduct(rows, product)
 
 		if err != nil {
 			return nil, err
@@ -85,12 +216,43 @@ func (r *repository) List(ctx context.Context, offset int, limit int) ([]*Produc
 	return products, nil
 }
 
+func (r *repository) Iterate(ctx context.Context, params *FilterParams, fn func(product *Product) error) error {
+	where, args := buildFilterClause(params)
+
+	order := buildOrderClause(params)
+	if order == "" {
+		order = " ORDER BY p.id_product"
+	}
+
+	rows, err := r.db.QueryContext(ctx, selectProductQuery+where+order, args...)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	// A single Product is reused for every row so memory stays constant
+	// regardless of how many rows the cursor yields.
+	product := &Product{}
+
+	for rows.Next() {
+		err = scanProduct(rows, product)
+
+		if err != nil {
+			return err
+		}
+
+		if err = fn(product); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 func (r *repository) FindByUUID(ctx context.Context, uuid string) (*Product, error) {
-	rows, err := r.db.Query(
-		"SELECT p.id_product, p.name, p.brand, p.stock, s.uuid, p.uuid FROM product p "+
-			"INNER JOIN seller s ON(s.id_seller = p.fk_seller) WHERE p.uuid = ?",
-		uuid,
-	)
+	rows, err := r.db.Query(selectProductQuery+" WHERE p.uuid = ? AND p.deleted_at IS NULL", uuid)
 
 	if err != nil {
 		return nil, err
@@ -103,7 +265,7 @@ func (r *repository) FindByUUID(ctx context.Context, uuid string) (*Product, err
 	}
 
 	product := &Product{}
-	err = rows.Scan(&product.ProductID, &product.Name, &product.Brand, &product.Stock, &product.SellerUUID, &product.UUID)
+	err = scanProduct(rows, product)
 
 	if err != nil {
 		return nil, err
@@ -111,3 +273,379 @@ func (r *repository) FindByUUID(ctx context.Context, uuid string) (*Product, err
 
 	return product, nil
 }
+
+func (r *repository) FindBySKU(ctx context.Context, sellerUUID string, sku string) (*Product, error) {
+	return r.findOne(ctx, " WHERE s.uuid = ? AND p.sku = ?", sellerUUID, sku)
+}
+
+func (r *repository) FindByGTIN(ctx context.Context, gtin string) (*Product, error) {
+	return r.findOne(ctx, " WHERE p.gtin14 = ?", gtin)
+}
+
+// findOne returns the first product matching where, or nil.
+func (r *repository) findOne(ctx context.Context, where string, args ...interface{}) (*Product, error) {
+	rows, err := r.db.QueryContext(ctx, selectProductQuery+where+" LIMIT 1", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	product := &Product{}
+	if err = scanProduct(rows, product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+func (r *repository) AdjustStock(ctx context.Context, uuid string, delta int, allowNegative bool, reservation string, movement *StockMovement) (*Product, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if reservation != "" {
+		result, err := tx.ExecContext(
+			ctx,
+			"UPDATE reservation r INNER JOIN product p ON(p.id_product = r.fk_product) SET r.status = 'confirmed' "+
+				"WHERE r.uuid = ? AND p.uuid = ? AND r.status = 'active' AND r.expires_at > UTC_TIMESTAMP()",
+			reservation, uuid,
+		)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if affected == 0 {
+			return nil, NewReservationNotActiveError(reservation)
+		}
+	}
+
+	product, err := adjustStock(ctx, tx, uuid, delta, allowNegative, movement)
+	if err != nil {
+		return nil, err
+	}
+
+	return product, tx.Commit()
+}
+
+func (r *repository) AdjustStockBatch(ctx context.Context, deltas []*StockDelta, allowNegative bool, movement *StockMovement) ([]*Product, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	products := make([]*Product, 0, len(deltas))
+	for _, d := range deltas {
+		m := *movement
+		product, err := adjustStock(ctx, tx, d.ProductUUID, d.Delta, allowNegative, &m)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	return products, tx.Commit()
+}
+
+// adjustStock adds delta to the stock of a product within tx, records the movement
+// and returns the updated product. A decrement must leave the units held by active
+// reservations in stock.
+func adjustStock(ctx context.Context, tx *sql.Tx, uuid string, delta int, allowNegative bool, movement *StockMovement) (*Product, error) {
+	result, err := tx.ExecContext(
+		ctx,
+		"UPDATE product SET stock = stock + ?, version = version + 1 WHERE uuid = ? AND deleted_at IS NULL "+
+			"AND (? OR stock + ? >= IF(? < 0, "+heldStockQuery+", 0))",
+		delta, uuid, allowNegative, delta, delta,
+	)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	// The updated row stays locked until commit, so this read sees exactly the
+	// result of the update above.
+	rows, err := tx.QueryContext(ctx, selectProductQuery+" WHERE p.uuid = ? AND p.deleted_at IS NULL", uuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, NewProductNotFoundError(uuid)
+	}
+	product := &Product{}
+	if err = scanProduct(rows, product); err != nil {
+		return nil, err
+	}
+	if err = rows.Close(); err != nil {
+		return nil, err
+	}
+	if affected == 0 && product.Stock+delta >= 0 {
+		return nil, NewHeldStockError(uuid, product.AvailableStock, delta)
+	}
+	if affected == 0 {
+		return nil, NewInsufficientStockError(uuid, product.Stock, delta)
+	}
+	if err = writeStockMovement(ctx, tx, movement, product, delta); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func (r *repository) Restore(ctx context.Context, uuid string) (bool, error) {
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE product SET deleted_at = NULL, version = version + 1 WHERE uuid = ? AND deleted_at IS NOT NULL",
+		uuid,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *repository) Purge(ctx context.Context, retention time.Duration) ([]string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(
+		ctx,
+		"SELECT uuid FROM product WHERE deleted_at IS NOT NULL AND deleted_at <= DATE_SUB(UTC_TIMESTAMP(), INTERVAL ? SECOND) "+
+			"AND NOT EXISTS (SELECT 1 FROM product_bundle_component bc WHERE bc.fk_component = product.id_product) FOR UPDATE",
+		int(retention/time.Second),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var purged []string
+	var uuids []interface{}
+	for rows.Next() {
+		var uuid string
+		if err := rows.Scan(&uuid); err != nil {
+			return nil, err
+		}
+		purged = append(purged, uuid)
+		uuids = append(uuids, uuid)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if len(uuids) == 0 {
+		return nil, nil
+	}
+
+	in := "(?" + strings.Repeat(",?", len(uuids)-1) + ")"
+	// The audit log, price history and stock ledger name products by UUID, so no
+	// foreign key cascades into them.
+	for _, table := range []string{"product_audit", "product_price_history", "stock_movement"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE product_uuid IN "+in, uuids...); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product WHERE uuid IN "+in, uuids...); err != nil {
+		return nil, err
+	}
+
+	return purged, tx.Commit()
+}
+
+// scanProduct reads a row selected by selectProductQuery into product.
+func scanProduct(rows *sql.Rows, product *Product) error {
+	var (
+		amount     sql.NullInt64
+		currency   sql.NullString
+		attributes []byte
+	)
+
+	err := rows.Scan(
+		&product.ProductID, &product.Name, &product.Brand, &product.Stock, &product.AvailableStock,
+		&product.SellerUUID, &product.UUID, &product.Version, &product.DeletedAt, &amount, &currency, &product.BrandUUID,
+		&attributes, &product.SKU, &product.GTIN, &product.Status, &product.PublishedAt, &product.ArchivedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if product.Attributes, err = unmarshalAttributes(attributes); err != nil {
+		return err
+	}
+
+	product.Price = nil
+	if amount.Valid && currency.Valid {
+		product.Price = &money.Money{Amount: amount.Int64, Currency: currency.String}
+	}
+
+	return nil
+}
+
+func priceAmount(price *money.Money) interface{} {
+	if price == nil {
+		return nil
+	}
+	return price.Amount
+}
+
+func priceCurrency(price *money.Money) interface{} {
+	if price == nil {
+		return nil
+	}
+	return price.Currency
+}
+
+// nullString turns an unset optional identifier into NULL, which unique keys ignore.
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// identifierConflict turns a duplicate key error on the SKU or GTIN into an
+// IdentifierConflictError. It covers a concurrent write that got past the
+// check in the service.
+func identifierConflict(err error, product *Product) error {
+	mysqlErr, ok := err.(*mysql.MySQLError)
+	if !ok || mysqlErr.Number != errDuplicateEntry {
+		return err
+	}
+	switch {
+	case strings.Contains(mysqlErr.Message, "seller_sku"):
+		return NewIdentifierConflictError("sku", product.SKU, "")
+	case strings.Contains(mysqlErr.Message, "gtin14"):
+		return NewIdentifierConflictError("gtin", product.GTIN, "")
+	}
+	return err
+}
+
+// checkVersionMatched returns a PreconditionFailedError when a write guarded by
+// product.Version touched no row, i.e. another write got there first.
+func checkVersionMatched(result sql.Result, product *Product) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return NewPreconditionFailedError(product.UUID)
+	}
+	return nil
+}
+
+// buildFilterClause returns the WHERE clause and its arguments for params.
+// Soft-deleted products are excluded unless params asks for them.
+func buildFilterClause(params *FilterParams) (string, []interface{}) {
+	if params == nil {
+		params = &FilterParams{}
+	}
+
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	if params.Deleted {
+		conditions = append(conditions, "p.deleted_at IS NOT NULL")
+	} else {
+		conditions = append(conditions, "p.deleted_at IS NULL")
+	}
+
+	if params.SellerUUID != "" {
+		conditions = append(conditions, "s.uuid = ?")
+		args = append(args, params.SellerUUID)
+	}
+	if params.Brand != "" {
+		// A brand is matched by UUID or by any spelling of its name.
+		conditions = append(conditions, "(p.brand = ? OR b.uuid = ? OR b.normalized_name = ?)")
+		args = append(args, params.Brand, params.Brand, brand.Normalize(params.Brand))
+	}
+	if params.Currency != "" {
+		conditions = append(conditions, "p.price_currency = ?")
+		args = append(args, params.Currency)
+	}
+	if params.MinPrice != nil {
+		conditions = append(conditions, "p.price_amount >= ?")
+		args = append(args, *params.MinPrice)
+	}
+	if params.MaxPrice != nil {
+		conditions = append(conditions, "p.price_amount <= ?")
+		args = append(args, *params.MaxPrice)
+	}
+	for _, name := range sortedKeys(params.Attributes) {
+		// Names are restricted to IsAttributeName, so they are safe inside the JSON path.
+		conditions = append(conditions, "JSON_UNQUOTE(JSON_EXTRACT(p.attributes, ?)) = ?")
+		args = append(args, fmt.Sprintf(`$."%s"`, name), params.Attributes[name])
+	}
+	if len(params.Statuses) > 0 {
+		conditions = append(conditions, "p.status IN (?"+strings.Repeat(",?", len(params.Statuses)-1)+")")
+		for _, status := range params.Statuses {
+			args = append(args, status)
+		}
+	}
+	if params.NamePrefix != "" {
+		conditions = append(conditions, "p.normalized_name LIKE ?")
+		args = append(args, escapeLike(params.NamePrefix)+"%")
+	}
+	if params.Category != "" {
+		// Matches the category itself and every category whose path starts below it.
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM product_category pc "+
+			"INNER JOIN category c ON(c.id_category = pc.fk_category) "+
+			"INNER JOIN category f ON(f.uuid = ? OR f.path = ?) "+
+			"WHERE pc.fk_product = p.id_product AND (c.path = f.path OR c.path LIKE CONCAT(f.path, '/%')))")
+		args = append(args, params.Category, params.Category)
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// escapeLike makes s match itself in a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// buildOrderClause returns the ORDER BY clause for params.Sort. Products without
+// a price are listed last in both directions.
+func buildOrderClause(params *FilterParams) string {
+	if params == nil {
+		return ""
+	}
+
+	switch params.Sort {
+	case SortPriceAsc:
+		return " ORDER BY p.price_amount IS NULL, p.price_amount ASC, p.id_product"
+	case SortPriceDesc:
+		return " ORDER BY p.price_amount IS NULL, p.price_amount DESC, p.id_product"
+	}
+
+	return ""
+}
@@ -3,8 +3,20 @@ package product
 import (
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/category"
+	"coding-challenge-go/pkg/money"
+	"coding-challenge-go/pkg/requestinfo"
 	"coding-challenge-go/pkg/seller"
+	"coding-challenge-go/pkg/storage"
 )
 
 var (
@@ -12,20 +24,124 @@ var (
 )
 
 const (
-	defaultListPageSize = 10
+	defaultListPageSize    = 10
+	defaultHistoryPageSize = 20
 )
 
 type (
 	Service interface {
 		List(ctx context.Context, params *FilterParams) ([]*ProductInfo, error)
 		FindByUUID(ctx context.Context, uuid string) (*ProductInfo, error)
+		// FindByGTIN returns the product with the given EAN-8, UPC-A, EAN-13 or GTIN-14.
+		// Codes that only differ in leading zeros find the same product.
+		FindByGTIN(ctx context.Context, code string) (*ProductInfo, error)
+		// FindBySKU returns the live product of a seller with the given SKU.
+		FindBySKU(ctx context.Context, sellerUUID string, sku string) (*ProductInfo, error)
+		// Update overwrites the product. A non-zero product.Version must match the
+		// stored version, otherwise a PreconditionFailedError is returned. A nil
+		// product.Price keeps the stored price, so V1 clients never clear it. An
+		// empty SKU or GTIN keeps the stored one for the same reason.
 		Update(ctx context.Context, product *Product) error
-		Create(ctx context.Context, product *Product) error
-		Delete(ctx context.Context, uuid string) error
+		// Patch changes only the fields present in patch and returns the updated product.
+		// A non-zero version must match the stored version.
+		Patch(ctx context.Context, uuid string, version int, patch *Patch) (*ProductInfo, error)
+		// Create stores a new product. When duplicate checks are on and the seller
+		// already has a product that looks the same, Create either fails with a
+		// DuplicateProductError or stores the product and returns the look-alike.
+		Create(ctx context.Context, product *Product) (*Duplicate, error)
+		// Duplicates reports a page of groups of live products of the same seller
+		// that look alike, for every seller when sellerUUID is empty.
+		Duplicates(ctx context.Context, sellerUUID string, page int) ([]*DuplicateGroup, error)
+		// Delete moves the product to the trash. A non-zero version must match the stored version.
+		Delete(ctx context.Context, uuid string, version int) error
+		// Restore moves a product out of the trash.
+		Restore(ctx context.Context, uuid string) (*ProductInfo, error)
+		// PurgeDeleted permanently removes products that have been in the trash for longer than retention.
+		PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error)
+		// History returns the audit entries of a product, newest first.
+		History(ctx context.Context, uuid string, page int) ([]*AuditEntry, error)
+		// PriceHistory returns the price changes of a product, newest first.
+		PriceHistory(ctx context.Context, uuid string, page int) ([]*PriceChange, error)
+		// PriceAlerts returns the subscriptions to price drops of a product.
+		PriceAlerts(ctx context.Context, uuid string) ([]*PriceAlert, error)
+		// SubscribePriceAlert subscribes the email address or phone number of alert
+		// to price drops of a product.
+		SubscribePriceAlert(ctx context.Context, productUUID string, alert *PriceAlert) error
+		// UnsubscribePriceAlert removes a subscription to price drops of a product.
+		UnsubscribePriceAlert(ctx context.Context, productUUID string, uuid string) error
+		// StockMovements returns the stock ledger of a product, newest first.
+		StockMovements(ctx context.Context, uuid string, page int) ([]*StockMovement, error)
+		// StockDiscrepancies returns the products, of one seller unless sellerUUID is
+		// empty, whose stock is not the sum of their stock movements.
+		StockDiscrepancies(ctx context.Context, sellerUUID string) ([]*StockDiscrepancy, error)
+		// Variants returns the variants of a product, ordered by SKU.
+		Variants(ctx context.Context, uuid string) ([]*Variant, error)
+		// CreateVariant adds a variant to a product. The first variant fixes the
+		// option axes, and from then on the product stock is the sum of its variants.
+		CreateVariant(ctx context.Context, productUUID string, variant *Variant) error
+		// AdjustVariantStock adds adjustment.Delta to the stock of one variant.
+		AdjustVariantStock(ctx context.Context, productUUID string, variantUUID string, adjustment *StockAdjustment) (*Variant, error)
+		DeleteVariant(ctx context.Context, productUUID string, variantUUID string) error
+		// LocationStock returns the stock of a product per location, ordered by location name.
+		LocationStock(ctx context.Context, uuid string) ([]*LocationStock, error)
+		// AdjustLocationStock adds adjustment.Delta to the stock at one location of the
+		// seller. From then on the product stock is the sum across its locations.
+		AdjustLocationStock(ctx context.Context, productUUID string, locationUUID string, adjustment *StockAdjustment) (*LocationStock, error)
+		// MoveStock takes units from one location to another, leaving the product stock
+		// unchanged, and returns the stock per location.
+		MoveStock(ctx context.Context, productUUID string, move *StockMove) ([]*LocationStock, error)
+		// Images returns the images of a product, oldest first.
+		Images(ctx context.Context, uuid string) ([]*ImageInfo, error)
+		// AddImage stores the image read from r together with its thumbnails. The
+		// content type is sniffed from the data, whatever the client claims.
+		AddImage(ctx context.Context, productUUID string, r io.Reader) (*ImageInfo, error)
+		// OpenImage returns the original or a thumbnail of an image. The caller closes the object.
+		OpenImage(ctx context.Context, productUUID string, imageUUID string, size string) (*Image, *storage.Object, error)
+		DeleteImage(ctx context.Context, productUUID string, imageUUID string) error
+		// SetCategories replaces the categories a product is assigned to.
+		SetCategories(ctx context.Context, uuid string, categoryUUIDs []string) (*ProductInfo, error)
+		// Revert updates name, brand, stock, price and attributes to their values at revision. It behaves
+		// like Update, so a non-zero version must match the stored version.
+		Revert(ctx context.Context, uuid string, revision int, version int) (*ProductInfo, error)
+		// Export streams every product matching params to w in the given format.
+		Export(ctx context.Context, params *FilterParams, format ExportFormat, w io.Writer) error
+		// AdjustStock atomically adds adjustment.Delta to the stock and returns the updated product.
+		// Adjusting a bundle changes each component by Delta times its quantity instead.
+		AdjustStock(ctx context.Context, uuid string, adjustment *StockAdjustment) (*ProductInfo, error)
+		// AdjustStockBatch applies every delta of batch in one transaction and returns the
+		// updated products. Each seller gets one summary of its changes instead of a
+		// notification per product.
+		AdjustStockBatch(ctx context.Context, batch *StockBatch) ([]*Product, error)
+		// SetStatus moves a product to another lifecycle status. A StatusTransitionError
+		// is returned when the move is not allowed, and a non-zero version must match
+		// the stored version.
+		SetStatus(ctx context.Context, uuid string, status Status, version int) (*ProductInfo, error)
+		// SetComponents turns a product into a bundle of other products of its seller,
+		// or replaces its components. From then on its stock is the number of bundles
+		// the components make. A non-zero version must match the stored version.
+		SetComponents(ctx context.Context, uuid string, components []*Component, version int) (*ProductInfo, error)
 	}
 
 	FilterParams struct {
 		Pagination *Pagination
+		SellerUUID string
+		Brand      string
+		// Deleted selects products in the trash instead of live ones.
+		Deleted bool
+		// Currency, MinPrice and MaxPrice filter on the price; amounts are in minor units.
+		Currency string
+		MinPrice *int64
+		MaxPrice *int64
+		Sort     Sort
+		// Category selects products in a category or any of its descendants, by UUID or path.
+		Category string
+		// Attributes selects products whose attributes equal the given values. Numbers
+		// and booleans match their JSON text, e.g. "220" or "true".
+		Attributes map[string]string
+		// Statuses selects products in any of the given statuses, all of them when empty.
+		Statuses []Status
+		// NamePrefix selects products whose name, normalized like brand names, starts with it.
+		NamePrefix string
 	}
 
 	Pagination struct {
@@ -33,25 +149,89 @@ type (
 	}
 
 	Repository interface {
-		// List to get list of products by offset and limit.
-		List(ctx context.Context, offset int, limit int) ([]*Product, error)
+		// List to get list of products matching params by offset and limit.
+		List(ctx context.Context, params *FilterParams, offset int, limit int) ([]*Product, error)
+		// Iterate calls fn for every product matching params while reading from the DB cursor.
+		// The product passed to fn is reused between calls and must not be retained.
+		Iterate(ctx context.Context, params *FilterParams, fn func(product *Product) error) error
 		// FindByUUID return a product when found.
 		FindByUUID(ctx context.Context, uuid string) (*Product, error)
-		// Update to update product information.
-		Update(ctx context.Context, product *Product) error
-		Create(ctx context.Context, product *Product) error
+		// Update to update product information when product.Version is still current.
+		// On success product.Version is the new version. A HeldStockError is returned
+		// when lowering the stock would take units held by active reservations. A stock
+		// change is written to the ledger as movement in the same transaction.
+		Update(ctx context.Context, product *Product, movement *StockMovement) error
+		// Create stores a new product and writes its stock to the ledger as movement
+		// in the same transaction.
+		Create(ctx context.Context, product *Product, movement *StockMovement) error
+		// Delete soft-deletes the product when product.Version is still current.
 		Delete(ctx context.Context, product *Product) error
+		// Restore clears the deletion of a soft-deleted product and reports whether one was found.
+		Restore(ctx context.Context, uuid string) (bool, error)
+		// Purge hard-deletes products soft-deleted longer than retention ago, along with
+		// their audit log, price history and stock ledger. Components of a bundle are
+		// kept until the bundle no longer lists them. It returns the UUIDs of the purged products.
+		Purge(ctx context.Context, retention time.Duration) ([]string, error)
+		// FindBySKU returns the product of a seller with the given SKU, or nil. Like
+		// FindByGTIN it includes products in the trash.
+		FindBySKU(ctx context.Context, sellerUUID string, sku string) (*Product, error)
+		// FindByGTIN returns the product whose GTIN, padded to 14 digits, is gtin, or nil.
+		FindByGTIN(ctx context.Context, gtin string) (*Product, error)
+		// AdjustStock adds delta to the stock in a single update, writes movement to the
+		// stock ledger in the same transaction and returns the updated product. Unless
+		// allowNegative is set, an InsufficientStockError is returned when the stock
+		// would drop below zero, and a HeldStockError when a decrement would take units
+		// held by active reservations. A non-empty reservation is confirmed in the same
+		// transaction, so its units are no longer held.
+		AdjustStock(ctx context.Context, uuid string, delta int, allowNegative bool, reservation string, movement *StockMovement) (*Product, error)
+		// AdjustStockBatch adds every delta like AdjustStock, all in one transaction,
+		// with a copy of movement for every product.
+		AdjustStockBatch(ctx context.Context, deltas []*StockDelta, allowNegative bool, movement *StockMovement) ([]*Product, error)
+		// UpdateStatus stores the status of product and its timestamps when product.Version
+		// is still current. On success product.Version is the new version.
+		UpdateStatus(ctx context.Context, product *Product) error
 	}
 
 	service struct {
-		repo         Repository
-		sellerRepo   seller.Repository
-		notiProvider seller.NotiProvider
+		repo            Repository
+		sellerRepo      seller.Repository
+		notiProvider    seller.NotiProvider
+		auditRepo       AuditRepository
+		allowBackorders bool
+
+		priceHistoryRepo      PriceHistoryRepository
+		priceAlertRepo        PriceAlertRepository
+		priceDropAlertPercent float64
+
+		stockMovementRepo StockMovementRepository
+
+		variantRepo   VariantRepository
+		categoryRepo  CategoryRepository
+		brandResolver BrandResolver
+
+		locationRepo      LocationRepository
+		locationStockRepo LocationStockRepository
+
+		imageRepo    ImageRepository
+		imageStorage storage.Storage
+		maxImageSize int64
+
+		duplicateMode      DuplicateMode
+		duplicateThreshold float64
+
+		bundleRepo        BundleRepository
+		lowStockThreshold int
 	}
 
 	ProductInfo struct {
 		*Product
-		Seller *SellerInfo `json:"seller"`
+		Seller     *SellerInfo      `json:"seller"`
+		Variants   []*Variant       `json:"variants,omitempty"`
+		Locations  []*LocationStock `json:"locations,omitempty"`
+		Categories []*CategoryInfo  `json:"categories,omitempty"`
+		Components []*Component     `json:"components,omitempty"`
+		Links      *ProductLinks    `json:"_links,omitempty"`
+		Images     []*Image         `json:"-"`
 	}
 	SellerInfo struct {
 		UUID  string       `json:"uuid"`
@@ -66,12 +246,34 @@ type (
 	}
 )
 
-func NewService(productRepo Repository, sellerRepo seller.Repository, notiProvider seller.NotiProvider) Service {
-	return &service{
+func NewService(productRepo Repository, sellerRepo seller.Repository, notiProvider seller.NotiProvider, opts ...Option) Service {
+	s := &service{
 		repo:         productRepo,
 		sellerRepo:   sellerRepo,
 		notiProvider: notiProvider,
+		auditRepo:    nopAuditRepository{},
+
+		priceHistoryRepo:  nopPriceHistoryRepository{},
+		priceAlertRepo:    nopPriceAlertRepository{},
+		stockMovementRepo: nopStockMovementRepository{},
+		variantRepo:       nopVariantRepository{},
+		categoryRepo:      nopCategoryRepository{},
+		brandResolver:     nopBrandResolver{},
+		locationRepo:      nopLocationRepository{},
+
+		locationStockRepo: nopLocationStockRepository{},
+		imageRepo:         nopImageRepository{},
+		maxImageSize:      defaultMaxImageSize,
+
+		duplicateMode:      DuplicateModeOff,
+		duplicateThreshold: defaultDuplicateThreshold,
+
+		bundleRepo: nopBundleRepository{},
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func (s *service) List(ctx context.Context, params *FilterParams) ([]*ProductInfo, error) {
@@ -80,7 +282,7 @@ func (s *service) List(ctx context.Context, params *FilterParams) ([]*ProductInf
 			PageNumber: 0,
 		}
 	}
-	products, err := s.repo.List(ctx, (params.Pagination.PageNumber-1)*defaultListPageSize, defaultListPageSize)
+	products, err := s.repo.List(ctx, params, (params.Pagination.PageNumber-1)*defaultListPageSize, defaultListPageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -91,6 +293,9 @@ func (s *service) List(ctx context.Context, params *FilterParams) ([]*ProductInf
 			Seller:  generateSellerInfo(p.SellerUUID),
 		}
 	}
+	if err := s.attachRelations(ctx, result...); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
@@ -103,10 +308,94 @@ func (s *service) FindByUUID(ctx context.Context, uuid string) (*ProductInfo, er
 		return nil, &ProductNotFoundError{id: uuid}
 	}
 
-	return &ProductInfo{
+	info := &ProductInfo{
 		Product: product,
 		Seller:  generateSellerInfo(product.SellerUUID),
-	}, nil
+	}
+	if err := s.attachRelations(ctx, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (s *service) FindByGTIN(ctx context.Context, code string) (*ProductInfo, error) {
+	if err := ValidateGTIN(code); err != nil {
+		return nil, err
+	}
+	product, err := s.repo.FindByGTIN(ctx, normalizeGTIN(code))
+	if err != nil {
+		return nil, err
+	}
+	if product == nil || product.DeletedAt != nil {
+		return nil, &ProductNotFoundError{id: code}
+	}
+
+	info := &ProductInfo{
+		Product: product,
+		Seller:  generateSellerInfo(product.SellerUUID),
+	}
+	if err := s.attachRelations(ctx, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (s *service) FindBySKU(ctx context.Context, sellerUUID string, sku string) (*ProductInfo, error) {
+	product, err := s.repo.FindBySKU(ctx, sellerUUID, sku)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil || product.DeletedAt != nil {
+		return nil, &ProductNotFoundError{id: sku}
+	}
+
+	info := &ProductInfo{
+		Product: product,
+		Seller:  generateSellerInfo(product.SellerUUID),
+	}
+	if err := s.attachRelations(ctx, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// attachRelations embeds the variants, locations, categories, images and links of each product.
+func (s *service) attachRelations(ctx context.Context, infos ...*ProductInfo) error {
+	uuids := make([]string, len(infos))
+	for i, info := range infos {
+		uuids[i] = info.UUID
+	}
+	variants, err := s.variantRepo.ListByProducts(ctx, uuids)
+	if err != nil {
+		return err
+	}
+	categories, err := s.categoryRepo.ListByProducts(ctx, uuids)
+	if err != nil {
+		return err
+	}
+	images, err := s.imageRepo.ListByProducts(ctx, uuids)
+	if err != nil {
+		return err
+	}
+	locations, err := s.locationStockRepo.ListByProducts(ctx, uuids)
+	if err != nil {
+		return err
+	}
+	components, err := s.bundleRepo.ListByProducts(ctx, uuids)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		info.Locations = locations[info.UUID]
+		info.Components = components[info.UUID]
+		info.Images = images[info.UUID]
+		info.Links = generateProductLinks(info.Product, info.Images)
+		info.Variants = variants[info.UUID]
+		for _, c := range categories[info.UUID] {
+			info.Categories = append(info.Categories, generateCategoryInfo(c))
+		}
+	}
+	return nil
 }
 
 func generateSellerInfo(sellerUUID string) *SellerInfo {
@@ -125,6 +414,11 @@ func generateSellerLink(sellerUUID string) string {
 }
 
 func (s *service) Update(ctx context.Context, product *Product) error {
+	return s.update(ctx, product, AuditActionUpdate)
+}
+
+// update overwrites the product and records the change under action.
+func (s *service) update(ctx context.Context, product *Product, action AuditAction) error {
 	p, err := s.repo.FindByUUID(ctx, product.UUID)
 	if err != nil {
 		return err
@@ -132,40 +426,231 @@ func (s *service) Update(ctx context.Context, product *Product) error {
 	if p == nil {
 		return &ProductNotFoundError{id: product.UUID}
 	}
+	if err := checkVersion(p, product.Version); err != nil {
+		return err
+	}
+	// A revert restores the snapshot exactly, including a missing price or attributes.
+	if product.Price == nil && action != AuditActionRevert {
+		product.Price = p.Price
+	}
+	if product.Attributes == nil && action != AuditActionRevert {
+		product.Attributes = p.Attributes
+	}
+	// Identifiers are not part of a revert, so they are always kept.
+	if product.SKU == "" {
+		product.SKU = p.SKU
+	}
+	if product.GTIN == "" {
+		product.GTIN = p.GTIN
+	}
+	// The status only changes through SetStatus.
+	product.Status, product.PublishedAt, product.ArchivedAt = p.Status, p.PublishedAt, p.ArchivedAt
+	if product.Stock != p.Stock {
+		source, err := s.stockSource(ctx, p.UUID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case source != "" && action == AuditActionRevert:
+			// The variants or locations own the stock, so a revert leaves it alone.
+			product.Stock = p.Stock
+		case source != "":
+			return NewDerivedStockError(p.UUID, source)
+		}
+	}
+	if err := validateFields(product, p); err != nil {
+		return err
+	}
+	if err := validatePrice(product); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(product.Attributes, p.Attributes) {
+		if err := s.validateAttributes(ctx, product); err != nil {
+			return err
+		}
+	}
+	if err := s.resolveBrand(ctx, product); err != nil {
+		return err
+	}
+	product.SellerUUID = p.SellerUUID
+	if product.SKU != p.SKU || product.GTIN != p.GTIN {
+		if err := s.checkIdentifiers(ctx, product); err != nil {
+			return err
+		}
+	}
 
 	oldStock := p.Stock
-	product.SellerUUID = p.SellerUUID
-	err = s.repo.Update(ctx, product)
+	product.Version = p.Version
+	err = s.repo.Update(ctx, product, newStockMovement(ctx, StockReasonCorrection, string(action)))
 	if err != nil {
 		return err
 	}
+	s.recordAudit(ctx, action, p, product)
+	s.recordPriceChange(ctx, p, product)
 	if oldStock != product.Stock {
-		sl, err := s.sellerRepo.FindByUUID(ctx, p.SellerUUID)
+		s.alertLowStock(ctx, map[string]int{product.UUID: oldStock}, product)
+		return s.notifyStockChanged(ctx, oldStock, product)
+	}
+	return nil
+}
+
+func (s *service) Patch(ctx context.Context, uuid string, version int, patch *Patch) (*ProductInfo, error) {
+	product, err := s.repo.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, &ProductNotFoundError{id: uuid}
+	}
+	if err := checkVersion(product, version); err != nil {
+		return nil, err
+	}
+
+	before := *product
+	patch.Apply(product)
+	if product.Stock != before.Stock {
+		source, err := s.stockSource(ctx, uuid)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if sl == nil {
-			return SellerNotFoundError{id: product.SellerUUID}
+		if source != "" {
+			return nil, NewDerivedStockError(uuid, source)
 		}
-		s.notiProvider.StockChanged(oldStock, product.Stock, product.Name, sl)
-		// log.Info().Msg(fmt.Sprintf("%s Warning sent to %s (Phone: %s): %s Product stock changed", s.notiProvider.Type().String(), sl.UUID, sl.Phone, p.Name))
+	}
+	if err := validateFields(product, &before); err != nil {
+		return nil, err
+	}
+	if err := validatePrice(product); err != nil {
+		return nil, err
+	}
+	if patch.Attributes != nil {
+		if err := s.validateAttributes(ctx, product); err != nil {
+			return nil, err
+		}
+	}
+	if patch.Brand != nil {
+		if err := s.resolveBrand(ctx, product); err != nil {
+			return nil, err
+		}
+	}
+	if patch.SKU != nil || patch.GTIN != nil {
+		if err := s.checkIdentifiers(ctx, product); err != nil {
+			return nil, err
+		}
+	}
+	err = s.repo.Update(ctx, product, newStockMovement(ctx, StockReasonCorrection, string(AuditActionUpdate)))
+	if err != nil {
+		return nil, err
+	}
+	s.recordAudit(ctx, AuditActionUpdate, &before, product)
+	s.recordPriceChange(ctx, &before, product)
+	oldStock := before.Stock
+	product.AvailableStock += product.Stock - oldStock
+	// Stock is only compared when the patch sends it, so patching other
+	// fields never triggers a stock notification.
+	if patch.Stock != nil && oldStock != product.Stock {
+		s.alertLowStock(ctx, map[string]int{product.UUID: oldStock}, product)
+		if err := s.notifyStockChanged(ctx, oldStock, product); err != nil {
+			return nil, err
+		}
+	}
+
+	info := &ProductInfo{
+		Product: product,
+		Seller:  generateSellerInfo(product.SellerUUID),
+	}
+	if err := s.attachRelations(ctx, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
 
+// notifyStockChanged warns the seller of product that its stock changed from oldStock.
+func (s *service) notifyStockChanged(ctx context.Context, oldStock int, product *Product) error {
+	if !notifiesStock(product) {
+		return nil
 	}
+	sl, err := s.findSeller(ctx, product.SellerUUID)
+	if err != nil {
+		return err
+	}
+	s.notiProvider.StockChanged(oldStock, product.Stock, product.Name, sl)
 	return nil
 }
 
-func (s *service) Create(ctx context.Context, product *Product) error {
-	seller, err := s.sellerRepo.FindByUUID(ctx, product.SellerUUID)
+// notifyVariantStockChanged warns the seller of product that the stock of one of
+// its variants changed from oldStock. The notification names the variant.
+func (s *service) notifyVariantStockChanged(ctx context.Context, oldStock int, variant *Variant, product *Product) error {
+	if !notifiesStock(product) {
+		return nil
+	}
+	sl, err := s.findSeller(ctx, product.SellerUUID)
 	if err != nil {
 		return err
 	}
+	s.notiProvider.StockChanged(oldStock, variant.Stock, variant.Label(product.Name), sl)
+	return nil
+}
+
+func (s *service) findSeller(ctx context.Context, uuid string) (*seller.Seller, error) {
+	sl, err := s.sellerRepo.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if sl == nil {
+		return nil, &SellerNotFoundError{id: uuid}
+	}
+	return sl, nil
+}
+
+func (s *service) Create(ctx context.Context, product *Product) (*Duplicate, error) {
+	if err := validateFields(product, nil); err != nil {
+		return nil, err
+	}
+	if err := validatePrice(product); err != nil {
+		return nil, err
+	}
+	// A new product has no categories yet, so no schema applies.
+	if err := product.Attributes.validate(); err != nil {
+		return nil, err
+	}
+	seller, err := s.sellerRepo.FindByUUID(ctx, product.SellerUUID)
+	if err != nil {
+		return nil, err
+	}
 	if seller == nil {
-		return &SellerNotFoundError{id: product.UUID}
+		return nil, &SellerNotFoundError{id: product.UUID}
+	}
+	if err := s.resolveBrand(ctx, product); err != nil {
+		return nil, err
+	}
+	if err := s.checkIdentifiers(ctx, product); err != nil {
+		return nil, err
+	}
+	if err := initStatus(product, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	var duplicate *Duplicate
+	if s.duplicateMode != DuplicateModeOff {
+		// The brand is resolved first, so spellings of one brand compare equal.
+		if duplicate, err = s.findDuplicate(ctx, product); err != nil {
+			return nil, err
+		}
+		if duplicate != nil && s.duplicateMode == DuplicateModeReject {
+			return nil, NewDuplicateProductError(duplicate.UUID)
+		}
 	}
-	return s.repo.Create(ctx, product)
+
+	if err := s.repo.Create(ctx, product, newStockMovement(ctx, StockReasonInitial, "")); err != nil {
+		return nil, err
+	}
+	s.recordAudit(ctx, AuditActionCreate, nil, product)
+	s.recordPriceChange(ctx, nil, product)
+	return duplicate, nil
 }
 
-func (s *service) Delete(ctx context.Context, uuid string) error {
+func (s *service) Delete(ctx context.Context, uuid string, version int) error {
 	product, err := s.repo.FindByUUID(ctx, uuid)
 	if err != nil {
 		return err
@@ -173,5 +658,578 @@ func (s *service) Delete(ctx context.Context, uuid string) error {
 	if product == nil {
 		return &ProductNotFoundError{id: uuid}
 	}
-	return s.repo.Delete(ctx, product)
+	if err := checkVersion(product, version); err != nil {
+		return err
+	}
+	before := *product
+	if err := s.repo.Delete(ctx, product); err != nil {
+		return err
+	}
+	s.recordAudit(ctx, AuditActionDelete, &before, product)
+	return nil
+}
+
+// validateAttributes checks the attributes of product, including against the
+// schemas of the categories it is assigned to.
+func (s *service) validateAttributes(ctx context.Context, product *Product) error {
+	if err := product.Attributes.validate(); err != nil {
+		return err
+	}
+	categories, err := s.categoryRepo.ListByProducts(ctx, []string{product.UUID})
+	if err != nil {
+		return err
+	}
+	return product.Attributes.checkSchemas(categories[product.UUID])
+}
+
+func validatePrice(product *Product) error {
+	if product.Price == nil {
+		return nil
+	}
+	if err := product.Price.Validate(); err != nil {
+		return NewInvalidPriceError(err.Error())
+	}
+	return nil
+}
+
+// checkVersion fails when the caller expects a version other than the stored one.
+// A zero version means the caller did not send a precondition.
+func checkVersion(product *Product, version int) error {
+	if version != 0 && version != product.Version {
+		return NewPreconditionFailedError(product.UUID)
+	}
+	return nil
+}
+
+func (s *service) Export(ctx context.Context, params *FilterParams, format ExportFormat, w io.Writer) error {
+	rw, err := NewRowWriter(format, w)
+	if err != nil {
+		return err
+	}
+	err = s.repo.Iterate(ctx, params, rw.Write)
+	if err != nil {
+		return err
+	}
+	return rw.Flush()
+}
+
+func (s *service) AdjustStock(ctx context.Context, uuid string, adjustment *StockAdjustment) (*ProductInfo, error) {
+	if err := adjustment.validate(); err != nil {
+		return nil, err
+	}
+	source, err := s.stockSource(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	switch source {
+	case "":
+	case StockSourceComponent:
+		return s.adjustBundleStock(ctx, uuid, adjustment)
+	default:
+		return nil, NewDerivedStockError(uuid, source)
+	}
+	movement := newStockMovement(ctx, adjustment.Code, adjustment.Reason)
+	product, err := s.repo.AdjustStock(ctx, uuid, adjustment.Delta, s.allowBackorders, adjustment.Reservation, movement)
+	if err != nil {
+		return nil, err
+	}
+	before := *product
+	before.Stock -= adjustment.Delta
+	s.recordAudit(ctx, AuditActionStockAdjustment, &before, product)
+	s.alertLowStock(ctx, map[string]int{product.UUID: before.Stock}, product)
+	if err := s.notifyStockChanged(ctx, product.Stock-adjustment.Delta, product); err != nil {
+		return nil, err
+	}
+	return &ProductInfo{
+		Product: product,
+		Seller:  generateSellerInfo(product.SellerUUID),
+	}, nil
+}
+
+func (s *service) AdjustStockBatch(ctx context.Context, batch *StockBatch) ([]*Product, error) {
+	if err := batch.validate(); err != nil {
+		return nil, err
+	}
+	for _, d := range batch.Deltas {
+		source, err := s.stockSource(ctx, d.ProductUUID)
+		if err != nil {
+			return nil, err
+		}
+		if source != "" {
+			return nil, NewDerivedStockError(d.ProductUUID, source)
+		}
+	}
+	products, err := s.repo.AdjustStockBatch(ctx, batch.Deltas, s.allowBackorders, newStockMovement(ctx, batch.Code, batch.Reason))
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string][]seller.StockChange{}
+	oldStock := make(map[string]int, len(products))
+	var sellers []string
+	for i, product := range products {
+		before := *product
+		before.Stock -= batch.Deltas[i].Delta
+		oldStock[product.UUID] = before.Stock
+		s.recordAudit(ctx, AuditActionStockAdjustment, &before, product)
+		if !notifiesStock(product) {
+			continue
+		}
+		if _, ok := changes[product.SellerUUID]; !ok {
+			sellers = append(sellers, product.SellerUUID)
+		}
+		changes[product.SellerUUID] = append(changes[product.SellerUUID], seller.StockChange{
+			Product:  product.Name,
+			OldStock: before.Stock,
+			NewStock: product.Stock,
+		})
+	}
+	// The batch is already committed, so a seller that cannot be told is only
+	// logged; failing here would invite applying the batch a second time.
+	for _, sellerUUID := range sellers {
+		sl, err := s.findSeller(ctx, sellerUUID)
+		if err != nil {
+			log.Error().Err(err).Msg(fmt.Sprintf("Fail to send stock summary to seller %s", sellerUUID))
+			continue
+		}
+		s.notiProvider.StockSummary(changes[sellerUUID], sl)
+	}
+	s.alertLowStock(ctx, oldStock, products...)
+	return products, nil
+}
+
+func (s *service) Variants(ctx context.Context, uuid string) ([]*Variant, error) {
+	product, err := s.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if product.Variants == nil {
+		return []*Variant{}, nil
+	}
+	return product.Variants, nil
+}
+
+func (s *service) CreateVariant(ctx context.Context, productUUID string, variant *Variant) error {
+	product, err := s.FindByUUID(ctx, productUUID)
+	if err != nil {
+		return err
+	}
+	if err := variant.validate(product.Variants); err != nil {
+		return err
+	}
+	// Stock is either split by variant or by location, never both.
+	if len(product.Locations) > 0 {
+		return NewDerivedStockError(productUUID, StockSourceLocation)
+	}
+	if len(product.Components) > 0 {
+		return NewDerivedStockError(productUUID, StockSourceComponent)
+	}
+	if err := s.checkNotComponent(ctx, productUUID, StockSourceVariant); err != nil {
+		return err
+	}
+
+	variant.UUID = uuid.New().String()
+	variant.ProductUUID = productUUID
+	movement := newStockMovement(ctx, StockReasonCorrection, "variant "+variant.SKU+" created")
+	updated, err := s.variantRepo.Create(ctx, variant, movement)
+	if err != nil {
+		return err
+	}
+	// The product may have changed since it was read; the movement tells what this write did.
+	before := *updated
+	before.Stock -= movement.Delta
+	s.recordAudit(ctx, AuditActionVariantCreate, &before, updated)
+	s.alertLowStock(ctx, map[string]int{productUUID: before.Stock}, updated)
+	return nil
+}
+
+func (s *service) AdjustVariantStock(ctx context.Context, productUUID string, variantUUID string, adjustment *StockAdjustment) (*Variant, error) {
+	if err := adjustment.validate(); err != nil {
+		return nil, err
+	}
+	movement := newStockMovement(ctx, adjustment.Code, adjustment.Reason)
+	variant, product, err := s.variantRepo.AdjustStock(ctx, productUUID, variantUUID, adjustment.Delta, s.allowBackorders, movement)
+	if err != nil {
+		return nil, err
+	}
+	before := *product
+	before.Stock -= movement.Delta
+	s.recordAudit(ctx, AuditActionVariantStockAdjustment, &before, product)
+	s.alertLowStock(ctx, map[string]int{productUUID: before.Stock}, product)
+	if err := s.notifyVariantStockChanged(ctx, variant.Stock-adjustment.Delta, variant, product); err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+func (s *service) DeleteVariant(ctx context.Context, productUUID string, variantUUID string) error {
+	product, err := s.repo.FindByUUID(ctx, productUUID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return &ProductNotFoundError{id: productUUID}
+	}
+	movement := newStockMovement(ctx, StockReasonCorrection, "variant "+variantUUID+" deleted")
+	updated, err := s.variantRepo.Delete(ctx, productUUID, variantUUID, movement)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		return NewVariantNotFoundError(productUUID, variantUUID)
+	}
+	before := *updated
+	before.Stock -= movement.Delta
+	s.recordAudit(ctx, AuditActionVariantDelete, &before, updated)
+	s.alertLowStock(ctx, map[string]int{productUUID: before.Stock}, updated)
+	return nil
+}
+
+func (s *service) LocationStock(ctx context.Context, uuid string) ([]*LocationStock, error) {
+	product, err := s.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if product.Locations == nil {
+		return []*LocationStock{}, nil
+	}
+	return product.Locations, nil
+}
+
+func (s *service) AdjustLocationStock(ctx context.Context, productUUID string, locationUUID string, adjustment *StockAdjustment) (*LocationStock, error) {
+	if err := adjustment.validate(); err != nil {
+		return nil, err
+	}
+	product, err := s.FindByUUID(ctx, productUUID)
+	if err != nil {
+		return nil, err
+	}
+	if len(product.Variants) > 0 {
+		return nil, NewDerivedStockError(productUUID, StockSourceVariant)
+	}
+	if len(product.Components) > 0 {
+		return nil, NewDerivedStockError(productUUID, StockSourceComponent)
+	}
+	if err := s.checkNotComponent(ctx, productUUID, StockSourceLocation); err != nil {
+		return nil, err
+	}
+	if _, err := s.findLocation(ctx, locationUUID, product.Product); err != nil {
+		return nil, err
+	}
+
+	movement := newStockMovement(ctx, adjustment.Code, adjustment.Reason)
+	stock, updated, err := s.locationStockRepo.AdjustStock(ctx, productUUID, locationUUID, adjustment.Delta, s.allowBackorders, movement)
+	if err != nil {
+		return nil, err
+	}
+	before := *updated
+	before.Stock -= movement.Delta
+	s.recordAudit(ctx, AuditActionLocationStockAdjustment, &before, updated)
+	s.alertLowStock(ctx, map[string]int{productUUID: before.Stock}, updated)
+	if err := s.notifyLocationStockChanged(ctx, stock.Stock-adjustment.Delta, stock, updated); err != nil {
+		return nil, err
+	}
+	return stock, nil
+}
+
+func (s *service) MoveStock(ctx context.Context, productUUID string, move *StockMove) ([]*LocationStock, error) {
+	if err := move.validate(); err != nil {
+		return nil, err
+	}
+	product, err := s.FindByUUID(ctx, productUUID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNotComponent(ctx, productUUID, StockSourceLocation); err != nil {
+		return nil, err
+	}
+	for _, locationUUID := range []string{move.From, move.To} {
+		if _, err := s.findLocation(ctx, locationUUID, product.Product); err != nil {
+			return nil, err
+		}
+	}
+
+	from, to, updated, err := s.locationStockRepo.Move(ctx, productUUID, move)
+	if err != nil {
+		return nil, err
+	}
+	s.recordAudit(ctx, AuditActionStockMove, product.Product, updated)
+	// The total stays the same, but each location is told about its own change.
+	if err := s.notifyLocationStockChanged(ctx, from.Stock+move.Quantity, from, updated); err != nil {
+		return nil, err
+	}
+	if err := s.notifyLocationStockChanged(ctx, to.Stock-move.Quantity, to, updated); err != nil {
+		return nil, err
+	}
+	return s.LocationStock(ctx, productUUID)
+}
+
+func (s *service) Images(ctx context.Context, uuid string) ([]*ImageInfo, error) {
+	product, err := s.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]*ImageInfo, len(product.Images))
+	for i, image := range product.Images {
+		infos[i] = generateImageInfo(image)
+	}
+	return infos, nil
+}
+
+func (s *service) AddImage(ctx context.Context, productUUID string, r io.Reader) (*ImageInfo, error) {
+	if s.imageStorage == nil {
+		return nil, NewInvalidImageError("images are not supported")
+	}
+	if _, err := s.FindByUUID(ctx, productUUID); err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(r, s.maxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxImageSize {
+		return nil, NewImageTooLargeError(s.maxImageSize)
+	}
+	contentType := http.DetectContentType(data)
+	if !imageContentTypes[contentType] {
+		return nil, NewUnsupportedImageTypeError(contentType)
+	}
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	image := &Image{
+		UUID:        uuid.New().String(),
+		ProductUUID: productUUID,
+		ContentType: contentType,
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		Size:        int64(len(data)),
+		CreatedAt:   time.Now().UTC(),
+	}
+	err = s.storeImage(ctx, image, data, img)
+	if err == nil {
+		err = s.imageRepo.Create(ctx, image)
+	}
+	if err != nil {
+		if err := s.imageStorage.Delete(ctx, imagePrefix(productUUID, image.UUID)); err != nil {
+			log.Error().Err(err).Msg(fmt.Sprintf("Fail to clean up image id=%s", image.UUID))
+		}
+		return nil, err
+	}
+	return generateImageInfo(image), nil
+}
+
+func (s *service) OpenImage(ctx context.Context, productUUID string, imageUUID string, size string) (*Image, *storage.Object, error) {
+	if !isImageSize(size) {
+		return nil, nil, NewInvalidImageError(fmt.Sprintf("unknown size %s", size))
+	}
+	product, err := s.FindByUUID(ctx, productUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, image := range product.Images {
+		if image.UUID != imageUUID || s.imageStorage == nil {
+			continue
+		}
+		object, err := s.imageStorage.Open(ctx, imageKey(productUUID, imageUUID, size))
+		if _, ok := err.(*storage.ObjectNotFoundError); ok {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return image, object, nil
+	}
+	return nil, nil, NewImageNotFoundError(productUUID, imageUUID)
+}
+
+func (s *service) DeleteImage(ctx context.Context, productUUID string, imageUUID string) error {
+	if _, err := s.FindByUUID(ctx, productUUID); err != nil {
+		return err
+	}
+	deleted, err := s.imageRepo.Delete(ctx, productUUID, imageUUID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return NewImageNotFoundError(productUUID, imageUUID)
+	}
+	// The image is gone once its row is, so leftover files are only logged.
+	if s.imageStorage != nil {
+		if err := s.imageStorage.Delete(ctx, imagePrefix(productUUID, imageUUID)); err != nil {
+			log.Error().Err(err).Msg(fmt.Sprintf("Fail to delete files of image id=%s", imageUUID))
+		}
+	}
+	return nil
+}
+
+func (s *service) SetCategories(ctx context.Context, uuid string, categoryUUIDs []string) (*ProductInfo, error) {
+	product, err := s.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	categories := make([]*category.Category, 0, len(categoryUUIDs))
+	unique := make([]string, 0, len(categoryUUIDs))
+	seen := map[string]bool{}
+	for _, categoryUUID := range categoryUUIDs {
+		if seen[categoryUUID] {
+			continue
+		}
+		seen[categoryUUID] = true
+		c, err := s.categoryRepo.FindByUUID(ctx, categoryUUID)
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			return nil, category.NewCategoryNotFoundError(categoryUUID)
+		}
+		categories = append(categories, c)
+		unique = append(unique, categoryUUID)
+	}
+	if err := product.Attributes.checkSchemas(categories); err != nil {
+		return nil, err
+	}
+
+	if err := s.categoryRepo.SetProductCategories(ctx, uuid, unique); err != nil {
+		return nil, err
+	}
+	return s.FindByUUID(ctx, uuid)
+}
+
+func (s *service) Restore(ctx context.Context, uuid string) (*ProductInfo, error) {
+	restored, err := s.repo.Restore(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, &ProductNotFoundError{id: uuid}
+	}
+	product, err := s.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	s.recordAudit(ctx, AuditActionRestore, product.Product, product.Product)
+	return product, nil
+}
+
+func (s *service) PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error) {
+	purged, err := s.repo.Purge(ctx, retention)
+	if err != nil {
+		return 0, err
+	}
+	// The image rows are gone with the products, so leftover files are only logged.
+	if s.imageStorage != nil {
+		for _, uuid := range purged {
+			if err := s.imageStorage.Delete(ctx, productImagesPrefix(uuid)); err != nil {
+				log.Error().Err(err).Msg(fmt.Sprintf("Fail to delete images of purged product id=%s", uuid))
+			}
+		}
+	}
+	return int64(len(purged)), nil
+}
+
+func (s *service) History(ctx context.Context, uuid string, page int) ([]*AuditEntry, error) {
+	if page < 1 {
+		page = 1
+	}
+	entries, err := s.auditRepo.ListByProduct(ctx, uuid, (page-1)*defaultHistoryPageSize, defaultHistoryPageSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 && page == 1 {
+		// Products created before auditing started have no entries but still exist.
+		if _, err := s.FindByUUID(ctx, uuid); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func (s *service) PriceHistory(ctx context.Context, uuid string, page int) ([]*PriceChange, error) {
+	if page < 1 {
+		page = 1
+	}
+	changes, err := s.priceHistoryRepo.ListByProduct(ctx, uuid, (page-1)*defaultHistoryPageSize, defaultHistoryPageSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 && page == 1 {
+		// An unpriced product has no history but still exists.
+		if _, err := s.FindByUUID(ctx, uuid); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// recordPriceChange stores the new price of after when it differs from before and
+// alerts the subscribers of the product to a large enough drop. Like auditing, failures are only logged
+// because the product has already been written.
+func (s *service) recordPriceChange(ctx context.Context, before *Product, after *Product) {
+	var oldPrice *money.Money
+	if before != nil {
+		oldPrice = before.Price
+	}
+	if priceEqual(oldPrice, after.Price) {
+		return
+	}
+
+	change := &PriceChange{
+		ProductUUID: after.UUID,
+		Price:       after.Price,
+		ChangedAt:   time.Now().UTC(),
+	}
+	if err := s.priceHistoryRepo.Create(ctx, change); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to record price change of product %s", after.UUID))
+	}
+
+	if isPriceDrop(oldPrice, after.Price, s.priceDropAlertPercent) {
+		s.alertPriceDrop(ctx, *oldPrice, after)
+	}
+}
+
+// recordAudit stores the change from before to after. The change itself has
+// already been written, so a failure to audit it is logged rather than returned.
+func (s *service) recordAudit(ctx context.Context, action AuditAction, before *Product, after *Product) {
+	snapshot := *after
+	entry := &AuditEntry{
+		ProductUUID: after.UUID,
+		Revision:    after.Version,
+		Action:      action,
+		Actor:       requestinfo.Actor(ctx),
+		RequestID:   requestinfo.RequestID(ctx),
+		Changes:     diffProducts(before, after),
+		Snapshot:    &snapshot,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to record %s of product %s revision %d", action, after.UUID, after.Version))
+	}
+}
+
+func (s *service) Revert(ctx context.Context, uuid string, revision int, version int) (*ProductInfo, error) {
+	entry, err := s.auditRepo.FindByRevision(ctx, uuid, revision)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil || entry.Snapshot == nil {
+		return nil, NewRevisionNotFoundError(uuid, revision)
+	}
+
+	product := &Product{
+		UUID:       uuid,
+		Name:       entry.Snapshot.Name,
+		Brand:      entry.Snapshot.Brand,
+		Stock:      entry.Snapshot.Stock,
+		Price:      entry.Snapshot.Price,
+		Attributes: entry.Snapshot.Attributes,
+		Version:    version,
+	}
+	if err := s.update(ctx, product, AuditActionRevert); err != nil {
+		return nil, err
+	}
+	return s.FindByUUID(ctx, uuid)
 }
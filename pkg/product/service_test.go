@@ -1,8 +1,18 @@
 package product
 
 import (
+	"context"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/brand"
+	"coding-challenge-go/pkg/money"
+	"coding-challenge-go/pkg/seller"
 )
 
 func Test_generateSellerInfo(t *testing.T) {
@@ -52,3 +62,447 @@ func Test_generateSellerInfo(t *testing.T) {
 		})
 	}
 }
+
+func Test_servicePatch(t *testing.T) {
+	name := "Berlin S.O.L.I.D. T-Shirt"
+	stock := 0
+	sameStock := 10
+
+	tests := []struct {
+		name          string
+		patch         *Patch
+		want          *Product
+		wantNotifying bool
+	}{
+		{
+			name:  "test patch name does not notify",
+			patch: &Patch{Name: &name},
+			want:  &Product{UUID: "p1", Name: name, Brand: "ShirtsCo", Stock: 10, SellerUUID: "s1", Version: 2, AvailableStock: 10},
+		},
+		{
+			name:          "test patch stock notifies",
+			patch:         &Patch{Stock: &stock},
+			want:          &Product{UUID: "p1", Name: "Berlin New Shirt", Brand: "ShirtsCo", Stock: 0, SellerUUID: "s1", Version: 2, AvailableStock: 0},
+			wantNotifying: true,
+		},
+		{
+			name:  "test patch same stock does not notify",
+			patch: &Patch{Stock: &sameStock},
+			want:  &Product{UUID: "p1", Name: "Berlin New Shirt", Brand: "ShirtsCo", Stock: 10, SellerUUID: "s1", Version: 2, AvailableStock: 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repositoryMock{products: map[string]*Product{
+				"p1": {UUID: "p1", Name: "Berlin New Shirt", Brand: "ShirtsCo", Stock: 10, AvailableStock: 10, SellerUUID: "s1", Version: 1},
+			}}
+			noti := &notiProviderMock{}
+			svc := NewService(repo, &sellerRepositoryMock{}, noti)
+
+			got, err := svc.Patch(context.Background(), "p1", 0, tt.patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got.Product)
+			assert.Equal(t, tt.want.Stock, repo.products["p1"].Stock)
+			assert.Equal(t, tt.wantNotifying, noti.calls == 1)
+		})
+	}
+
+	_, err := NewService(&repositoryMock{}, &sellerRepositoryMock{}, &notiProviderMock{}).Patch(context.Background(), "p2", 0, &Patch{})
+	assert.Equal(t, NewProductNotFoundError("p2"), err)
+}
+
+func Test_servicePrice(t *testing.T) {
+	amount := int64(999)
+	newRepo := func() *repositoryMock {
+		return &repositoryMock{products: map[string]*Product{
+			"p1": {UUID: "p1", Name: "Berlin New Shirt", Brand: "Berlin", SellerUUID: "s1", Version: 1, Price: &money.Money{Amount: 1999, Currency: "EUR"}},
+			"p2": {UUID: "p2", Name: "Plano Tee", Brand: "Plano", SellerUUID: "s1", Version: 1},
+		}}
+	}
+
+	t.Run("test create rejects unknown currency", func(t *testing.T) {
+		svc := NewService(newRepo(), &sellerRepositoryMock{}, &notiProviderMock{})
+		_, err := svc.Create(context.Background(), &Product{UUID: "p3", Name: "Linen Shirt", Brand: "Plano", SellerUUID: "s1", Price: &money.Money{Amount: 100, Currency: "XYZ"}})
+		assert.Equal(t, NewInvalidPriceError(`currency "XYZ" is not a supported ISO-4217 code`), err)
+	})
+
+	t.Run("test update without price keeps stored price", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{})
+		if err := svc.Update(context.Background(), &Product{UUID: "p1", Name: "Renamed", Brand: "Plano"}); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, &money.Money{Amount: 1999, Currency: "EUR"}, repo.products["p1"].Price)
+	})
+
+	t.Run("test patch amount on unpriced product needs a currency", func(t *testing.T) {
+		svc := NewService(newRepo(), &sellerRepositoryMock{}, &notiProviderMock{})
+		_, err := svc.Patch(context.Background(), "p2", 0, &Patch{Price: &PricePatch{Amount: &amount}})
+		assert.IsType(t, &InvalidPriceError{}, err)
+	})
+}
+
+func Test_serviceVersionCheck(t *testing.T) {
+	stock := 3
+	newRepo := func() *repositoryMock {
+		return &repositoryMock{products: map[string]*Product{
+			"p1": {UUID: "p1", Name: "Berlin New Shirt", Brand: "ShirtsCo", Stock: 10, SellerUUID: "s1", Version: 4},
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		call    func(svc Service) error
+		wantErr error
+	}{
+		{
+			name: "test update with current version",
+			call: func(svc Service) error {
+				return svc.Update(context.Background(), &Product{UUID: "p1", Name: "n", Brand: "b", Stock: 10, Version: 4})
+			},
+		},
+		{
+			name: "test update with stale version",
+			call: func(svc Service) error {
+				return svc.Update(context.Background(), &Product{UUID: "p1", Name: "n", Brand: "b", Stock: 10, Version: 3})
+			},
+			wantErr: NewPreconditionFailedError("p1"),
+		},
+		{
+			name: "test patch with stale version",
+			call: func(svc Service) error {
+				_, err := svc.Patch(context.Background(), "p1", 2, &Patch{Stock: &stock})
+				return err
+			},
+			wantErr: NewPreconditionFailedError("p1"),
+		},
+		{
+			name: "test delete with stale version",
+			call: func(svc Service) error {
+				return svc.Delete(context.Background(), "p1", 5)
+			},
+			wantErr: NewPreconditionFailedError("p1"),
+		},
+		{
+			name: "test delete without precondition",
+			call: func(svc Service) error {
+				return svc.Delete(context.Background(), "p1", 0)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			noti := &notiProviderMock{}
+			err := tt.call(NewService(newRepo(), &sellerRepositoryMock{}, noti))
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, 0, noti.calls)
+		})
+	}
+}
+
+func Test_serviceAdjustStock(t *testing.T) {
+	tests := []struct {
+		name            string
+		adjustment      *StockAdjustment
+		allowBackorders bool
+		wantStock       int
+		wantErr         error
+	}{
+		{
+			name:       "test decrement stock",
+			adjustment: &StockAdjustment{Delta: -3, Reason: "order"},
+			wantStock:  7,
+		},
+		{
+			name:       "test decrement below zero",
+			adjustment: &StockAdjustment{Delta: -11, Reason: "order"},
+			wantErr:    NewInsufficientStockError("p1", 10, -11),
+		},
+		{
+			name:       "test decrement into reserved units",
+			adjustment: &StockAdjustment{Delta: -7, Reason: "order"},
+			wantErr:    NewHeldStockError("p1", 6, -7),
+		},
+		{
+			name:            "test decrement below zero with backorders",
+			adjustment:      &StockAdjustment{Delta: -11, Reason: "order"},
+			allowBackorders: true,
+			wantStock:       -1,
+		},
+		{
+			name:       "test zero delta",
+			adjustment: &StockAdjustment{Delta: 0, Reason: "order"},
+			wantErr:    NewInvalidStockAdjustmentError("delta must not be zero"),
+		},
+		{
+			name:       "test missing reason",
+			adjustment: &StockAdjustment{Delta: 1},
+			wantErr:    NewInvalidStockAdjustmentError("reason is required"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repositoryMock{products: map[string]*Product{
+				"p1": {UUID: "p1", Name: "Berlin New Shirt", Brand: "ShirtsCo", Stock: 10, SellerUUID: "s1", Version: 1},
+			}, held: map[string]int{"p1": 4}}
+			noti := &notiProviderMock{}
+			svc := NewService(repo, &sellerRepositoryMock{}, noti, WithBackorders(tt.allowBackorders))
+
+			got, err := svc.AdjustStock(context.Background(), "p1", tt.adjustment)
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr != nil {
+				assert.Equal(t, 0, noti.calls)
+				return
+			}
+			assert.Equal(t, tt.wantStock, got.Stock)
+			assert.Equal(t, 1, noti.calls)
+		})
+	}
+}
+
+func Test_serviceDeleteAndRestore(t *testing.T) {
+	repo := &repositoryMock{products: map[string]*Product{
+		"p1": {UUID: "p1", Name: "Berlin New Shirt", Brand: "ShirtsCo", Stock: 10, SellerUUID: "s1", Version: 1},
+	}}
+	svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{})
+
+	assert.NoError(t, svc.Delete(context.Background(), "p1", 1))
+	_, err := svc.FindByUUID(context.Background(), "p1")
+	assert.Equal(t, NewProductNotFoundError("p1"), err)
+
+	restored, err := svc.Restore(context.Background(), "p1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Berlin New Shirt", restored.Name)
+	assert.Equal(t, 2, restored.Version)
+
+	_, err = svc.Restore(context.Background(), "p1")
+	assert.Equal(t, NewProductNotFoundError("p1"), err)
+}
+
+type repositoryMock struct {
+	products map[string]*Product
+	deleted  map[string]*Product
+	// movements is the stock ledger, which the stock writes of every repository mock append to.
+	movements []*StockMovement
+	// held is the units held by active reservations per product.
+	held map[string]int
+}
+
+// writeStockMovement appends movement like the repositories do within their transaction.
+func (m *repositoryMock) writeStockMovement(movement *StockMovement, product *Product, delta int) {
+	if movement == nil {
+		return
+	}
+	movement.ProductUUID = product.UUID
+	movement.Delta = delta
+	movement.StockAfter = product.Stock
+	if delta != 0 {
+		cp := *movement
+		m.movements = append(m.movements, &cp)
+	}
+}
+
+func (m *repositoryMock) List(ctx context.Context, params *FilterParams, offset int, limit int) ([]*Product, error) {
+	return nil, nil
+}
+
+// Iterate yields the live products of params.SellerUUID ordered by UUID. Like the
+// real repository it reuses one Product for every row.
+func (m *repositoryMock) Iterate(ctx context.Context, params *FilterParams, fn func(product *Product) error) error {
+	uuids := make([]string, 0, len(m.products))
+	for uuid, p := range m.products {
+		if (params.SellerUUID == "" || p.SellerUUID == params.SellerUUID) &&
+			strings.HasPrefix(brand.Normalize(p.Name), params.NamePrefix) {
+			uuids = append(uuids, uuid)
+		}
+	}
+	sort.Strings(uuids)
+	product := &Product{}
+	for _, uuid := range uuids {
+		*product = *m.products[uuid]
+		if err := fn(product); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *repositoryMock) FindByUUID(ctx context.Context, uuid string) (*Product, error) {
+	p, ok := m.products[uuid]
+	if !ok {
+		return nil, nil
+	}
+	cp := *p
+	return &cp, nil
+}
+
+func (m *repositoryMock) FindBySKU(ctx context.Context, sellerUUID string, sku string) (*Product, error) {
+	return m.find(func(p *Product) bool { return p.SellerUUID == sellerUUID && p.SKU == sku }), nil
+}
+
+func (m *repositoryMock) FindByGTIN(ctx context.Context, gtin string) (*Product, error) {
+	return m.find(func(p *Product) bool { return p.GTIN != "" && normalizeGTIN(p.GTIN) == gtin }), nil
+}
+
+// find returns a copy of the first live or deleted product matching fn.
+func (m *repositoryMock) find(fn func(p *Product) bool) *Product {
+	for _, products := range []map[string]*Product{m.products, m.deleted} {
+		for _, p := range products {
+			if fn(p) {
+				cp := *p
+				return &cp
+			}
+		}
+	}
+	return nil
+}
+
+func (m *repositoryMock) Update(ctx context.Context, product *Product, movement *StockMovement) error {
+	p, ok := m.products[product.UUID]
+	if !ok || p.Version != product.Version {
+		return NewPreconditionFailedError(product.UUID)
+	}
+	if held := m.held[product.UUID]; product.Stock < p.Stock && product.Stock < held {
+		return NewHeldStockError(product.UUID, p.Stock-held, product.Stock-p.Stock)
+	}
+	m.writeStockMovement(movement, product, product.Stock-p.Stock)
+	product.Version++
+	cp := *product
+	m.products[product.UUID] = &cp
+	return nil
+}
+
+func (m *repositoryMock) UpdateStatus(ctx context.Context, product *Product) error {
+	return m.Update(ctx, product, nil)
+}
+
+func (m *repositoryMock) Create(ctx context.Context, product *Product, movement *StockMovement) error {
+	m.writeStockMovement(movement, product, product.Stock)
+	product.Version = 1
+	cp := *product
+	m.products[product.UUID] = &cp
+	return nil
+}
+
+func (m *repositoryMock) Delete(ctx context.Context, product *Product) error {
+	if m.deleted == nil {
+		m.deleted = map[string]*Product{}
+	}
+	deletedAt := time.Now().UTC()
+	product.DeletedAt = &deletedAt
+	product.Version++
+	m.deleted[product.UUID] = m.products[product.UUID]
+	delete(m.products, product.UUID)
+	return nil
+}
+
+func (m *repositoryMock) AdjustStock(ctx context.Context, uuid string, delta int, allowNegative bool, reservation string, movement *StockMovement) (*Product, error) {
+	p, ok := m.products[uuid]
+	if !ok {
+		return nil, NewProductNotFoundError(uuid)
+	}
+	if !allowNegative && p.Stock+delta < 0 {
+		return nil, NewInsufficientStockError(uuid, p.Stock, delta)
+	}
+	if held := m.held[uuid]; !allowNegative && delta < 0 && p.Stock+delta < held {
+		return nil, NewHeldStockError(uuid, p.Stock-held, delta)
+	}
+	p.Stock += delta
+	p.Version++
+	m.writeStockMovement(movement, p, delta)
+	cp := *p
+	return &cp, nil
+}
+
+func (m *repositoryMock) AdjustStockBatch(ctx context.Context, deltas []*StockDelta, allowNegative bool, movement *StockMovement) ([]*Product, error) {
+	for _, d := range deltas {
+		p, ok := m.products[d.ProductUUID]
+		if !ok {
+			return nil, NewProductNotFoundError(d.ProductUUID)
+		}
+		if !allowNegative && p.Stock+d.Delta < 0 {
+			return nil, NewInsufficientStockError(d.ProductUUID, p.Stock, d.Delta)
+		}
+		if held := m.held[d.ProductUUID]; !allowNegative && d.Delta < 0 && p.Stock+d.Delta < held {
+			return nil, NewHeldStockError(d.ProductUUID, p.Stock-held, d.Delta)
+		}
+	}
+	var products []*Product
+	for _, d := range deltas {
+		cp := *movement
+		p, _ := m.AdjustStock(ctx, d.ProductUUID, d.Delta, allowNegative, "", &cp)
+		products = append(products, p)
+	}
+	return products, nil
+}
+
+func (m *repositoryMock) Restore(ctx context.Context, uuid string) (bool, error) {
+	p, ok := m.deleted[uuid]
+	if !ok {
+		return false, nil
+	}
+	delete(m.deleted, uuid)
+	p.Version++
+	m.products[uuid] = p
+	return true, nil
+}
+
+func (m *repositoryMock) Purge(ctx context.Context, retention time.Duration) ([]string, error) {
+	var purged []string
+	for uuid := range m.deleted {
+		purged = append(purged, uuid)
+	}
+	m.deleted = map[string]*Product{}
+	return purged, nil
+}
+
+type sellerRepositoryMock struct{}
+
+func (m *sellerRepositoryMock) List(ctx context.Context) ([]*seller.Seller, error) {
+	return nil, nil
+}
+
+func (m *sellerRepositoryMock) FindByUUID(ctx context.Context, uuid string) (*seller.Seller, error) {
+	return &seller.Seller{UUID: uuid}, nil
+}
+
+func (m *sellerRepositoryMock) TopByProduct(ctx context.Context, limit int) ([]*seller.Seller, error) {
+	return nil, nil
+}
+
+type notiProviderMock struct {
+	calls       int
+	lastProduct string
+	priceDrops  int
+	summaries   map[string][]seller.StockChange
+	lowStock    []string
+	// subscribers are the ones told about the last price drop.
+	subscribers []*seller.Subscriber
+}
+
+func (m *notiProviderMock) StockChanged(oldStock int, newStock int, product string, sl *seller.Seller) {
+	m.calls++
+	m.lastProduct = product
+}
+
+func (m *notiProviderMock) StockSummary(changes []seller.StockChange, sl *seller.Seller) {
+	if m.summaries == nil {
+		m.summaries = map[string][]seller.StockChange{}
+	}
+	m.summaries[sl.UUID] = append(m.summaries[sl.UUID], changes...)
+}
+
+func (m *notiProviderMock) PriceDropped(oldPrice money.Money, newPrice money.Money, product string, subscribers []*seller.Subscriber) {
+	m.priceDrops++
+	m.subscribers = subscribers
+}
+
+func (m *notiProviderMock) StockLow(stock int, product string, sl *seller.Seller) {
+	m.lowStock = append(m.lowStock, product)
+}
+
+func (m *notiProviderMock) Type() seller.ProviderType {
+	return seller.Email
+}
@@ -0,0 +1,18 @@
+package product
+
+// Sort is the order of a product list.
+type Sort string
+
+const (
+	SortPriceAsc  Sort = "price"
+	SortPriceDesc Sort = "-price"
+)
+
+// ParseSort returns the Sort named by s. An empty s keeps the default order.
+func ParseSort(s string) (Sort, error) {
+	switch Sort(s) {
+	case "", SortPriceAsc, SortPriceDesc:
+		return Sort(s), nil
+	}
+	return "", NewInvalidFilterError("sort", "must be price or -price")
+}
@@ -0,0 +1,121 @@
+package product
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Status is the lifecycle state of a product. Only published products are shown
+// to shoppers, while drafts are still being prepared and archived products are
+// no longer sold.
+type Status string
+
+const (
+	StatusDraft     Status = "draft"
+	StatusPublished Status = "published"
+	StatusArchived  Status = "archived"
+)
+
+// statusTransitions lists the statuses a product may move to from each status.
+var statusTransitions = map[Status][]Status{
+	StatusDraft:     {StatusPublished, StatusArchived},
+	StatusPublished: {StatusArchived},
+	StatusArchived:  {StatusPublished},
+}
+
+func ParseStatus(s string) (Status, error) {
+	switch status := Status(s); status {
+	case StatusDraft, StatusPublished, StatusArchived:
+		return status, nil
+	}
+	return "", NewInvalidStatusError(s)
+}
+
+// ParseStatuses reads a comma separated list of statuses, e.g. "draft,published".
+func ParseStatuses(s string) ([]Status, error) {
+	var statuses []Status
+	for _, part := range strings.Split(s, ",") {
+		status, err := ParseStatus(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (s Status) canMoveTo(to Status) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// setStatus moves product to status and stamps when it was published or archived.
+func (p *Product) setStatus(status Status, now time.Time) {
+	p.Status = status
+	switch status {
+	case StatusPublished:
+		p.PublishedAt = &now
+		p.ArchivedAt = nil
+	case StatusArchived:
+		p.ArchivedAt = &now
+	}
+}
+
+// notifiesStock reports whether stock changes of product are sent to its seller.
+// Drafts are not on sale yet, so their stock is still being set up.
+func notifiesStock(product *Product) bool {
+	return product.Status != StatusDraft
+}
+
+func (s *service) SetStatus(ctx context.Context, uuid string, status Status, version int) (*ProductInfo, error) {
+	product, err := s.repo.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, &ProductNotFoundError{id: uuid}
+	}
+	if err := checkVersion(product, version); err != nil {
+		return nil, err
+	}
+
+	if product.Status != status {
+		if !product.Status.canMoveTo(status) {
+			return nil, NewStatusTransitionError(uuid, product.Status, status)
+		}
+		before := *product
+		product.setStatus(status, time.Now().UTC())
+		if err := s.repo.UpdateStatus(ctx, product); err != nil {
+			return nil, err
+		}
+		s.recordAudit(ctx, AuditActionStatusChange, &before, product)
+	}
+
+	info := &ProductInfo{
+		Product: product,
+		Seller:  generateSellerInfo(product.SellerUUID),
+	}
+	if err := s.attachRelations(ctx, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// initStatus checks the status a product is created with and defaults it to
+// published, which is what clients that do not know statuses expect.
+func initStatus(product *Product, now time.Time) error {
+	switch product.Status {
+	case "", StatusPublished:
+		product.setStatus(StatusPublished, now)
+	case StatusDraft:
+		product.PublishedAt, product.ArchivedAt = nil, nil
+	default:
+		return NewStatusTransitionError(product.UUID, "", product.Status)
+	}
+	return nil
+}
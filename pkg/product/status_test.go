@@ -0,0 +1,126 @@
+package product
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_serviceSetStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    Status
+		to      Status
+		wantErr error
+	}{
+		{name: "test publish draft", from: StatusDraft, to: StatusPublished},
+		{name: "test archive draft", from: StatusDraft, to: StatusArchived},
+		{name: "test archive published", from: StatusPublished, to: StatusArchived},
+		{name: "test republish archived", from: StatusArchived, to: StatusPublished},
+		{name: "test same status", from: StatusPublished, to: StatusPublished},
+		{
+			name:    "test published back to draft",
+			from:    StatusPublished,
+			to:      StatusDraft,
+			wantErr: NewStatusTransitionError("p1", StatusPublished, StatusDraft),
+		},
+		{
+			name:    "test archived back to draft",
+			from:    StatusArchived,
+			to:      StatusDraft,
+			wantErr: NewStatusTransitionError("p1", StatusArchived, StatusDraft),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repositoryMock{products: map[string]*Product{
+				"p1": {UUID: "p1", Name: "Berlin New Shirt", SellerUUID: "s1", Version: 1, Status: tt.from},
+			}}
+			svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{})
+
+			got, err := svc.SetStatus(context.Background(), "p1", tt.to, 1)
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.from, repo.products["p1"].Status)
+				return
+			}
+			assert.Equal(t, tt.to, got.Status)
+			assert.Equal(t, tt.to, repo.products["p1"].Status)
+		})
+	}
+}
+
+func Test_serviceSetStatusTimestamps(t *testing.T) {
+	repo := &repositoryMock{products: map[string]*Product{
+		"p1": {UUID: "p1", Name: "Berlin New Shirt", SellerUUID: "s1", Version: 1, Status: StatusDraft},
+	}}
+	auditRepo := &auditRepositoryMock{}
+	svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{}, WithAuditRepository(auditRepo))
+
+	published, err := svc.SetStatus(context.Background(), "p1", StatusPublished, 0)
+	assert.NoError(t, err)
+	assert.NotNil(t, published.PublishedAt)
+	assert.Nil(t, published.ArchivedAt)
+	assert.Equal(t, 2, published.Version)
+
+	archived, err := svc.SetStatus(context.Background(), "p1", StatusArchived, 2)
+	assert.NoError(t, err)
+	assert.NotNil(t, archived.PublishedAt)
+	assert.NotNil(t, archived.ArchivedAt)
+
+	_, err = svc.SetStatus(context.Background(), "p1", StatusPublished, 2)
+	assert.Equal(t, NewPreconditionFailedError("p1"), err)
+
+	if assert.Len(t, auditRepo.entries, 2) {
+		assert.Equal(t, AuditActionStatusChange, auditRepo.entries[0].Action)
+		assert.Equal(t, []*FieldChange{{Field: "status", From: "draft", To: "published"}}, auditRepo.entries[0].Changes)
+	}
+}
+
+func Test_serviceCreateStatus(t *testing.T) {
+	repo := &repositoryMock{products: map[string]*Product{}}
+	svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{})
+
+	_, err := svc.Create(context.Background(), &Product{UUID: "p1", Name: "Berlin New Shirt", Brand: "Plano", SellerUUID: "s1"})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusPublished, repo.products["p1"].Status)
+	assert.NotNil(t, repo.products["p1"].PublishedAt)
+
+	_, err = svc.Create(context.Background(), &Product{UUID: "p2", Name: "Plano Tee", Brand: "Plano", SellerUUID: "s1", Status: StatusDraft})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusDraft, repo.products["p2"].Status)
+	assert.Nil(t, repo.products["p2"].PublishedAt)
+
+	_, err = svc.Create(context.Background(), &Product{UUID: "p3", Name: "Linen Shirt", Brand: "Plano", SellerUUID: "s1", Status: StatusArchived})
+	assert.Equal(t, NewStatusTransitionError("p3", "", StatusArchived), err)
+}
+
+func Test_serviceDraftStockIsNotNotified(t *testing.T) {
+	repo := &repositoryMock{products: map[string]*Product{
+		"p1": {UUID: "p1", Name: "Berlin New Shirt", SellerUUID: "s1", Stock: 10, Version: 1, Status: StatusDraft},
+	}}
+	noti := &notiProviderMock{}
+	svc := NewService(repo, &sellerRepositoryMock{}, noti)
+
+	_, err := svc.AdjustStock(context.Background(), "p1", &StockAdjustment{Delta: -2, Reason: "count"})
+	assert.NoError(t, err)
+	assert.NoError(t, svc.Update(context.Background(), &Product{UUID: "p1", Name: "Berlin New Shirt", Brand: "Plano", Stock: 5}))
+	assert.Equal(t, 0, noti.calls)
+	assert.Equal(t, StatusDraft, repo.products["p1"].Status)
+
+	_, err = svc.SetStatus(context.Background(), "p1", StatusPublished, 0)
+	assert.NoError(t, err)
+	_, err = svc.AdjustStock(context.Background(), "p1", &StockAdjustment{Delta: 1, Reason: "count"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, noti.calls)
+}
+
+func Test_ParseStatuses(t *testing.T) {
+	statuses, err := ParseStatuses("draft, archived")
+	assert.NoError(t, err)
+	assert.Equal(t, []Status{StatusDraft, StatusArchived}, statuses)
+
+	_, err = ParseStatuses("draft,deleted")
+	assert.Equal(t, NewInvalidStatusError("deleted"), err)
+}
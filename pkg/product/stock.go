@@ -0,0 +1,75 @@
+package product
+
+import (
+	"fmt"
+)
+
+const (
+	maxStockAdjustmentReasonLength = 200
+)
+
+// StockAdjustment is a signed change of stock, e.g. -3 when an order takes three units.
+type StockAdjustment struct {
+	Delta  int
+	Reason string
+	// Code classifies the adjustment in the stock ledger and defaults to a correction.
+	Code StockReason
+	// Reservation, when set, is confirmed in the same transaction as the decrement,
+	// so the units it held cannot be taken by another checkout in between.
+	Reservation string
+}
+
+func (a *StockAdjustment) validate() error {
+	if a.Delta == 0 {
+		return NewInvalidStockAdjustmentError("delta must not be zero")
+	}
+	if a.Reason == "" {
+		return NewInvalidStockAdjustmentError("reason is required")
+	}
+	if len(a.Reason) > maxStockAdjustmentReasonLength {
+		return NewInvalidStockAdjustmentError("reason is too long")
+	}
+	if a.Code == "" {
+		a.Code = StockReasonCorrection
+	}
+	for _, code := range adjustmentReasons {
+		if a.Code == code {
+			return nil
+		}
+	}
+	return NewInvalidStockAdjustmentError(fmt.Sprintf("reason code %q is not supported", a.Code))
+}
+
+type (
+	// StockBatch adjusts the stock of several products for one reason, e.g. after
+	// a warehouse count. Either every delta is applied or none is.
+	StockBatch struct {
+		Deltas []*StockDelta
+		Reason string
+		Code   StockReason
+	}
+
+	StockDelta struct {
+		ProductUUID string
+		Delta       int
+	}
+)
+
+func (b *StockBatch) validate() error {
+	if len(b.Deltas) == 0 {
+		return NewInvalidStockAdjustmentError("batch has no adjustments")
+	}
+	seen := map[string]bool{}
+	for _, d := range b.Deltas {
+		if seen[d.ProductUUID] {
+			return NewInvalidStockAdjustmentError(fmt.Sprintf("product %s is adjusted more than once", d.ProductUUID))
+		}
+		seen[d.ProductUUID] = true
+		adjustment := &StockAdjustment{Delta: d.Delta, Reason: b.Reason, Code: b.Code}
+		if err := adjustment.validate(); err != nil {
+			return err
+		}
+		b.Code = adjustment.Code
+	}
+	return nil
+}
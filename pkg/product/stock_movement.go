@@ -0,0 +1,103 @@
+package product
+
+import (
+	"context"
+	"time"
+
+	"coding-challenge-go/pkg/requestinfo"
+)
+
+// StockReason says why stock moved.
+type StockReason string
+
+const (
+	// StockReasonInitial is the stock a product is created with.
+	StockReasonInitial StockReason = "initial"
+	StockReasonSale    StockReason = "sale"
+	StockReasonReturn  StockReason = "return"
+	StockReasonDamage  StockReason = "damage"
+	StockReasonRestock StockReason = "restock"
+	// StockReasonCorrection is stock set to a counted or known value, e.g. by an
+	// update, and the default of adjustments that give no reason code.
+	StockReasonCorrection StockReason = "correction"
+)
+
+type (
+	// StockMovement is an entry of the append-only stock ledger of a product. The
+	// deltas of a product add up to its stock.
+	StockMovement struct {
+		StockMovementID int         `json:"-"`
+		ProductUUID     string      `json:"product_uuid"`
+		Delta           int         `json:"delta"`
+		Reason          StockReason `json:"reason"`
+		// Reference points to what caused the movement, e.g. an order number.
+		Reference  string    `json:"reference,omitempty"`
+		Actor      string    `json:"actor"`
+		StockAfter int       `json:"stock_after"`
+		CreatedAt  time.Time `json:"created_at"`
+	}
+
+	// StockDiscrepancy is a product whose stock is not the sum of its ledger.
+	StockDiscrepancy struct {
+		ProductUUID string `json:"product_uuid"`
+		Stock       int    `json:"stock"`
+		LedgerStock int    `json:"ledger_stock"`
+	}
+
+	StockMovementRepository interface {
+		// ListByProduct returns the movements of a product, newest first.
+		ListByProduct(ctx context.Context, productUUID string, offset int, limit int) ([]*StockMovement, error)
+		// Discrepancies returns the products, only those of sellerUUID unless it is
+		// empty, whose stock differs from the sum of their movements. Bundles are
+		// left out, since their stock follows from their components.
+		Discrepancies(ctx context.Context, sellerUUID string) ([]*StockDiscrepancy, error)
+	}
+
+	// nopStockMovementRepository is used when the service is built without a stock movement repository.
+	nopStockMovementRepository struct{}
+)
+
+// adjustmentReasons are the reason codes clients may give a stock adjustment.
+var adjustmentReasons = []StockReason{
+	StockReasonSale, StockReasonReturn, StockReasonDamage, StockReasonRestock, StockReasonCorrection,
+}
+
+func (s *service) StockMovements(ctx context.Context, uuid string, page int) ([]*StockMovement, error) {
+	if page < 1 {
+		page = 1
+	}
+	movements, err := s.stockMovementRepo.ListByProduct(ctx, uuid, (page-1)*defaultHistoryPageSize, defaultHistoryPageSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(movements) == 0 && page == 1 {
+		// A product created without stock has no movements but still exists.
+		if _, err := s.FindByUUID(ctx, uuid); err != nil {
+			return nil, err
+		}
+	}
+	return movements, nil
+}
+
+func (s *service) StockDiscrepancies(ctx context.Context, sellerUUID string) ([]*StockDiscrepancy, error) {
+	return s.stockMovementRepo.Discrepancies(ctx, sellerUUID)
+}
+
+// newStockMovement starts the ledger entry of a stock adjustment. The repository
+// completes it and writes it in the transaction of the adjustment.
+func newStockMovement(ctx context.Context, reason StockReason, reference string) *StockMovement {
+	return &StockMovement{
+		Reason:    reason,
+		Reference: reference,
+		Actor:     requestinfo.Actor(ctx),
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+func (nopStockMovementRepository) ListByProduct(ctx context.Context, productUUID string, offset int, limit int) ([]*StockMovement, error) {
+	return []*StockMovement{}, nil
+}
+
+func (nopStockMovementRepository) Discrepancies(ctx context.Context, sellerUUID string) ([]*StockDiscrepancy, error) {
+	return []*StockDiscrepancy{}, nil
+}
@@ -0,0 +1,107 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+)
+
+const (
+	insertStockMovementQuery = "INSERT INTO stock_movement (product_uuid, delta, reason, reference, actor, stock_after, created_at) " +
+		"VALUES(?,?,?,?,?,?,?)"
+)
+
+func NewStockMovementRepository(db *sql.DB) StockMovementRepository {
+	return &stockMovementRepository{db: db}
+}
+
+type stockMovementRepository struct {
+	db *sql.DB
+}
+
+// writeStockMovement records within tx that the stock of product changed by
+// delta, so the ledger entry commits or rolls back with the stock it describes.
+// movement carries the reason, reference, actor and time, and gets the product,
+// delta and stock after filled in. Nothing is written for a zero delta or a nil
+// movement, which writes that cannot change the total, like moves, pass.
+func writeStockMovement(ctx context.Context, tx *sql.Tx, movement *StockMovement, product *Product, delta int) error {
+	if movement == nil {
+		return nil
+	}
+	movement.ProductUUID = product.UUID
+	movement.Delta = delta
+	movement.StockAfter = product.Stock
+	if delta == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(
+		ctx,
+		insertStockMovementQuery,
+		movement.ProductUUID, movement.Delta, movement.Reason, nullString(movement.Reference), movement.Actor,
+		movement.StockAfter, movement.CreatedAt,
+	)
+
+	return err
+}
+
+func (r *stockMovementRepository) ListByProduct(ctx context.Context, productUUID string, offset int, limit int) ([]*StockMovement, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT id_stock_movement, product_uuid, delta, reason, COALESCE(reference, ''), actor, stock_after, created_at FROM stock_movement "+
+			"WHERE product_uuid = ? ORDER BY id_stock_movement DESC LIMIT ? OFFSET ?",
+		productUUID, limit, offset,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	movements := []*StockMovement{}
+
+	for rows.Next() {
+		movement := &StockMovement{}
+
+		err := rows.Scan(
+			&movement.StockMovementID, &movement.ProductUUID, &movement.Delta, &movement.Reason, &movement.Reference,
+			&movement.Actor, &movement.StockAfter, &movement.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, rows.Err()
+}
+
+func (r *stockMovementRepository) Discrepancies(ctx context.Context, sellerUUID string) ([]*StockDiscrepancy, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT p.uuid, p.stock, COALESCE(m.total, 0) FROM product p "+
+			"INNER JOIN seller s ON(s.id_seller = p.fk_seller) "+
+			"LEFT JOIN (SELECT product_uuid, SUM(delta) AS total FROM stock_movement GROUP BY product_uuid) m ON(m.product_uuid = p.uuid) "+
+			"WHERE (? = '' OR s.uuid = ?) AND p.stock <> COALESCE(m.total, 0) "+
+			"AND NOT EXISTS (SELECT 1 FROM product_bundle_component bc WHERE bc.fk_bundle = p.id_product) ORDER BY p.id_product",
+		sellerUUID, sellerUUID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	discrepancies := []*StockDiscrepancy{}
+
+	for rows.Next() {
+		discrepancy := &StockDiscrepancy{}
+		if err := rows.Scan(&discrepancy.ProductUUID, &discrepancy.Stock, &discrepancy.LedgerStock); err != nil {
+			return nil, err
+		}
+		discrepancies = append(discrepancies, discrepancy)
+	}
+
+	return discrepancies, rows.Err()
+}
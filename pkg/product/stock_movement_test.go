@@ -0,0 +1,108 @@
+package product
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/requestinfo"
+)
+
+func Test_serviceStockMovements(t *testing.T) {
+	repo := &repositoryMock{products: map[string]*Product{}}
+	movementRepo := &stockMovementRepositoryMock{repo: repo}
+	svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{}, WithStockMovementRepository(movementRepo))
+	ctx := requestinfo.WithActor(context.Background(), "merch@gfg.com")
+
+	_, err := svc.Create(ctx, &Product{UUID: "p1", Name: "Plano Tee", Brand: "Plano", SellerUUID: "s1", Stock: 10})
+	assert.NoError(t, err)
+	_, err = svc.Create(ctx, &Product{UUID: "p2", Name: "Plano Cap", Brand: "Plano", SellerUUID: "s1"})
+	assert.NoError(t, err)
+
+	_, err = svc.AdjustStock(ctx, "p1", &StockAdjustment{Delta: -3, Reason: "order 1001", Code: StockReasonSale})
+	assert.NoError(t, err)
+	_, err = svc.AdjustStock(ctx, "p1", &StockAdjustment{Delta: 1, Reason: "order 1001"})
+	assert.NoError(t, err)
+
+	name := "Plano Tee v2"
+	_, err = svc.Patch(ctx, "p1", 0, &Patch{Name: &name})
+	assert.NoError(t, err)
+	stock := 20
+	_, err = svc.Patch(ctx, "p1", 0, &Patch{Stock: &stock})
+	assert.NoError(t, err)
+
+	movements, err := svc.StockMovements(ctx, "p1", 1)
+	assert.NoError(t, err)
+	got := []StockMovement{}
+	for _, movement := range movements {
+		got = append(got, StockMovement{Delta: movement.Delta, Reason: movement.Reason, Reference: movement.Reference, Actor: movement.Actor, StockAfter: movement.StockAfter})
+	}
+	assert.Equal(t, []StockMovement{
+		{Delta: 12, Reason: StockReasonCorrection, Reference: "update", Actor: "merch@gfg.com", StockAfter: 20},
+		{Delta: 1, Reason: StockReasonCorrection, Reference: "order 1001", Actor: "merch@gfg.com", StockAfter: 8},
+		{Delta: -3, Reason: StockReasonSale, Reference: "order 1001", Actor: "merch@gfg.com", StockAfter: 7},
+		{Delta: 10, Reason: StockReasonInitial, Actor: "merch@gfg.com", StockAfter: 10},
+	}, got)
+
+	// A product without stock changes has an empty ledger, an unknown one has none.
+	movements, err = svc.StockMovements(ctx, "p2", 1)
+	assert.NoError(t, err)
+	assert.Empty(t, movements)
+	_, err = svc.StockMovements(ctx, "p3", 1)
+	assert.Equal(t, NewProductNotFoundError("p3"), err)
+
+	discrepancies, err := svc.StockDiscrepancies(ctx, "")
+	assert.NoError(t, err)
+	assert.Empty(t, discrepancies)
+
+	// Stock written behind the service's back shows up in the consistency check.
+	repo.products["p1"].Stock = 18
+	discrepancies, err = svc.StockDiscrepancies(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []*StockDiscrepancy{{ProductUUID: "p1", Stock: 18, LedgerStock: 20}}, discrepancies)
+}
+
+func Test_StockAdjustmentValidate(t *testing.T) {
+	adjustment := &StockAdjustment{Delta: 2, Reason: "customer returned"}
+	assert.NoError(t, adjustment.validate())
+	assert.Equal(t, StockReasonCorrection, adjustment.Code)
+
+	adjustment = &StockAdjustment{Delta: 2, Reason: "customer returned", Code: StockReasonReturn}
+	assert.NoError(t, adjustment.validate())
+
+	adjustment = &StockAdjustment{Delta: 2, Reason: "customer returned", Code: StockReasonInitial}
+	assert.Equal(t, NewInvalidStockAdjustmentError(`reason code "initial" is not supported`), adjustment.validate())
+}
+
+// stockMovementRepositoryMock keeps the ledger in repo, where the stock writes add to it.
+type stockMovementRepositoryMock struct {
+	repo *repositoryMock
+}
+
+func (m *stockMovementRepositoryMock) ListByProduct(ctx context.Context, productUUID string, offset int, limit int) ([]*StockMovement, error) {
+	movements := []*StockMovement{}
+	for i := len(m.repo.movements) - 1; i >= 0; i-- {
+		if m.repo.movements[i].ProductUUID == productUUID {
+			movements = append(movements, m.repo.movements[i])
+		}
+	}
+	return movements, nil
+}
+
+func (m *stockMovementRepositoryMock) Discrepancies(ctx context.Context, sellerUUID string) ([]*StockDiscrepancy, error) {
+	discrepancies := []*StockDiscrepancy{}
+	err := m.repo.Iterate(ctx, &FilterParams{SellerUUID: sellerUUID}, func(product *Product) error {
+		total := 0
+		for _, movement := range m.repo.movements {
+			if movement.ProductUUID == product.UUID {
+				total += movement.Delta
+			}
+		}
+		if total != product.Stock {
+			discrepancies = append(discrepancies, &StockDiscrepancy{ProductUUID: product.UUID, Stock: product.Stock, LedgerStock: total})
+		}
+		return nil
+	})
+	return discrepancies, err
+}
@@ -0,0 +1,67 @@
+package product
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/seller"
+)
+
+func Test_serviceAdjustStockBatch(t *testing.T) {
+	newRepo := func() *repositoryMock {
+		return &repositoryMock{products: map[string]*Product{
+			"p1": {UUID: "p1", Name: "Berlin New Shirt", SellerUUID: "s1", Stock: 10, Status: StatusPublished, Version: 1},
+			"p2": {UUID: "p2", Name: "Plano Tee", SellerUUID: "s1", Stock: 5, Status: StatusPublished, Version: 1},
+			"p3": {UUID: "p3", Name: "Storm Jacket", SellerUUID: "s2", Stock: 3, Status: StatusPublished, Version: 1},
+			"p4": {UUID: "p4", Name: "Sasha", SellerUUID: "s2", Stock: 1, Status: StatusDraft, Version: 1},
+		}}
+	}
+
+	repo := newRepo()
+	noti := &notiProviderMock{}
+	movementRepo := &stockMovementRepositoryMock{repo: repo}
+	svc := NewService(repo, &sellerRepositoryMock{}, noti, WithStockMovementRepository(movementRepo))
+
+	products, err := svc.AdjustStockBatch(context.Background(), &StockBatch{
+		Deltas: []*StockDelta{{"p1", -2}, {"p2", 3}, {"p3", -1}, {"p4", 4}},
+		Reason: "count 2021-09",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, products, 4)
+	assert.Equal(t, 8, repo.products["p1"].Stock)
+	assert.Equal(t, 5, repo.products["p4"].Stock)
+
+	// One summary per seller and none of them about drafts.
+	assert.Equal(t, 0, noti.calls)
+	assert.Equal(t, map[string][]seller.StockChange{
+		"s1": {{Product: "Berlin New Shirt", OldStock: 10, NewStock: 8}, {Product: "Plano Tee", OldStock: 5, NewStock: 8}},
+		"s2": {{Product: "Storm Jacket", OldStock: 3, NewStock: 2}},
+	}, noti.summaries)
+
+	assert.Len(t, repo.movements, 4)
+	for _, movement := range repo.movements {
+		assert.Equal(t, StockReasonCorrection, movement.Reason)
+		assert.Equal(t, "count 2021-09", movement.Reference)
+	}
+
+	// Nothing is applied when one delta fails.
+	repo = newRepo()
+	svc = NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{})
+	_, err = svc.AdjustStockBatch(context.Background(), &StockBatch{
+		Deltas: []*StockDelta{{"p1", -2}, {"p3", -4}},
+		Reason: "count 2021-09",
+	})
+	assert.Equal(t, NewInsufficientStockError("p3", 3, -4), err)
+	assert.Equal(t, 10, repo.products["p1"].Stock)
+
+	_, err = svc.AdjustStockBatch(context.Background(), &StockBatch{
+		Deltas: []*StockDelta{{"p1", -2}, {"p1", 1}},
+		Reason: "count 2021-09",
+	})
+	assert.Equal(t, NewInvalidStockAdjustmentError("product p1 is adjusted more than once"), err)
+
+	_, err = svc.AdjustStockBatch(context.Background(), &StockBatch{Reason: "count 2021-09"})
+	assert.Equal(t, NewInvalidStockAdjustmentError("batch has no adjustments"), err)
+}
@@ -0,0 +1,85 @@
+package product
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// productFields holds the rules every stored product satisfies. They are the
+// rules of the create and update requests, so a patch, a revert or a scheduled
+// change cannot store a product those requests would reject.
+type productFields struct {
+	Name  string `json:"name" binding:"required,max=200"`
+	Brand string `json:"brand" binding:"required,max=200"`
+	Stock int    `json:"stock" binding:"min=0"`
+	SKU   string `json:"sku" binding:"max=64"`
+	GTIN  string `json:"gtin" binding:"max=14"`
+}
+
+// fieldValidator reports fields by the name clients send.
+var fieldValidator = newFieldValidator()
+
+func newFieldValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	})
+	return v
+}
+
+// validateFields checks product against the field rules. The stock is only
+// checked when it differs from before, since backorders may have taken the
+// stored stock below zero; before is nil for a new product.
+func validateFields(product *Product, before *Product) error {
+	fields := &productFields{
+		Name:  product.Name,
+		Brand: product.Brand,
+		Stock: product.Stock,
+		SKU:   product.SKU,
+		GTIN:  product.GTIN,
+	}
+
+	var err error
+	if before != nil && product.Stock == before.Stock {
+		err = fieldValidator.StructExcept(fields, "Stock")
+	} else {
+		err = fieldValidator.Struct(fields)
+	}
+	return newFieldsError(err)
+}
+
+// Validate checks the fields present in the patch against the field rules, so a
+// scheduled change is rejected when it is created rather than when it is due.
+func (p *Patch) Validate() error {
+	fields := &productFields{}
+	var present []string
+	if p.Name != nil {
+		fields.Name, present = *p.Name, append(present, "Name")
+	}
+	if p.Brand != nil {
+		fields.Brand, present = *p.Brand, append(present, "Brand")
+	}
+	if p.Stock != nil {
+		fields.Stock, present = *p.Stock, append(present, "Stock")
+	}
+	if p.SKU != nil {
+		fields.SKU, present = *p.SKU, append(present, "SKU")
+	}
+	if p.GTIN != nil {
+		fields.GTIN, present = *p.GTIN, append(present, "GTIN")
+	}
+	if len(present) == 0 {
+		return nil
+	}
+	return newFieldsError(fieldValidator.StructPartial(fields, present...))
+}
+
+func newFieldsError(err error) error {
+	if errs, ok := err.(validator.ValidationErrors); ok {
+		return NewInvalidFieldsError(errs)
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+package product
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_serviceFields(t *testing.T) {
+	repo := &repositoryMock{products: map[string]*Product{
+		"p1": {UUID: "p1", Name: "Berlin New Shirt", Brand: "Berlin", Stock: -2, SellerUUID: "s1", Version: 1},
+	}}
+	svc := NewService(repo, &sellerRepositoryMock{}, &notiProviderMock{}, WithBackorders(true))
+	ctx := context.Background()
+
+	brokenRules := func(err error) map[string]string {
+		rules := map[string]string{}
+		if e, ok := err.(*InvalidFieldsError); ok {
+			for _, fe := range e.Fields() {
+				rules[fe.Field()] = fe.Tag()
+			}
+		}
+		return rules
+	}
+
+	stock, empty, long := -5, "", strings.Repeat("x", 201)
+	_, err := svc.Patch(ctx, "p1", 0, &Patch{Stock: &stock, Name: &empty, Brand: &long})
+	assert.Equal(t, map[string]string{"name": "required", "brand": "max", "stock": "min"}, brokenRules(err))
+
+	// A backordered stock stays valid until the stock itself is written.
+	name := "Berlin Shirt"
+	_, err = svc.Patch(ctx, "p1", 0, &Patch{Name: &name})
+	assert.NoError(t, err)
+
+	err = svc.Update(ctx, &Product{UUID: "p1", Name: "Berlin Shirt", Brand: "", Stock: -2})
+	assert.Equal(t, map[string]string{"brand": "required"}, brokenRules(err))
+
+	_, err = svc.Create(ctx, &Product{UUID: "p2", Name: long, Brand: "Plano", SellerUUID: "s1"})
+	assert.Equal(t, map[string]string{"name": "max"}, brokenRules(err))
+}
+
+func Test_PatchValidate(t *testing.T) {
+	stock, empty, name := -1, "", "Berlin Shirt"
+
+	assert.NoError(t, (&Patch{Name: &name}).Validate())
+	assert.NoError(t, (&Patch{Price: &PricePatch{Remove: true}}).Validate())
+
+	err := (&Patch{Stock: &stock, Brand: &empty}).Validate()
+	if assert.IsType(t, &InvalidFieldsError{}, err) {
+		assert.Len(t, err.(*InvalidFieldsError).Fields(), 2)
+	}
+}
@@ -0,0 +1,109 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const maxSKULength = 64
+
+type (
+	// Variant is a sellable version of a product, e.g. one size and color of a
+	// shirt. Once a product has variants its stock is the sum of their stock.
+	Variant struct {
+		VariantID   int               `json:"-"`
+		UUID        string            `json:"uuid"`
+		ProductUUID string            `json:"-"`
+		SKU         string            `json:"sku"`
+		Options     map[string]string `json:"options"`
+		Stock       int               `json:"stock"`
+	}
+
+	VariantRepository interface {
+		// ListByProducts returns the variants of each product, ordered by SKU.
+		ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*Variant, error)
+		// Create inserts the variant and returns the parent with its new total stock.
+		// The change of the total is written as movement in the same transaction.
+		Create(ctx context.Context, variant *Variant, movement *StockMovement) (*Product, error)
+		// AdjustStock adds delta to the variant stock unless it would drop below zero
+		// and allowNegative is false. It returns the variant and the parent with its
+		// new total stock, and records movement like Create.
+		AdjustStock(ctx context.Context, productUUID string, variantUUID string, delta int, allowNegative bool, movement *StockMovement) (*Variant, *Product, error)
+		// Delete removes the variant and returns the parent with its new total stock,
+		// or nil when there was no such variant. It records movement like Create.
+		Delete(ctx context.Context, productUUID string, variantUUID string, movement *StockMovement) (*Product, error)
+	}
+
+	// nopVariantRepository is used when the service is built without a variant repository.
+	nopVariantRepository struct{}
+)
+
+// Label names the variant in notifications, e.g. "Berlin New Shirt (color: blue, size: M)".
+func (v *Variant) Label(productName string) string {
+	axes := v.axes()
+	options := make([]string, len(axes))
+	for i, axis := range axes {
+		options[i] = fmt.Sprintf("%s: %s", axis, v.Options[axis])
+	}
+	return fmt.Sprintf("%s (%s)", productName, strings.Join(options, ", "))
+}
+
+// axes returns the option names of the variant in alphabetical order.
+func (v *Variant) axes() []string {
+	axes := make([]string, 0, len(v.Options))
+	for axis := range v.Options {
+		axes = append(axes, axis)
+	}
+	sort.Strings(axes)
+	return axes
+}
+
+// validate checks the variant on its own and against the existing variants of
+// its product, which fix the option axes for every later variant.
+func (v *Variant) validate(existing []*Variant) error {
+	if v.SKU == "" || len(v.SKU) > maxSKULength {
+		return NewInvalidVariantError(fmt.Sprintf("sku must be between 1 and %d characters", maxSKULength))
+	}
+	if len(v.Options) == 0 {
+		return NewInvalidVariantError("options must name at least one axis")
+	}
+	for axis, value := range v.Options {
+		if axis == "" || value == "" {
+			return NewInvalidVariantError("option names and values must not be empty")
+		}
+	}
+	if v.Stock < 0 {
+		return NewInvalidVariantError("stock must not be negative")
+	}
+
+	for _, other := range existing {
+		if other.SKU == v.SKU {
+			return NewInvalidVariantError(fmt.Sprintf("sku %s is already used", v.SKU))
+		}
+		if strings.Join(other.axes(), ",") != strings.Join(v.axes(), ",") {
+			return NewInvalidVariantError(fmt.Sprintf("options must be %s", strings.Join(other.axes(), ", ")))
+		}
+		if other.Label("") == v.Label("") {
+			return NewInvalidVariantError("a variant with these options already exists")
+		}
+	}
+	return nil
+}
+
+func (nopVariantRepository) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*Variant, error) {
+	return map[string][]*Variant{}, nil
+}
+
+func (nopVariantRepository) Create(ctx context.Context, variant *Variant, movement *StockMovement) (*Product, error) {
+	return nil, NewInvalidVariantError("variants are not supported")
+}
+
+func (nopVariantRepository) AdjustStock(ctx context.Context, productUUID string, variantUUID string, delta int, allowNegative bool, movement *StockMovement) (*Variant, *Product, error) {
+	return nil, nil, NewVariantNotFoundError(productUUID, variantUUID)
+}
+
+func (nopVariantRepository) Delete(ctx context.Context, productUUID string, variantUUID string, movement *StockMovement) (*Product, error) {
+	return nil, nil
+}
@@ -0,0 +1,265 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	selectVariantQuery = "SELECT v.id_product_variant, v.uuid, p.uuid, v.sku, v.options, v.stock FROM product_variant v " +
+		"INNER JOIN product p ON(p.id_product = v.fk_product)"
+)
+
+func NewVariantRepository(db *sql.DB) VariantRepository {
+	return &variantRepository{db: db}
+}
+
+type variantRepository struct {
+	db *sql.DB
+}
+
+func (r *variantRepository) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*Variant, error) {
+	variants := map[string][]*Variant{}
+	if len(productUUIDs) == 0 {
+		return variants, nil
+	}
+
+	args := make([]interface{}, len(productUUIDs))
+	for i, uuid := range productUUIDs {
+		args[i] = uuid
+	}
+	rows, err := r.db.QueryContext(
+		ctx,
+		selectVariantQuery+" WHERE p.uuid IN (?"+strings.Repeat(",?", len(productUUIDs)-1)+") ORDER BY v.sku",
+		args...,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		variant, err := scanVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		variants[variant.ProductUUID] = append(variants[variant.ProductUUID], variant)
+	}
+
+	return variants, rows.Err()
+}
+
+func (r *variantRepository) Create(ctx context.Context, variant *Variant, movement *StockMovement) (*Product, error) {
+	options, err := json.Marshal(variant.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	productID, err := lockProduct(ctx, tx, variant.ProductUUID)
+	if err != nil {
+		return nil, err
+	}
+	// Bundles change the stock of their components directly, which variants would not allow.
+	bundleUUID, err := findParentBundle(ctx, tx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if bundleUUID != "" {
+		return nil, NewBundleComponentError(variant.ProductUUID, bundleUUID, StockSourceVariant)
+	}
+
+	// The stock of a product with variants is their sum, so the units the product
+	// had on its own would be dropped by its first variant.
+	var variants, stock int
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT (SELECT COUNT(*) FROM product_variant WHERE fk_product = ?), stock FROM product WHERE id_product = ?",
+		productID, productID,
+	).Scan(&variants, &stock)
+	if err != nil {
+		return nil, err
+	}
+	if variants == 0 && stock != 0 {
+		return nil, NewInvalidVariantError(fmt.Sprintf("product has its own stock of %d; adjust it to 0 first", stock))
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO product_variant (uuid, fk_product, sku, options, stock) VALUES(?,?,?,?,?)",
+		variant.UUID, productID, variant.SKU, options, variant.Stock,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := sumStock(ctx, tx, "product_variant", productID, variant.ProductUUID, movement)
+	if err != nil {
+		return nil, err
+	}
+
+	return product, tx.Commit()
+}
+
+func (r *variantRepository) AdjustStock(ctx context.Context, productUUID string, variantUUID string, delta int, allowNegative bool, movement *StockMovement) (*Variant, *Product, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	productID, err := lockProduct(ctx, tx, productUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := tx.ExecContext(
+		ctx,
+		"UPDATE product_variant SET stock = stock + ? WHERE uuid = ? AND fk_product = ? AND (? OR stock + ? >= 0)",
+		delta, variantUUID, productID, allowNegative, delta,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, selectVariantQuery+" WHERE v.uuid = ? AND v.fk_product = ?", variantUUID, productID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, nil, NewVariantNotFoundError(productUUID, variantUUID)
+	}
+	variant, err := scanVariant(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = rows.Close(); err != nil {
+		return nil, nil, err
+	}
+	if affected == 0 {
+		return nil, nil, NewInsufficientStockError(variantUUID, variant.Stock, delta)
+	}
+
+	product, err := sumStock(ctx, tx, "product_variant", productID, productUUID, movement)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return variant, product, tx.Commit()
+}
+
+func (r *variantRepository) Delete(ctx context.Context, productUUID string, variantUUID string, movement *StockMovement) (*Product, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	productID, err := lockProduct(ctx, tx, productUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM product_variant WHERE uuid = ? AND fk_product = ?", variantUUID, productID)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, nil
+	}
+
+	product, err := sumStock(ctx, tx, "product_variant", productID, productUUID, movement)
+	if err != nil {
+		return nil, err
+	}
+
+	return product, tx.Commit()
+}
+
+// lockProduct locks the product row so concurrent variant or location writes
+// recompute the total stock one after another.
+func lockProduct(ctx context.Context, tx *sql.Tx, uuid string) (int, error) {
+	var productID int
+	err := tx.QueryRowContext(ctx, "SELECT id_product FROM product WHERE uuid = ? AND deleted_at IS NULL FOR UPDATE", uuid).Scan(&productID)
+	if err == sql.ErrNoRows {
+		return 0, NewProductNotFoundError(uuid)
+	}
+	return productID, err
+}
+
+// sumStock sets the product stock to the sum of its rows in table, product_variant
+// or product_stock, records the change as movement and returns the product. The
+// product row must be locked, so the change is exactly what this transaction did.
+func sumStock(ctx context.Context, tx *sql.Tx, table string, productID int, uuid string, movement *StockMovement) (*Product, error) {
+	var oldStock int
+	err := tx.QueryRowContext(ctx, "SELECT stock FROM product WHERE id_product = ?", productID).Scan(&oldStock)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE product SET stock = (SELECT COALESCE(SUM(stock), 0) FROM "+table+" WHERE fk_product = ?), version = version + 1 WHERE id_product = ?",
+		productID, productID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, selectProductQuery+" WHERE p.uuid = ?", uuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, NewProductNotFoundError(uuid)
+	}
+	product := &Product{}
+	if err = scanProduct(rows, product); err != nil {
+		return nil, err
+	}
+	if err = rows.Close(); err != nil {
+		return nil, err
+	}
+	if err = writeStockMovement(ctx, tx, movement, product, product.Stock-oldStock); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func scanVariant(rows *sql.Rows) (*Variant, error) {
+	var options []byte
+	variant := &Variant{}
+
+	err := rows.Scan(&variant.VariantID, &variant.UUID, &variant.ProductUUID, &variant.SKU, &options, &variant.Stock)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(options, &variant.Options); err != nil {
+		return nil, err
+	}
+
+	return variant, nil
+}
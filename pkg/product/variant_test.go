@@ -0,0 +1,183 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_VariantLabel(t *testing.T) {
+	v := &Variant{Options: map[string]string{"size": "M", "color": "blue"}}
+
+	assert.Equal(t, "Berlin New Shirt (color: blue, size: M)", v.Label("Berlin New Shirt"))
+}
+
+func Test_VariantValidate(t *testing.T) {
+	existing := []*Variant{
+		{SKU: "BNS-M-BLUE", Options: map[string]string{"size": "M", "color": "blue"}},
+	}
+
+	tests := []struct {
+		name    string
+		variant *Variant
+		wantErr error
+	}{
+		{
+			name:    "test valid variant",
+			variant: &Variant{SKU: "BNS-L-BLUE", Options: map[string]string{"size": "L", "color": "blue"}, Stock: 3},
+		},
+		{
+			name:    "test missing sku",
+			variant: &Variant{Options: map[string]string{"size": "L", "color": "blue"}},
+			wantErr: NewInvalidVariantError("sku must be between 1 and 64 characters"),
+		},
+		{
+			name:    "test missing options",
+			variant: &Variant{SKU: "BNS"},
+			wantErr: NewInvalidVariantError("options must name at least one axis"),
+		},
+		{
+			name:    "test negative stock",
+			variant: &Variant{SKU: "BNS-L-BLUE", Options: map[string]string{"size": "L", "color": "blue"}, Stock: -1},
+			wantErr: NewInvalidVariantError("stock must not be negative"),
+		},
+		{
+			name:    "test duplicate sku",
+			variant: &Variant{SKU: "BNS-M-BLUE", Options: map[string]string{"size": "L", "color": "blue"}},
+			wantErr: NewInvalidVariantError("sku BNS-M-BLUE is already used"),
+		},
+		{
+			name:    "test different axes",
+			variant: &Variant{SKU: "BNS-L", Options: map[string]string{"size": "L"}},
+			wantErr: NewInvalidVariantError("options must be color, size"),
+		},
+		{
+			name:    "test duplicate options",
+			variant: &Variant{SKU: "BNS-M-BLUE-2", Options: map[string]string{"size": "M", "color": "blue"}},
+			wantErr: NewInvalidVariantError("a variant with these options already exists"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, tt.variant.validate(existing))
+		})
+	}
+}
+
+func Test_serviceVariants(t *testing.T) {
+	repo := &repositoryMock{products: map[string]*Product{
+		"p1": {UUID: "p1", Name: "Berlin New Shirt", Stock: 10, SellerUUID: "s1", Version: 1},
+	}}
+	variantRepo := &variantRepositoryMock{repo: repo}
+	noti := &notiProviderMock{}
+	svc := NewService(repo, &sellerRepositoryMock{}, noti, WithVariantRepository(variantRepo))
+	ctx := context.Background()
+
+	// The units the product has on its own cannot be told apart by size.
+	medium := &Variant{SKU: "BNS-M", Options: map[string]string{"size": "M"}, Stock: 4}
+	err := svc.CreateVariant(ctx, "p1", medium)
+	assert.Equal(t, NewInvalidVariantError("product has its own stock of 10; adjust it to 0 first"), err)
+	_, err = svc.AdjustStock(ctx, "p1", &StockAdjustment{Delta: -10, Reason: "split by size"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, svc.CreateVariant(ctx, "p1", medium))
+	assert.NoError(t, svc.CreateVariant(ctx, "p1", &Variant{SKU: "BNS-L", Options: map[string]string{"size": "L"}, Stock: 6}))
+	assert.Equal(t, 10, repo.products["p1"].Stock)
+
+	got, err := svc.FindByUUID(ctx, "p1")
+	assert.NoError(t, err)
+	assert.Len(t, got.Variants, 2)
+
+	variant, err := svc.AdjustVariantStock(ctx, "p1", medium.UUID, &StockAdjustment{Delta: -3, Reason: "order 1042"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, variant.Stock)
+	assert.Equal(t, 7, repo.products["p1"].Stock)
+	assert.Equal(t, 2, noti.calls)
+	assert.Equal(t, "Berlin New Shirt (size: M)", noti.lastProduct)
+
+	_, err = svc.AdjustVariantStock(ctx, "p1", medium.UUID, &StockAdjustment{Delta: -2, Reason: "order 1043"})
+	assert.Equal(t, NewInsufficientStockError(medium.UUID, 1, -2), err)
+
+	_, err = svc.AdjustStock(ctx, "p1", &StockAdjustment{Delta: 1, Reason: "recount"})
+	assert.Equal(t, NewDerivedStockError("p1", StockSourceVariant), err)
+
+	stock := 20
+	_, err = svc.Patch(ctx, "p1", 0, &Patch{Stock: &stock})
+	assert.Equal(t, NewDerivedStockError("p1", StockSourceVariant), err)
+
+	assert.NoError(t, svc.DeleteVariant(ctx, "p1", medium.UUID))
+	assert.Equal(t, 6, repo.products["p1"].Stock)
+	assert.Equal(t, NewVariantNotFoundError("p1", medium.UUID), svc.DeleteVariant(ctx, "p1", medium.UUID))
+}
+
+type variantRepositoryMock struct {
+	repo     *repositoryMock
+	variants []*Variant
+}
+
+func (m *variantRepositoryMock) ListByProducts(ctx context.Context, productUUIDs []string) (map[string][]*Variant, error) {
+	variants := map[string][]*Variant{}
+	for _, uuid := range productUUIDs {
+		for _, v := range m.variants {
+			if v.ProductUUID == uuid {
+				cp := *v
+				variants[uuid] = append(variants[uuid], &cp)
+			}
+		}
+		sort.Slice(variants[uuid], func(i, j int) bool { return variants[uuid][i].SKU < variants[uuid][j].SKU })
+	}
+	return variants, nil
+}
+
+func (m *variantRepositoryMock) Create(ctx context.Context, variant *Variant, movement *StockMovement) (*Product, error) {
+	if variants, _ := m.ListByProducts(ctx, []string{variant.ProductUUID}); len(variants[variant.ProductUUID]) == 0 {
+		if stock := m.repo.products[variant.ProductUUID].Stock; stock != 0 {
+			return nil, NewInvalidVariantError(fmt.Sprintf("product has its own stock of %d; adjust it to 0 first", stock))
+		}
+	}
+	cp := *variant
+	m.variants = append(m.variants, &cp)
+	return m.sumStock(variant.ProductUUID, movement), nil
+}
+
+func (m *variantRepositoryMock) AdjustStock(ctx context.Context, productUUID string, variantUUID string, delta int, allowNegative bool, movement *StockMovement) (*Variant, *Product, error) {
+	for _, v := range m.variants {
+		if v.ProductUUID == productUUID && v.UUID == variantUUID {
+			if !allowNegative && v.Stock+delta < 0 {
+				return nil, nil, NewInsufficientStockError(variantUUID, v.Stock, delta)
+			}
+			v.Stock += delta
+			cp := *v
+			return &cp, m.sumStock(productUUID, movement), nil
+		}
+	}
+	return nil, nil, NewVariantNotFoundError(productUUID, variantUUID)
+}
+
+func (m *variantRepositoryMock) Delete(ctx context.Context, productUUID string, variantUUID string, movement *StockMovement) (*Product, error) {
+	for i, v := range m.variants {
+		if v.ProductUUID == productUUID && v.UUID == variantUUID {
+			m.variants = append(m.variants[:i], m.variants[i+1:]...)
+			return m.sumStock(productUUID, movement), nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *variantRepositoryMock) sumStock(productUUID string, movement *StockMovement) *Product {
+	p := m.repo.products[productUUID]
+	oldStock := p.Stock
+	p.Stock = 0
+	for _, v := range m.variants {
+		if v.ProductUUID == productUUID {
+			p.Stock += v.Stock
+		}
+	}
+	p.Version++
+	m.repo.writeStockMovement(movement, p, p.Stock-oldStock)
+	cp := *p
+	return &cp
+}
@@ -0,0 +1,47 @@
+package reconciliation
+
+import "fmt"
+
+type ReconciliationNotFoundError struct {
+	id string
+}
+
+func (e ReconciliationNotFoundError) Error() string {
+	return fmt.Sprintf("Reconciliation is not found with id=%s", e.id)
+}
+
+func NewReconciliationNotFoundError(uuid string) error {
+	return &ReconciliationNotFoundError{
+		id: uuid,
+	}
+}
+
+type InvalidReconciliationError struct {
+	reason string
+}
+
+func (e InvalidReconciliationError) Error() string {
+	return fmt.Sprintf("Stock count is invalid: %s", e.reason)
+}
+
+func NewInvalidReconciliationError(reason string) error {
+	return &InvalidReconciliationError{
+		reason: reason,
+	}
+}
+
+type ReconciliationNotPendingError struct {
+	id     string
+	status Status
+}
+
+func (e ReconciliationNotPendingError) Error() string {
+	return fmt.Sprintf("Reconciliation id=%s is %s and can no longer be reviewed", e.id, e.status)
+}
+
+func NewReconciliationNotPendingError(uuid string, status Status) error {
+	return &ReconciliationNotPendingError{
+		id:     uuid,
+		status: status,
+	}
+}
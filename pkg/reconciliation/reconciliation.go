@@ -0,0 +1,127 @@
+package reconciliation
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxLines caps the rows of one uploaded count.
+	maxLines = 10000
+)
+
+type Status string
+
+const (
+	// Pending reconciliations wait for their report to be approved or rejected.
+	Pending Status = "pending"
+	// Approved reconciliations are being applied.
+	Approved Status = "approved"
+	Applied  Status = "applied"
+	Rejected Status = "rejected"
+)
+
+type (
+	// Reconciliation compares stock counted in a warehouse with the stock on record.
+	Reconciliation struct {
+		ReconciliationID int    `json:"-"`
+		UUID             string `json:"uuid"`
+		// SellerUUID limits the count to the products of one seller, which may then
+		// be named by SKU.
+		SellerUUID string     `json:"seller_uuid,omitempty"`
+		Status     Status     `json:"status"`
+		Lines      []*Line    `json:"lines"`
+		CreatedBy  string     `json:"created_by"`
+		CreatedAt  time.Time  `json:"created_at"`
+		ReviewedBy string     `json:"reviewed_by,omitempty"`
+		ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	}
+
+	// Line is one counted product of the report.
+	Line struct {
+		// Row is the row of the uploaded file, where the header is row 1.
+		Row         int    `json:"row"`
+		ProductUUID string `json:"product_uuid,omitempty"`
+		SKU         string `json:"sku,omitempty"`
+		Name        string `json:"name,omitempty"`
+		// Expected is the stock on record when the count was uploaded.
+		Expected   int `json:"expected"`
+		Counted    int `json:"counted"`
+		Difference int `json:"difference"`
+		// Error says why the line cannot be applied, e.g. an unknown product.
+		// Approving skips such lines.
+		Error string `json:"error,omitempty"`
+	}
+)
+
+// applicable reports whether approving changes stock for the line.
+func (l *Line) applicable() bool {
+	return l.Error == "" && l.Difference != 0
+}
+
+// parseCounts reads a CSV file whose header names a counted column and a uuid or
+// sku column, e.g. "sku,counted". Rows name their product by either of them.
+func parseCounts(r io.Reader) ([]*Line, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, NewInvalidReconciliationError("file is empty")
+	}
+	if err != nil {
+		return nil, NewInvalidReconciliationError(err.Error())
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	countedColumn, ok := columns["counted"]
+	if !ok {
+		return nil, NewInvalidReconciliationError("header has no counted column")
+	}
+	uuidColumn, hasUUID := columns["uuid"]
+	skuColumn, hasSKU := columns["sku"]
+	if !hasUUID && !hasSKU {
+		return nil, NewInvalidReconciliationError("header has neither a uuid nor a sku column")
+	}
+
+	var lines []*Line
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, NewInvalidReconciliationError(err.Error())
+		}
+		if len(lines) == maxLines {
+			return nil, NewInvalidReconciliationError(fmt.Sprintf("file has more than %d rows", maxLines))
+		}
+
+		line := &Line{Row: row}
+		if hasUUID {
+			line.ProductUUID = strings.TrimSpace(record[uuidColumn])
+		}
+		if hasSKU {
+			line.SKU = strings.TrimSpace(record[skuColumn])
+		}
+		if line.ProductUUID == "" && line.SKU == "" {
+			return nil, NewInvalidReconciliationError(fmt.Sprintf("row %d names no product", row))
+		}
+		counted, err := strconv.Atoi(strings.TrimSpace(record[countedColumn]))
+		if err != nil || counted < 0 {
+			return nil, NewInvalidReconciliationError(fmt.Sprintf("row %d: counted must be a whole number of at least zero", row))
+		}
+		line.Counted = counted
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return nil, NewInvalidReconciliationError("file has no counts")
+	}
+	return lines, nil
+}
@@ -0,0 +1,117 @@
+package reconciliation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	selectReconciliationQuery = "SELECT id_stock_reconciliation, uuid, COALESCE(seller_uuid, ''), status, report_lines, created_by, created_at, " +
+		"COALESCE(reviewed_by, ''), reviewed_at FROM stock_reconciliation"
+)
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func (r *repository) List(ctx context.Context, sellerUUID string, status Status) ([]*Reconciliation, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	if sellerUUID != "" {
+		conditions = append(conditions, "seller_uuid = ?")
+		args = append(args, sellerUUID)
+	}
+	if status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, status)
+	}
+
+	query := selectReconciliationQuery
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return r.query(ctx, query+" ORDER BY id_stock_reconciliation DESC", args...)
+}
+
+func (r *repository) FindByUUID(ctx context.Context, uuid string) (*Reconciliation, error) {
+	reconciliations, err := r.query(ctx, selectReconciliationQuery+" WHERE uuid = ?", uuid)
+	if err != nil || len(reconciliations) == 0 {
+		return nil, err
+	}
+	return reconciliations[0], nil
+}
+
+func (r *repository) Create(ctx context.Context, reconciliation *Reconciliation) error {
+	lines, err := json.Marshal(reconciliation.Lines)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(
+		ctx,
+		"INSERT INTO stock_reconciliation (uuid, seller_uuid, status, report_lines, created_by, created_at) VALUES(?,?,?,?,?,?)",
+		reconciliation.UUID, nullString(reconciliation.SellerUUID), reconciliation.Status, lines,
+		reconciliation.CreatedBy, reconciliation.CreatedAt,
+	)
+	return err
+}
+
+func (r *repository) Review(ctx context.Context, reconciliation *Reconciliation, from Status) (bool, error) {
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE stock_reconciliation SET status = ?, reviewed_by = ?, reviewed_at = ? WHERE uuid = ? AND status = ?",
+		reconciliation.Status, nullString(reconciliation.ReviewedBy), reconciliation.ReviewedAt, reconciliation.UUID, from,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *repository) query(ctx context.Context, query string, args ...interface{}) ([]*Reconciliation, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	reconciliations := []*Reconciliation{}
+
+	for rows.Next() {
+		reconciliation := &Reconciliation{}
+		var lines []byte
+		err := rows.Scan(
+			&reconciliation.ReconciliationID, &reconciliation.UUID, &reconciliation.SellerUUID, &reconciliation.Status, &lines,
+			&reconciliation.CreatedBy, &reconciliation.CreatedAt, &reconciliation.ReviewedBy, &reconciliation.ReviewedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(lines, &reconciliation.Lines); err != nil {
+			return nil, err
+		}
+		reconciliations = append(reconciliations, reconciliation)
+	}
+
+	return reconciliations, rows.Err()
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
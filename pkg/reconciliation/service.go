@@ -0,0 +1,216 @@
+package reconciliation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/product"
+	"coding-challenge-go/pkg/requestinfo"
+)
+
+type (
+	Service interface {
+		// List returns reconciliations, newest first, only those of sellerUUID and in
+		// status unless they are empty.
+		List(ctx context.Context, sellerUUID string, status Status) ([]*Reconciliation, error)
+		FindByUUID(ctx context.Context, uuid string) (*Reconciliation, error)
+		// Create reads counted stock from a CSV file and stores a pending report that
+		// compares it with the stock on record. Products that cannot be matched are
+		// reported per line instead of failing the upload.
+		Create(ctx context.Context, sellerUUID string, r io.Reader) (*Reconciliation, error)
+		// Approve applies the differences of a pending report as one stock batch.
+		// When the batch fails, nothing is applied and the report stays pending.
+		Approve(ctx context.Context, uuid string) (*Reconciliation, error)
+		// Reject closes a pending report without changing any stock.
+		Reject(ctx context.Context, uuid string) (*Reconciliation, error)
+	}
+
+	Repository interface {
+		// List returns reconciliations, newest first, only those of sellerUUID and in
+		// status unless they are empty.
+		List(ctx context.Context, sellerUUID string, status Status) ([]*Reconciliation, error)
+		FindByUUID(ctx context.Context, uuid string) (*Reconciliation, error)
+		Create(ctx context.Context, reconciliation *Reconciliation) error
+		// Review stores the status and reviewer of a reconciliation while it is in
+		// status from and reports false otherwise.
+		Review(ctx context.Context, reconciliation *Reconciliation, from Status) (bool, error)
+	}
+
+	service struct {
+		repo       Repository
+		productSvc product.Service
+		now        func() time.Time
+	}
+)
+
+func NewService(repo Repository, productSvc product.Service) Service {
+	return &service{
+		repo:       repo,
+		productSvc: productSvc,
+		now:        time.Now,
+	}
+}
+
+func (s *service) List(ctx context.Context, sellerUUID string, status Status) ([]*Reconciliation, error) {
+	return s.repo.List(ctx, sellerUUID, status)
+}
+
+func (s *service) FindByUUID(ctx context.Context, uuid string) (*Reconciliation, error) {
+	reconciliation, err := s.repo.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if reconciliation == nil {
+		return nil, NewReconciliationNotFoundError(uuid)
+	}
+	return reconciliation, nil
+}
+
+func (s *service) Create(ctx context.Context, sellerUUID string, r io.Reader) (*Reconciliation, error) {
+	lines, err := parseCounts(r)
+	if err != nil {
+		return nil, err
+	}
+
+	counted := map[string]bool{}
+	for _, line := range lines {
+		if err := s.compare(ctx, sellerUUID, line); err != nil {
+			return nil, err
+		}
+		if line.Error != "" {
+			continue
+		}
+		if counted[line.ProductUUID] {
+			line.Error = "product is counted more than once"
+			continue
+		}
+		counted[line.ProductUUID] = true
+	}
+
+	reconciliation := &Reconciliation{
+		UUID:       uuid.New().String(),
+		SellerUUID: sellerUUID,
+		Status:     Pending,
+		Lines:      lines,
+		CreatedBy:  requestinfo.Actor(ctx),
+		CreatedAt:  s.now().UTC().Truncate(time.Second),
+	}
+	if err := s.repo.Create(ctx, reconciliation); err != nil {
+		return nil, err
+	}
+	return reconciliation, nil
+}
+
+// compare looks up the product of line and fills in its expected stock, or the
+// reason it cannot be reconciled.
+func (s *service) compare(ctx context.Context, sellerUUID string, line *Line) error {
+	var (
+		info *product.ProductInfo
+		err  error
+	)
+	switch {
+	case line.ProductUUID != "":
+		info, err = s.productSvc.FindByUUID(ctx, line.ProductUUID)
+	case sellerUUID == "":
+		line.Error = "products can only be named by SKU in the count of one seller"
+		return nil
+	default:
+		info, err = s.productSvc.FindBySKU(ctx, sellerUUID, line.SKU)
+	}
+	if _, ok := err.(*product.ProductNotFoundError); ok {
+		line.Error = "product is not found"
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	line.ProductUUID, line.SKU, line.Name = info.UUID, info.SKU, info.Name
+	switch {
+	case sellerUUID != "" && info.SellerUUID != sellerUUID:
+		line.Error = "product belongs to another seller"
+	case len(info.Variants) > 0:
+		line.Error = product.NewDerivedStockError(info.UUID, product.StockSourceVariant).Error()
+	case len(info.Locations) > 0:
+		line.Error = product.NewDerivedStockError(info.UUID, product.StockSourceLocation).Error()
+	case len(info.Components) > 0:
+		line.Error = product.NewDerivedStockError(info.UUID, product.StockSourceComponent).Error()
+	default:
+		line.Expected = info.Stock
+		line.Difference = line.Counted - line.Expected
+	}
+	return nil
+}
+
+// Approve adds the difference of each line to the stock rather than setting the
+// counted value, so sales between the upload and the approval are kept.
+func (s *service) Approve(ctx context.Context, uuid string) (*Reconciliation, error) {
+	reconciliation, err := s.review(ctx, uuid, Approved)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &product.StockBatch{
+		Reason: fmt.Sprintf("reconciliation %s", uuid),
+		Code:   product.StockReasonCorrection,
+	}
+	for _, line := range reconciliation.Lines {
+		if line.applicable() {
+			batch.Deltas = append(batch.Deltas, &product.StockDelta{ProductUUID: line.ProductUUID, Delta: line.Difference})
+		}
+	}
+	if len(batch.Deltas) > 0 {
+		if _, err := s.productSvc.AdjustStockBatch(ctx, batch); err != nil {
+			// Put the report back so it can be approved again, or rejected.
+			reconciliation.Status, reconciliation.ReviewedBy, reconciliation.ReviewedAt = Pending, "", nil
+			if _, reopenErr := s.repo.Review(ctx, reconciliation, Approved); reopenErr != nil {
+				log.Error().Err(reopenErr).Msg(fmt.Sprintf("Fail to reopen reconciliation %s", uuid))
+			}
+			return nil, err
+		}
+	}
+
+	reconciliation.Status = Applied
+	if _, err := s.repo.Review(ctx, reconciliation, Approved); err != nil {
+		return nil, err
+	}
+	return reconciliation, nil
+}
+
+func (s *service) Reject(ctx context.Context, uuid string) (*Reconciliation, error) {
+	return s.review(ctx, uuid, Rejected)
+}
+
+// review moves a pending reconciliation to status on behalf of the actor of ctx.
+func (s *service) review(ctx context.Context, uuid string, status Status) (*Reconciliation, error) {
+	reconciliation, err := s.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if reconciliation.Status != Pending {
+		return nil, NewReconciliationNotPendingError(uuid, reconciliation.Status)
+	}
+
+	now := s.now().UTC().Truncate(time.Second)
+	reconciliation.Status = status
+	reconciliation.ReviewedBy = requestinfo.Actor(ctx)
+	reconciliation.ReviewedAt = &now
+	ok, err := s.repo.Review(ctx, reconciliation, Pending)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// Someone else reviewed it first.
+		current, err := s.FindByUUID(ctx, uuid)
+		if err != nil {
+			return nil, err
+		}
+		return nil, NewReconciliationNotPendingError(uuid, current.Status)
+	}
+	return reconciliation, nil
+}
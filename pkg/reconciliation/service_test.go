@@ -0,0 +1,248 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/product"
+	"coding-challenge-go/pkg/requestinfo"
+)
+
+var countedAt = time.Date(2021, 9, 1, 18, 0, 0, 0, time.UTC)
+
+func Test_parseCounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    []*Line
+		wantErr error
+	}{
+		{
+			name: "test uuid and sku columns",
+			file: "uuid,sku,counted\np1,,8\n,PT-1, 12\n",
+			want: []*Line{{Row: 2, ProductUUID: "p1", Counted: 8}, {Row: 3, SKU: "PT-1", Counted: 12}},
+		},
+		{
+			name: "test header in any case and order",
+			file: "Counted,SKU\n0,PT-1\n",
+			want: []*Line{{Row: 2, SKU: "PT-1", Counted: 0}},
+		},
+		{
+			name:    "test empty file",
+			file:    "",
+			wantErr: NewInvalidReconciliationError("file is empty"),
+		},
+		{
+			name:    "test no counts",
+			file:    "sku,counted\n",
+			wantErr: NewInvalidReconciliationError("file has no counts"),
+		},
+		{
+			name:    "test missing counted column",
+			file:    "sku,stock\nPT-1,3\n",
+			wantErr: NewInvalidReconciliationError("header has no counted column"),
+		},
+		{
+			name:    "test missing product column",
+			file:    "name,counted\nPlano Tee,3\n",
+			wantErr: NewInvalidReconciliationError("header has neither a uuid nor a sku column"),
+		},
+		{
+			name:    "test negative count",
+			file:    "sku,counted\nPT-1,-3\n",
+			wantErr: NewInvalidReconciliationError("row 2: counted must be a whole number of at least zero"),
+		},
+		{
+			name:    "test row without product",
+			file:    "uuid,sku,counted\np1,,3\n,,3\n",
+			wantErr: NewInvalidReconciliationError("row 3 names no product"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCounts(strings.NewReader(tt.file))
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_serviceCreate(t *testing.T) {
+	repo := newRepositoryMock()
+	svc := NewService(repo, newProductServiceMock()).(*service)
+	svc.now = func() time.Time { return countedAt }
+	ctx := requestinfo.WithActor(context.Background(), "warehouse@gfg.com")
+
+	file := "uuid,sku,counted\np1,,8\n,PT-1,12\n,CAP-1,1\np3,,4\np4,,2\np1,,9\np5,,3\n"
+	reconciliation, err := svc.Create(ctx, "s1", strings.NewReader(file))
+	assert.NoError(t, err)
+	assert.Equal(t, Pending, reconciliation.Status)
+	assert.Equal(t, "warehouse@gfg.com", reconciliation.CreatedBy)
+	assert.Equal(t, countedAt, reconciliation.CreatedAt)
+	assert.Equal(t, []*Line{
+		{Row: 2, ProductUUID: "p1", SKU: "BNS-1", Name: "Berlin New Shirt", Expected: 10, Counted: 8, Difference: -2},
+		{Row: 3, ProductUUID: "p2", SKU: "PT-1", Name: "Plano Tee", Expected: 5, Counted: 12, Difference: 7},
+		{Row: 4, SKU: "CAP-1", Counted: 1, Error: "product is not found"},
+		{Row: 5, ProductUUID: "p3", Name: "Storm Jacket", Counted: 4, Error: "product belongs to another seller"},
+		{Row: 6, ProductUUID: "p4", Name: "Ottana Sweater", Counted: 2,
+			Error: product.NewDerivedStockError("p4", product.StockSourceVariant).Error()},
+		{Row: 7, ProductUUID: "p1", SKU: "BNS-1", Name: "Berlin New Shirt", Expected: 10, Counted: 9, Difference: -1,
+			Error: "product is counted more than once"},
+		{Row: 8, ProductUUID: "p5", Name: "Shirt and Tee Kit", Counted: 3,
+			Error: product.NewDerivedStockError("p5", product.StockSourceComponent).Error()},
+	}, reconciliation.Lines)
+	assert.Equal(t, reconciliation, repo.reconciliations[reconciliation.UUID])
+
+	// Without a seller only UUIDs name products.
+	reconciliation, err = svc.Create(ctx, "", strings.NewReader("uuid,sku,counted\np3,,4\n,PT-1,12\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, -1, reconciliation.Lines[0].Difference)
+	assert.Equal(t, "products can only be named by SKU in the count of one seller", reconciliation.Lines[1].Error)
+
+	_, err = svc.Create(ctx, "s1", strings.NewReader("sku\nPT-1\n"))
+	assert.Equal(t, NewInvalidReconciliationError("header has no counted column"), err)
+}
+
+func Test_serviceApprove(t *testing.T) {
+	repo := newRepositoryMock()
+	productSvc := newProductServiceMock()
+	svc := NewService(repo, productSvc).(*service)
+	svc.now = func() time.Time { return countedAt }
+	ctx := requestinfo.WithActor(context.Background(), "lead@gfg.com")
+
+	repo.reconciliations["r1"] = &Reconciliation{UUID: "r1", Status: Pending, Lines: []*Line{
+		{Row: 2, ProductUUID: "p1", Expected: 10, Counted: 8, Difference: -2},
+		{Row: 3, ProductUUID: "p2", Expected: 5, Counted: 5},
+		{Row: 4, SKU: "CAP-1", Counted: 1, Error: "product is not found"},
+		{Row: 5, ProductUUID: "p3", Expected: 3, Counted: 4, Difference: 1},
+	}}
+
+	reconciliation, err := svc.Approve(ctx, "r1")
+	assert.NoError(t, err)
+	assert.Equal(t, Applied, reconciliation.Status)
+	assert.Equal(t, "lead@gfg.com", reconciliation.ReviewedBy)
+	assert.Equal(t, &countedAt, reconciliation.ReviewedAt)
+	assert.Equal(t, Applied, repo.reconciliations["r1"].Status)
+	assert.Equal(t, []*product.StockBatch{{
+		Deltas: []*product.StockDelta{{ProductUUID: "p1", Delta: -2}, {ProductUUID: "p3", Delta: 1}},
+		Reason: "reconciliation r1",
+		Code:   product.StockReasonCorrection,
+	}}, productSvc.batches)
+
+	_, err = svc.Approve(ctx, "r1")
+	assert.Equal(t, NewReconciliationNotPendingError("r1", Applied), err)
+	_, err = svc.Reject(ctx, "r1")
+	assert.Equal(t, NewReconciliationNotPendingError("r1", Applied), err)
+	_, err = svc.Approve(ctx, "r2")
+	assert.Equal(t, NewReconciliationNotFoundError("r2"), err)
+}
+
+func Test_serviceApproveFails(t *testing.T) {
+	repo := newRepositoryMock()
+	productSvc := newProductServiceMock()
+	productSvc.batchErr = errors.New("connection refused")
+	svc := NewService(repo, productSvc)
+
+	repo.reconciliations["r1"] = &Reconciliation{UUID: "r1", Status: Pending, Lines: []*Line{
+		{Row: 2, ProductUUID: "p1", Expected: 10, Counted: 8, Difference: -2},
+	}}
+
+	_, err := svc.Approve(context.Background(), "r1")
+	assert.Equal(t, productSvc.batchErr, err)
+	assert.Equal(t, Pending, repo.reconciliations["r1"].Status)
+	assert.Nil(t, repo.reconciliations["r1"].ReviewedAt)
+
+	reconciliation, err := svc.Reject(context.Background(), "r1")
+	assert.NoError(t, err)
+	assert.Equal(t, Rejected, reconciliation.Status)
+	assert.Equal(t, Rejected, repo.reconciliations["r1"].Status)
+}
+
+type repositoryMock struct {
+	reconciliations map[string]*Reconciliation
+}
+
+func newRepositoryMock() *repositoryMock {
+	return &repositoryMock{reconciliations: map[string]*Reconciliation{}}
+}
+
+func (m *repositoryMock) List(ctx context.Context, sellerUUID string, status Status) ([]*Reconciliation, error) {
+	return nil, nil
+}
+
+func (m *repositoryMock) FindByUUID(ctx context.Context, uuid string) (*Reconciliation, error) {
+	reconciliation, ok := m.reconciliations[uuid]
+	if !ok {
+		return nil, nil
+	}
+	cp := *reconciliation
+	return &cp, nil
+}
+
+func (m *repositoryMock) Create(ctx context.Context, reconciliation *Reconciliation) error {
+	m.reconciliations[reconciliation.UUID] = reconciliation
+	return nil
+}
+
+func (m *repositoryMock) Review(ctx context.Context, reconciliation *Reconciliation, from Status) (bool, error) {
+	if existing, ok := m.reconciliations[reconciliation.UUID]; !ok || existing.Status != from {
+		return false, nil
+	}
+	cp := *reconciliation
+	m.reconciliations[reconciliation.UUID] = &cp
+	return true, nil
+}
+
+// productServiceMock only implements the calls the reconciliation service makes.
+type productServiceMock struct {
+	product.Service
+	products map[string]*product.ProductInfo
+	batches  []*product.StockBatch
+	batchErr error
+}
+
+func newProductServiceMock() *productServiceMock {
+	return &productServiceMock{products: map[string]*product.ProductInfo{
+		"p1": {Product: &product.Product{UUID: "p1", SKU: "BNS-1", Name: "Berlin New Shirt", SellerUUID: "s1", Stock: 10}},
+		"p2": {Product: &product.Product{UUID: "p2", SKU: "PT-1", Name: "Plano Tee", SellerUUID: "s1", Stock: 5}},
+		"p3": {Product: &product.Product{UUID: "p3", Name: "Storm Jacket", SellerUUID: "s2", Stock: 5}},
+		"p4": {
+			Product:  &product.Product{UUID: "p4", Name: "Ottana Sweater", SellerUUID: "s1", Stock: 7},
+			Variants: []*product.Variant{{SKU: "OS-M", Stock: 7}},
+		},
+		"p5": {
+			Product:    &product.Product{UUID: "p5", Name: "Shirt and Tee Kit", SellerUUID: "s1", Stock: 5},
+			Components: []*product.Component{{ProductUUID: "p1", Quantity: 1, Stock: 10}, {ProductUUID: "p2", Quantity: 1, Stock: 5}},
+		},
+	}}
+}
+
+func (m *productServiceMock) FindByUUID(ctx context.Context, uuid string) (*product.ProductInfo, error) {
+	info, ok := m.products[uuid]
+	if !ok {
+		return nil, product.NewProductNotFoundError(uuid)
+	}
+	return info, nil
+}
+
+func (m *productServiceMock) FindBySKU(ctx context.Context, sellerUUID string, sku string) (*product.ProductInfo, error) {
+	for _, info := range m.products {
+		if info.SellerUUID == sellerUUID && info.SKU == sku {
+			return info, nil
+		}
+	}
+	return nil, product.NewProductNotFoundError(sku)
+}
+
+func (m *productServiceMock) AdjustStockBatch(ctx context.Context, batch *product.StockBatch) ([]*product.Product, error) {
+	if m.batchErr != nil {
+		return nil, m.batchErr
+	}
+	m.batches = append(m.batches, batch)
+	return nil, nil
+}
@@ -0,0 +1,43 @@
+// Package requestinfo carries who made a request and its ID through a context.
+package requestinfo
+
+import "context"
+
+const (
+	// AnonymousActor is reported when a request does not name its actor.
+	AnonymousActor = "anonymous"
+
+	// MaxActorLength and MaxRequestIDLength are the widths of the columns the
+	// actor and request ID are stored in.
+	MaxActorLength     = 200
+	MaxRequestIDLength = 64
+)
+
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	requestIDKey
+)
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor returns the actor stored in ctx, or AnonymousActor.
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID stored in ctx, or an empty string.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
@@ -0,0 +1,65 @@
+package reservation
+
+import "fmt"
+
+type ReservationNotFoundError struct {
+	id string
+}
+
+func (e ReservationNotFoundError) Error() string {
+	return fmt.Sprintf("Reservation is not found with id=%s", e.id)
+}
+
+func NewReservationNotFoundError(uuid string) error {
+	return &ReservationNotFoundError{
+		id: uuid,
+	}
+}
+
+type InvalidReservationError struct {
+	reason string
+}
+
+func (e InvalidReservationError) Error() string {
+	return fmt.Sprintf("Reservation is invalid: %s", e.reason)
+}
+
+func NewInvalidReservationError(reason string) error {
+	return &InvalidReservationError{
+		reason: reason,
+	}
+}
+
+type InsufficientAvailableStockError struct {
+	productID string
+	available int
+	quantity  int
+}
+
+func (e InsufficientAvailableStockError) Error() string {
+	return fmt.Sprintf("Product id=%s has %d units available and cannot reserve %d", e.productID, e.available, e.quantity)
+}
+
+func NewInsufficientAvailableStockError(productUUID string, available int, quantity int) error {
+	return &InsufficientAvailableStockError{
+		productID: productUUID,
+		available: available,
+		quantity:  quantity,
+	}
+}
+
+type ReservationNotActiveError struct {
+	id     string
+	status Status
+}
+
+func (e ReservationNotActiveError) Error() string {
+	return fmt.Sprintf("Reservation id=%s is %s", e.id, e.status)
+}
+
+func NewReservationNotActiveError(uuid string, status Status) error {
+	return &ReservationNotActiveError{
+		id:     uuid,
+		status: status,
+	}
+}
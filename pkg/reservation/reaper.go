@@ -0,0 +1,25 @@
+package reservation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/periodic"
+)
+
+// Reap expires stale reservations every interval until ctx is done.
+func Reap(ctx context.Context, svc Service, interval time.Duration) {
+	periodic.Run(ctx, "reservation reaper", interval, func(ctx context.Context) {
+		expired, err := svc.ExpireStale(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("Fail to expire stale reservations")
+			return
+		}
+		if expired > 0 {
+			log.Info().Msg(fmt.Sprintf("Expired %d stale reservations", expired))
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package reservation
+
+import (
+	"context"
+	"database/sql"
+
+	"coding-challenge-go/pkg/product"
+)
+
+const (
+	// selectReservationQuery reports active reservations past their expiry as expired,
+	// even before the reaper has marked them.
+	selectReservationQuery = "SELECT r.id_reservation, r.uuid, p.uuid, r.quantity, " +
+		"CASE WHEN r.status = 'active' AND r.expires_at <= UTC_TIMESTAMP() THEN 'expired' ELSE r.status END, " +
+		"r.expires_at, r.created_at FROM reservation r " +
+		"INNER JOIN product p ON(p.id_product = r.fk_product)"
+)
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func (r *repository) Create(ctx context.Context, reservation *Reservation, ttlSeconds int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Locking the product row serializes reservations of the same product, so two
+	// checkouts cannot both take the last units.
+	var productID, stock int
+	err = tx.QueryRowContext(ctx, "SELECT id_product, stock FROM product WHERE uuid = ? AND deleted_at IS NULL FOR UPDATE", reservation.ProductUUID).Scan(&productID, &stock)
+	if err == sql.ErrNoRows {
+		return product.NewProductNotFoundError(reservation.ProductUUID)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Reservations hold product stock, which variants, locations or the
+	// components of a bundle own once a product has them.
+	var variants, locations, components int
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT (SELECT COUNT(*) FROM product_variant WHERE fk_product = ?), (SELECT COUNT(*) FROM product_stock WHERE fk_product = ?), (SELECT COUNT(*) FROM product_bundle_component WHERE fk_bundle = ?)",
+		productID, productID, productID,
+	).Scan(&variants, &locations, &components)
+	if err != nil {
+		return err
+	}
+	if variants > 0 {
+		return product.NewDerivedStockError(reservation.ProductUUID, product.StockSourceVariant)
+	}
+	if locations > 0 {
+		return product.NewDerivedStockError(reservation.ProductUUID, product.StockSourceLocation)
+	}
+	if components > 0 {
+		return product.NewDerivedStockError(reservation.ProductUUID, product.StockSourceComponent)
+	}
+
+	var reserved int
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT COALESCE(SUM(quantity), 0) FROM reservation WHERE fk_product = ? AND status = 'active' AND expires_at > UTC_TIMESTAMP()",
+		productID,
+	).Scan(&reserved)
+	if err != nil {
+		return err
+	}
+	if available := stock - reserved; available < reservation.Quantity {
+		return NewInsufficientAvailableStockError(reservation.ProductUUID, available, reservation.Quantity)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO reservation (uuid, fk_product, quantity, status, expires_at) VALUES(?,?,?,?,DATE_ADD(UTC_TIMESTAMP(), INTERVAL ? SECOND))",
+		reservation.UUID, productID, reservation.Quantity, Active, ttlSeconds,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	created, err := r.FindByUUID(ctx, reservation.UUID)
+	if err != nil {
+		return err
+	}
+	*reservation = *created
+
+	return nil
+}
+
+func (r *repository) FindByUUID(ctx context.Context, uuid string) (*Reservation, error) {
+	rows, err := r.db.QueryContext(ctx, selectReservationQuery+" WHERE r.uuid = ?", uuid)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, nil
+	}
+
+	reservation := &Reservation{}
+	err = rows.Scan(
+		&reservation.ReservationID, &reservation.UUID, &reservation.ProductUUID, &reservation.Quantity,
+		&reservation.Status, &reservation.ExpiresAt, &reservation.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+func (r *repository) Release(ctx context.Context, uuid string) (bool, error) {
+	return r.exec(ctx, "UPDATE reservation SET status = ? WHERE uuid = ? AND status = ? AND expires_at > UTC_TIMESTAMP()", Released, uuid, Active)
+}
+
+func (r *repository) ExpireStale(ctx context.Context) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE reservation SET status = ? WHERE status = ? AND expires_at <= UTC_TIMESTAMP()", Expired, Active)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// exec runs a single-row status transition and reports whether the row was changed.
+func (r *repository) exec(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
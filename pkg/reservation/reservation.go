@@ -0,0 +1,23 @@
+package reservation
+
+import "time"
+
+type Status string
+
+const (
+	// Active reservations hold stock until they expire.
+	Active    Status = "active"
+	Confirmed Status = "confirmed"
+	Released  Status = "released"
+	Expired   Status = "expired"
+)
+
+type Reservation struct {
+	ReservationID int       `json:"-"`
+	UUID          string    `json:"uuid"`
+	ProductUUID   string    `json:"product_uuid"`
+	Quantity      int       `json:"quantity"`
+	Status        Status    `json:"status"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
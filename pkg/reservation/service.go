@@ -0,0 +1,143 @@
+package reservation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"coding-challenge-go/pkg/product"
+)
+
+const (
+	maxTTL = 24 * time.Hour
+)
+
+type (
+	Service interface {
+		// Create holds quantity units of a product for ttl. A zero ttl uses the default TTL.
+		Create(ctx context.Context, productUUID string, quantity int, ttl time.Duration) (*Reservation, error)
+		FindByUUID(ctx context.Context, uuid string) (*Reservation, error)
+		// Confirm turns the hold into a permanent stock decrement.
+		Confirm(ctx context.Context, uuid string) (*Reservation, error)
+		// Release gives the held units back without touching stock.
+		Release(ctx context.Context, uuid string) (*Reservation, error)
+		// ExpireStale marks every active reservation past its expiry as expired.
+		ExpireStale(ctx context.Context) (int64, error)
+	}
+
+	Repository interface {
+		// Create inserts an active reservation when enough stock is available and
+		// fills in the stored fields.
+		Create(ctx context.Context, reservation *Reservation, ttlSeconds int) error
+		FindByUUID(ctx context.Context, uuid string) (*Reservation, error)
+		// Release reports false when the reservation was not active. Confirming is done
+		// by the product repository, in the transaction of the stock decrement.
+		Release(ctx context.Context, uuid string) (bool, error)
+		ExpireStale(ctx context.Context) (int64, error)
+	}
+
+	service struct {
+		repo       Repository
+		productSvc product.Service
+		defaultTTL time.Duration
+	}
+)
+
+func NewService(repo Repository, productSvc product.Service, defaultTTL time.Duration) Service {
+	return &service{
+		repo:       repo,
+		productSvc: productSvc,
+		defaultTTL: defaultTTL,
+	}
+}
+
+func (s *service) Create(ctx context.Context, productUUID string, quantity int, ttl time.Duration) (*Reservation, error) {
+	if ttl == 0 {
+		ttl = s.defaultTTL
+	}
+	if quantity <= 0 {
+		return nil, NewInvalidReservationError("quantity must be positive")
+	}
+	if ttl < time.Second || ttl > maxTTL {
+		return nil, NewInvalidReservationError(fmt.Sprintf("ttl must be between 1s and %s", maxTTL))
+	}
+
+	reservation := &Reservation{
+		UUID:        uuid.New().String(),
+		ProductUUID: productUUID,
+		Quantity:    quantity,
+	}
+	if err := s.repo.Create(ctx, reservation, int(ttl/time.Second)); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+func (s *service) FindByUUID(ctx context.Context, uuid string) (*Reservation, error) {
+	reservation, err := s.repo.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if reservation == nil {
+		return nil, NewReservationNotFoundError(uuid)
+	}
+	return reservation, nil
+}
+
+func (s *service) Confirm(ctx context.Context, uuid string) (*Reservation, error) {
+	reservation, err := s.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	// The reservation is confirmed in the transaction of the decrement, so its units
+	// stay held until they have left the stock.
+	_, err = s.productSvc.AdjustStock(ctx, reservation.ProductUUID, &product.StockAdjustment{
+		Delta:       -reservation.Quantity,
+		Reason:      fmt.Sprintf("reservation %s confirmed", uuid),
+		Code:        product.StockReasonSale,
+		Reservation: uuid,
+	})
+	if _, ok := err.(*product.ReservationNotActiveError); ok {
+		return nil, s.notActiveError(ctx, uuid)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reservation.Status = Confirmed
+	return reservation, nil
+}
+
+func (s *service) Release(ctx context.Context, uuid string) (*Reservation, error) {
+	reservation, err := s.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.Release(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, s.notActiveError(ctx, uuid)
+	}
+
+	reservation.Status = Released
+	return reservation, nil
+}
+
+func (s *service) ExpireStale(ctx context.Context) (int64, error) {
+	return s.repo.ExpireStale(ctx)
+}
+
+// notActiveError reports the state that made a transition fail.
+func (s *service) notActiveError(ctx context.Context, uuid string) error {
+	reservation, err := s.FindByUUID(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	return NewReservationNotActiveError(uuid, reservation.Status)
+}
@@ -0,0 +1,191 @@
+package reservation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/product"
+)
+
+func Test_serviceCreate(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity int
+		ttl      time.Duration
+		wantTTL  int
+		wantErr  error
+	}{
+		{
+			name:     "test create with default ttl",
+			quantity: 2,
+			wantTTL:  900,
+		},
+		{
+			name:     "test create with ttl",
+			quantity: 2,
+			ttl:      time.Minute,
+			wantTTL:  60,
+		},
+		{
+			name:     "test create with zero quantity",
+			quantity: 0,
+			wantErr:  NewInvalidReservationError("quantity must be positive"),
+		},
+		{
+			name:     "test create with too long ttl",
+			quantity: 1,
+			ttl:      48 * time.Hour,
+			wantErr:  NewInvalidReservationError("ttl must be between 1s and 24h0m0s"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepositoryMock()
+			svc := NewService(repo, nil, 15*time.Minute)
+
+			got, err := svc.Create(context.Background(), "p1", tt.quantity, tt.ttl)
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr != nil {
+				return
+			}
+			assert.Equal(t, Active, got.Status)
+			assert.Equal(t, tt.quantity, got.Quantity)
+			assert.Equal(t, tt.wantTTL, repo.ttls[got.UUID])
+		})
+	}
+}
+
+func Test_serviceConfirm(t *testing.T) {
+	tests := []struct {
+		name              string
+		status            Status
+		adjustErr         error
+		wantErr           error
+		wantStatus        Status
+		wantAdjustedDelta int
+	}{
+		{
+			name:              "test confirm decrements stock",
+			status:            Active,
+			wantStatus:        Confirmed,
+			wantAdjustedDelta: -3,
+		},
+		{
+			name:       "test confirm released reservation",
+			status:     Released,
+			wantErr:    NewReservationNotActiveError("r1", Released),
+			wantStatus: Released,
+		},
+		{
+			name:              "test confirm keeps hold when stock adjustment fails",
+			status:            Active,
+			adjustErr:         product.NewHeldStockError("p1", 2, -3),
+			wantErr:           product.NewHeldStockError("p1", 2, -3),
+			wantStatus:        Active,
+			wantAdjustedDelta: -3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepositoryMock()
+			repo.reservations["r1"] = &Reservation{UUID: "r1", ProductUUID: "p1", Quantity: 3, Status: tt.status}
+			adjustedDelta := 0
+			productSvc := &productServiceMock{
+				// Like the product repository, the reservation is only confirmed
+				// together with the decrement.
+				DoAdjustStockFunc: func(uuid string, adjustment *product.StockAdjustment) (*product.ProductInfo, error) {
+					if tt.adjustErr != nil {
+						adjustedDelta = adjustment.Delta
+						return nil, tt.adjustErr
+					}
+					if ok, _ := repo.transition(adjustment.Reservation, Active, Confirmed); !ok {
+						return nil, product.NewReservationNotActiveError(adjustment.Reservation)
+					}
+					adjustedDelta = adjustment.Delta
+					return &product.ProductInfo{}, nil
+				},
+			}
+			svc := NewService(repo, productSvc, time.Minute)
+
+			_, err := svc.Confirm(context.Background(), "r1")
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantStatus, repo.reservations["r1"].Status)
+			assert.Equal(t, tt.wantAdjustedDelta, adjustedDelta)
+		})
+	}
+}
+
+func Test_serviceRelease(t *testing.T) {
+	repo := newRepositoryMock()
+	repo.reservations["r1"] = &Reservation{UUID: "r1", ProductUUID: "p1", Quantity: 3, Status: Active}
+	svc := NewService(repo, nil, time.Minute)
+
+	got, err := svc.Release(context.Background(), "r1")
+	assert.NoError(t, err)
+	assert.Equal(t, Released, got.Status)
+
+	_, err = svc.Release(context.Background(), "r1")
+	assert.Equal(t, NewReservationNotActiveError("r1", Released), err)
+
+	_, err = svc.Release(context.Background(), "r2")
+	assert.Equal(t, NewReservationNotFoundError("r2"), err)
+}
+
+type repositoryMock struct {
+	reservations map[string]*Reservation
+	ttls         map[string]int
+}
+
+func newRepositoryMock() *repositoryMock {
+	return &repositoryMock{
+		reservations: map[string]*Reservation{},
+		ttls:         map[string]int{},
+	}
+}
+
+func (m *repositoryMock) Create(ctx context.Context, reservation *Reservation, ttlSeconds int) error {
+	reservation.Status = Active
+	m.ttls[reservation.UUID] = ttlSeconds
+	cp := *reservation
+	m.reservations[reservation.UUID] = &cp
+	return nil
+}
+
+func (m *repositoryMock) FindByUUID(ctx context.Context, uuid string) (*Reservation, error) {
+	r, ok := m.reservations[uuid]
+	if !ok {
+		return nil, nil
+	}
+	cp := *r
+	return &cp, nil
+}
+
+func (m *repositoryMock) transition(uuid string, from Status, to Status) (bool, error) {
+	r, ok := m.reservations[uuid]
+	if !ok || r.Status != from {
+		return false, nil
+	}
+	r.Status = to
+	return true, nil
+}
+
+func (m *repositoryMock) Release(ctx context.Context, uuid string) (bool, error) {
+	return m.transition(uuid, Active, Released)
+}
+
+func (m *repositoryMock) ExpireStale(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// productServiceMock only implements the calls the reservation service makes.
+type productServiceMock struct {
+	product.Service
+	DoAdjustStockFunc func(uuid string, adjustment *product.StockAdjustment) (*product.ProductInfo, error)
+}
+
+func (m *productServiceMock) AdjustStock(ctx context.Context, uuid string, adjustment *product.StockAdjustment) (*product.ProductInfo, error) {
+	return m.DoAdjustStockFunc(uuid, adjustment)
+}
@@ -0,0 +1,47 @@
+package schedule
+
+import "fmt"
+
+type ChangeNotFoundError struct {
+	id string
+}
+
+func (e ChangeNotFoundError) Error() string {
+	return fmt.Sprintf("Scheduled change is not found with id=%s", e.id)
+}
+
+func NewChangeNotFoundError(uuid string) error {
+	return &ChangeNotFoundError{
+		id: uuid,
+	}
+}
+
+type InvalidChangeError struct {
+	reason string
+}
+
+func (e InvalidChangeError) Error() string {
+	return fmt.Sprintf("Scheduled change is invalid: %s", e.reason)
+}
+
+func NewInvalidChangeError(reason string) error {
+	return &InvalidChangeError{
+		reason: reason,
+	}
+}
+
+type ChangeNotPendingError struct {
+	id     string
+	status Status
+}
+
+func (e ChangeNotPendingError) Error() string {
+	return fmt.Sprintf("Scheduled change id=%s is %s and can no longer be changed", e.id, e.status)
+}
+
+func NewChangeNotPendingError(uuid string, status Status) error {
+	return &ChangeNotPendingError{
+		id:     uuid,
+		status: status,
+	}
+}
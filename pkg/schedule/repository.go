@@ -0,0 +1,187 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"coding-challenge-go/pkg/product"
+)
+
+const (
+	selectChangeQuery = "SELECT c.id_scheduled_change, c.uuid, p.uuid, c.patch, c.effective_at, c.status, c.attempts, " +
+		"c.next_attempt_at, COALESCE(c.last_error, ''), c.applied_at, c.created_by, c.created_at FROM scheduled_change c " +
+		"INNER JOIN product p ON(p.id_product = c.fk_product)"
+)
+
+func NewRepository(db *sql.DB) Repository {
+	return &repository{db: db}
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+func (r *repository) List(ctx context.Context, productUUID string, status Status) ([]*Change, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	if productUUID != "" {
+		conditions = append(conditions, "p.uuid = ?")
+		args = append(args, productUUID)
+	}
+	if status != "" {
+		conditions = append(conditions, "c.status = ?")
+		args = append(args, status)
+	}
+
+	query := selectChangeQuery
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return r.query(ctx, query+" ORDER BY c.effective_at, c.id_scheduled_change", args...)
+}
+
+func (r *repository) FindByUUID(ctx context.Context, uuid string) (*Change, error) {
+	changes, err := r.query(ctx, selectChangeQuery+" WHERE c.uuid = ?", uuid)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+	return changes[0], nil
+}
+
+func (r *repository) Create(ctx context.Context, change *Change) error {
+	result, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO scheduled_change (uuid, fk_product, patch, effective_at, status, attempts, next_attempt_at, created_by, created_at) "+
+			"SELECT ?, id_product, ?, ?, ?, ?, ?, ?, ? FROM product WHERE uuid = ? AND deleted_at IS NULL",
+		change.UUID, []byte(change.Patch), change.EffectiveAt, change.Status, change.Attempts, change.NextAttemptAt,
+		change.CreatedBy, change.CreatedAt, change.ProductUUID,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return product.NewProductNotFoundError(change.ProductUUID)
+	}
+	return nil
+}
+
+func (r *repository) Update(ctx context.Context, change *Change) (bool, error) {
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE scheduled_change SET patch = ?, effective_at = ?, attempts = ?, next_attempt_at = ?, last_error = NULL "+
+			"WHERE uuid = ? AND status = ?",
+		[]byte(change.Patch), change.EffectiveAt, change.Attempts, change.NextAttemptAt, change.UUID, Pending,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *repository) Delete(ctx context.Context, uuid string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM scheduled_change WHERE uuid = ? AND status = ?", uuid, Pending)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *repository) ListDue(ctx context.Context, now time.Time, limit int) ([]*Change, error) {
+	return r.query(
+		ctx,
+		selectChangeQuery+" WHERE c.status IN (?, ?) AND c.next_attempt_at <= ? "+
+			"ORDER BY c.next_attempt_at, c.id_scheduled_change LIMIT ?",
+		Pending, Running, now, limit,
+	)
+}
+
+func (r *repository) Claim(ctx context.Context, change *Change, until time.Time) (bool, error) {
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE scheduled_change SET status = ?, attempts = attempts + 1, next_attempt_at = ? "+
+			"WHERE uuid = ? AND status = ? AND attempts = ? AND next_attempt_at = ? AND patch = CAST(? AS JSON)",
+		Running, until, change.UUID, change.Status, change.Attempts, change.NextAttemptAt, string(change.Patch),
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+	change.Status = Running
+	change.Attempts++
+	change.NextAttemptAt = until
+	return true, nil
+}
+
+func (r *repository) SaveOutcome(ctx context.Context, change *Change) (bool, error) {
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE scheduled_change SET status = ?, next_attempt_at = ?, last_error = ?, applied_at = ? "+
+			"WHERE uuid = ? AND status = ? AND attempts = ?",
+		change.Status, change.NextAttemptAt, nullString(change.LastError), change.AppliedAt, change.UUID, Running, change.Attempts,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *repository) query(ctx context.Context, query string, args ...interface{}) ([]*Change, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	changes := []*Change{}
+
+	for rows.Next() {
+		change := &Change{}
+		var patch []byte
+		err := rows.Scan(
+			&change.ChangeID, &change.UUID, &change.ProductUUID, &patch, &change.EffectiveAt, &change.Status, &change.Attempts,
+			&change.NextAttemptAt, &change.LastError, &change.AppliedAt, &change.CreatedBy, &change.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		change.Patch = patch
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,39 @@
+package schedule
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Status string
+
+const (
+	// Pending changes wait for their effective time, or for a retry after a failed attempt.
+	Pending Status = "pending"
+	// Running changes are claimed by a server applying them. A claim that is not
+	// resolved by its NextAttemptAt, e.g. because the server died, is taken over.
+	Running Status = "running"
+	Applied Status = "applied"
+	// Failed changes gave up after the last attempt allowed.
+	Failed Status = "failed"
+)
+
+// Change is a patch of a product that takes effect at EffectiveAt.
+type Change struct {
+	ChangeID    int    `json:"-"`
+	UUID        string `json:"uuid"`
+	ProductUUID string `json:"product_uuid"`
+	// Patch is a JSON Merge Patch of the product like PATCH /products/{uuid} takes.
+	// It may also set the status, e.g. {"status":"published"} for a launch.
+	Patch       json.RawMessage `json:"patch"`
+	EffectiveAt time.Time       `json:"effective_at"`
+	Status      Status          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	// NextAttemptAt is when a pending change is tried, which is later than
+	// EffectiveAt once an attempt failed, or when the claim of a running change expires.
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	AppliedAt     *time.Time `json:"applied_at,omitempty"`
+	CreatedBy     string     `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/periodic"
+)
+
+// Run applies due changes every interval until ctx is done.
+func Run(ctx context.Context, svc Service, interval time.Duration) {
+	periodic.Run(ctx, "scheduler", interval, func(ctx context.Context) {
+		applied, err := svc.ApplyDue(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("Fail to apply scheduled changes")
+			return
+		}
+		if applied > 0 {
+			log.Info().Msg(fmt.Sprintf("Applied %d scheduled changes", applied))
+		}
+	})
+}
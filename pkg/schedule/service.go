@@ -0,0 +1,315 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/product"
+	"coding-challenge-go/pkg/requestinfo"
+)
+
+const (
+	// dueBatchSize caps how many changes one run applies, so a backlog is worked off over several runs.
+	dueBatchSize = 100
+	// claimTimeout is how long a server may take to apply a change it claimed
+	// before another one takes the change over.
+	claimTimeout = 5 * time.Minute
+)
+
+type (
+	Service interface {
+		// List returns the changes of a product, or of every product when productUUID
+		// is empty, ordered by effective time. A non-empty status selects only changes in it.
+		List(ctx context.Context, productUUID string, status Status) ([]*Change, error)
+		FindByUUID(ctx context.Context, uuid string) (*Change, error)
+		Create(ctx context.Context, change *Change) error
+		// Update replaces the patch and effective time of a pending change and starts
+		// its attempts over.
+		Update(ctx context.Context, change *Change) error
+		// Delete cancels a pending change.
+		Delete(ctx context.Context, uuid string) error
+		// ApplyDue applies the pending changes whose time has come and returns how many
+		// were applied. A change that fails is retried later until it runs out of attempts.
+		ApplyDue(ctx context.Context) (int, error)
+	}
+
+	Repository interface {
+		// List returns changes ordered by effective time, only those of productUUID and
+		// in status unless they are empty.
+		List(ctx context.Context, productUUID string, status Status) ([]*Change, error)
+		FindByUUID(ctx context.Context, uuid string) (*Change, error)
+		Create(ctx context.Context, change *Change) error
+		// Update stores the patch, effective time and attempts of a change while it is
+		// pending and reports false otherwise.
+		Update(ctx context.Context, change *Change) (bool, error)
+		// Delete removes a pending change and reports false when there was none.
+		Delete(ctx context.Context, uuid string) (bool, error)
+		// ListDue returns up to limit pending changes whose next attempt is due at now,
+		// and running changes whose claim expired, the longest due first.
+		ListDue(ctx context.Context, now time.Time, limit int) ([]*Change, error)
+		// Claim marks change running until until and counts the attempt, unless its
+		// status, attempts, next attempt or patch changed since it was read. It
+		// reports false when another server or an update got there first.
+		Claim(ctx context.Context, change *Change, until time.Time) (bool, error)
+		// SaveOutcome stores the status, next attempt, error and applied time of a
+		// change while it is still claimed by this attempt, and reports false otherwise.
+		SaveOutcome(ctx context.Context, change *Change) (bool, error)
+	}
+
+	service struct {
+		repo        Repository
+		productSvc  product.Service
+		maxAttempts int
+		retryDelay  time.Duration
+		now         func() time.Time
+	}
+)
+
+// NewService returns a service that tries a change up to maxAttempts times and
+// waits retryDelay longer after every failed attempt.
+func NewService(repo Repository, productSvc product.Service, maxAttempts int, retryDelay time.Duration) Service {
+	return &service{
+		repo:        repo,
+		productSvc:  productSvc,
+		maxAttempts: maxAttempts,
+		retryDelay:  retryDelay,
+		now:         time.Now,
+	}
+}
+
+func (s *service) List(ctx context.Context, productUUID string, status Status) ([]*Change, error) {
+	return s.repo.List(ctx, productUUID, status)
+}
+
+func (s *service) FindByUUID(ctx context.Context, uuid string) (*Change, error) {
+	change, err := s.repo.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if change == nil {
+		return nil, NewChangeNotFoundError(uuid)
+	}
+	return change, nil
+}
+
+func (s *service) Create(ctx context.Context, change *Change) error {
+	if err := validate(change); err != nil {
+		return err
+	}
+	if _, err := s.productSvc.FindByUUID(ctx, change.ProductUUID); err != nil {
+		return err
+	}
+
+	change.UUID = uuid.New().String()
+	change.Status = Pending
+	change.Attempts = 0
+	change.NextAttemptAt = change.EffectiveAt
+	change.CreatedBy = requestinfo.Actor(ctx)
+	change.CreatedAt = s.now().UTC().Truncate(time.Second)
+	return s.repo.Create(ctx, change)
+}
+
+func (s *service) Update(ctx context.Context, change *Change) error {
+	existing, err := s.FindByUUID(ctx, change.UUID)
+	if err != nil {
+		return err
+	}
+	if existing.Status != Pending {
+		return NewChangeNotPendingError(existing.UUID, existing.Status)
+	}
+	if err := validate(change); err != nil {
+		return err
+	}
+
+	updated := *existing
+	updated.Patch = change.Patch
+	updated.EffectiveAt = change.EffectiveAt
+	updated.NextAttemptAt = change.EffectiveAt
+	updated.Attempts = 0
+	updated.LastError = ""
+	ok, err := s.repo.Update(ctx, &updated)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// The scheduler got to it first.
+		return s.notPendingError(ctx, change.UUID)
+	}
+	*change = updated
+	return nil
+}
+
+func (s *service) Delete(ctx context.Context, uuid string) error {
+	deleted, err := s.repo.Delete(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return s.notPendingError(ctx, uuid)
+	}
+	return nil
+}
+
+// notPendingError reports why a pending change was not found.
+func (s *service) notPendingError(ctx context.Context, uuid string) error {
+	change, err := s.FindByUUID(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	return NewChangeNotPendingError(uuid, change.Status)
+}
+
+// ApplyDue claims every due change before applying it, so two servers running
+// the scheduler never apply the same change at the same time.
+func (s *service) ApplyDue(ctx context.Context) (int, error) {
+	changes, err := s.repo.ListDue(ctx, s.now().UTC(), dueBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, change := range changes {
+		claimed, err := s.repo.Claim(ctx, change, s.now().UTC().Truncate(time.Second).Add(claimTimeout))
+		if err != nil {
+			return applied, err
+		}
+		if !claimed {
+			continue
+		}
+
+		s.attempt(ctx, change)
+		saved, err := s.repo.SaveOutcome(ctx, change)
+		if err != nil {
+			return applied, err
+		}
+		if !saved {
+			// The claim expired and another server took the change over.
+			log.Error().Msg(fmt.Sprintf("Fail to save outcome of scheduled change %s, another server took it over during attempt %d", change.UUID, change.Attempts))
+			continue
+		}
+		if change.Status == Applied {
+			applied++
+		}
+	}
+	return applied, nil
+}
+
+// attempt applies a claimed change once and records the outcome in it.
+func (s *service) attempt(ctx context.Context, change *Change) {
+	// The product history shows who scheduled the change and which change it was.
+	ctx = requestinfo.WithActor(ctx, change.CreatedBy)
+	ctx = requestinfo.WithRequestID(ctx, change.UUID)
+
+	err := s.apply(ctx, change)
+	now := s.now().UTC().Truncate(time.Second)
+	switch {
+	case err == nil:
+		change.Status = Applied
+		change.AppliedAt = &now
+		change.LastError = ""
+	case change.Attempts >= s.maxAttempts:
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to apply scheduled change %s, giving up after %d attempts", change.UUID, change.Attempts))
+		change.Status = Failed
+		change.LastError = err.Error()
+	default:
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to apply scheduled change %s, attempt %d", change.UUID, change.Attempts))
+		change.Status = Pending
+		change.LastError = err.Error()
+		change.NextAttemptAt = now.Add(time.Duration(change.Attempts) * s.retryDelay)
+	}
+}
+
+// apply updates the product fields of the patch and then moves the product to
+// the status of the patch, if it sets one.
+func (s *service) apply(ctx context.Context, change *Change) error {
+	patch, status, err := parsePatch(change.Patch)
+	if err != nil {
+		return err
+	}
+
+	if patch != nil {
+		info, err := s.productSvc.FindByUUID(ctx, change.ProductUUID)
+		if err != nil {
+			return err
+		}
+		// Update fails with a PreconditionFailedError when the product changes in
+		// between, and the next attempt patches the newer version.
+		p := *info.Product
+		patch.Apply(&p)
+		if err := s.productSvc.Update(ctx, &p); err != nil {
+			return err
+		}
+	}
+	if status != "" {
+		if _, err := s.productSvc.SetStatus(ctx, change.ProductUUID, status, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validate(change *Change) error {
+	if change.EffectiveAt.IsZero() {
+		return NewInvalidChangeError("effective_at is required")
+	}
+	change.EffectiveAt = change.EffectiveAt.UTC().Truncate(time.Second)
+	_, _, err := parsePatch(change.Patch)
+	return err
+}
+
+// parsePatch splits a patch into the product fields, which are applied through
+// product.Service.Update, and the status, which only changes through SetStatus.
+// Update keeps fields that are missing, so a scheduled change cannot remove any.
+func parsePatch(data json.RawMessage) (*product.Patch, product.Status, error) {
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, "", NewInvalidChangeError("patch must be a JSON object")
+	}
+
+	var status product.Status
+	if raw, ok := members["status"]; ok {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, "", NewInvalidChangeError("status must be a string")
+		}
+		var err error
+		if status, err = product.ParseStatus(s); err != nil {
+			return nil, "", NewInvalidChangeError(err.Error())
+		}
+		delete(members, "status")
+	}
+	if len(members) == 0 {
+		if status == "" {
+			return nil, "", NewInvalidChangeError("patch must change at least one field")
+		}
+		return nil, status, nil
+	}
+
+	fields, err := json.Marshal(members)
+	if err != nil {
+		return nil, "", err
+	}
+	patch, err := product.ParseMergePatch(fields)
+	if err != nil {
+		return nil, "", NewInvalidChangeError(err.Error())
+	}
+	if removesFields(patch) {
+		return nil, "", NewInvalidChangeError("fields cannot be removed by a scheduled change")
+	}
+	if err := patch.Validate(); err != nil {
+		return nil, "", err
+	}
+	return patch, status, nil
+}
+
+func removesFields(patch *product.Patch) bool {
+	return (patch.SKU != nil && *patch.SKU == "") ||
+		(patch.GTIN != nil && *patch.GTIN == "") ||
+		(patch.Price != nil && patch.Price.Remove) ||
+		(patch.Attributes != nil && (patch.Attributes.Remove || len(patch.Attributes.Delete) > 0))
+}
@@ -0,0 +1,353 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/product"
+	"coding-challenge-go/pkg/requestinfo"
+)
+
+var launch = time.Date(2021, 9, 1, 8, 0, 0, 0, time.UTC)
+
+func Test_serviceCreate(t *testing.T) {
+	negative := -5
+	tests := []struct {
+		name        string
+		patch       string
+		effectiveAt time.Time
+		wantErr     error
+	}{
+		{
+			name:        "test schedule stock and publish",
+			patch:       `{"stock":100,"status":"published"}`,
+			effectiveAt: launch,
+		},
+		{
+			name:        "test schedule status only",
+			patch:       `{"status":"archived"}`,
+			effectiveAt: launch,
+		},
+		{
+			name:    "test missing effective time",
+			patch:   `{"stock":100}`,
+			wantErr: NewInvalidChangeError("effective_at is required"),
+		},
+		{
+			name:        "test empty patch",
+			patch:       `{}`,
+			effectiveAt: launch,
+			wantErr:     NewInvalidChangeError("patch must change at least one field"),
+		},
+		{
+			name:        "test unknown status",
+			patch:       `{"status":"deleted"}`,
+			effectiveAt: launch,
+			wantErr:     NewInvalidChangeError(product.NewInvalidStatusError("deleted").Error()),
+		},
+		{
+			name:        "test field that cannot be patched",
+			patch:       `{"seller_uuid":"s2"}`,
+			effectiveAt: launch,
+			wantErr:     NewInvalidChangeError(product.NewInvalidPatchError("seller_uuid", "field cannot be patched").Error()),
+		},
+		{
+			name:        "test removing a field",
+			patch:       `{"price":null}`,
+			effectiveAt: launch,
+			wantErr:     NewInvalidChangeError("fields cannot be removed by a scheduled change"),
+		},
+		{
+			name:        "test field that breaks a rule",
+			patch:       `{"stock":-5}`,
+			effectiveAt: launch,
+			wantErr:     (&product.Patch{Stock: &negative}).Validate(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepositoryMock()
+			svc := NewService(repo, newProductServiceMock(), 3, time.Minute)
+
+			ctx := requestinfo.WithActor(context.Background(), "merch@gfg.com")
+			change := &Change{ProductUUID: "p1", Patch: json.RawMessage(tt.patch), EffectiveAt: tt.effectiveAt}
+			err := svc.Create(ctx, change)
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr != nil {
+				assert.Empty(t, repo.changes)
+				return
+			}
+			assert.Equal(t, Pending, repo.changes[change.UUID].Status)
+			assert.Equal(t, launch, repo.changes[change.UUID].NextAttemptAt)
+			assert.Equal(t, "merch@gfg.com", repo.changes[change.UUID].CreatedBy)
+		})
+	}
+
+	svc := NewService(newRepositoryMock(), newProductServiceMock(), 3, time.Minute)
+	err := svc.Create(context.Background(), &Change{ProductUUID: "p2", Patch: json.RawMessage(`{"stock":1}`), EffectiveAt: launch})
+	assert.Equal(t, product.NewProductNotFoundError("p2"), err)
+}
+
+func Test_serviceApplyDue(t *testing.T) {
+	repo := newRepositoryMock()
+	productSvc := newProductServiceMock()
+	svc := NewService(repo, productSvc, 3, time.Minute).(*service)
+	svc.now = func() time.Time { return launch }
+
+	repo.add(&Change{UUID: "c1", ProductUUID: "p1", Patch: json.RawMessage(`{"stock":100,"status":"published"}`), NextAttemptAt: launch})
+	repo.add(&Change{UUID: "c2", ProductUUID: "p1", Patch: json.RawMessage(`{"name":"Later"}`), NextAttemptAt: launch.Add(time.Hour)})
+
+	applied, err := svc.ApplyDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, applied)
+
+	assert.Equal(t, 100, productSvc.products["p1"].Stock)
+	assert.Equal(t, "Berlin New Shirt", productSvc.products["p1"].Name)
+	assert.Equal(t, product.StatusPublished, productSvc.products["p1"].Status)
+	assert.Equal(t, []string{"merch@gfg.com"}, productSvc.actors)
+
+	assert.Equal(t, Applied, repo.changes["c1"].Status)
+	assert.Equal(t, 1, repo.changes["c1"].Attempts)
+	assert.Equal(t, &launch, repo.changes["c1"].AppliedAt)
+	assert.Equal(t, Pending, repo.changes["c2"].Status)
+	assert.Equal(t, 0, repo.changes["c2"].Attempts)
+}
+
+func Test_serviceApplyDueRetries(t *testing.T) {
+	repo := newRepositoryMock()
+	productSvc := newProductServiceMock()
+	productSvc.updateErr = errors.New("connection refused")
+	now := launch
+	svc := NewService(repo, productSvc, 3, time.Minute).(*service)
+	svc.now = func() time.Time { return now }
+
+	repo.add(&Change{UUID: "c1", ProductUUID: "p1", Patch: json.RawMessage(`{"stock":100}`), NextAttemptAt: launch})
+
+	applied, err := svc.ApplyDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, applied)
+	assert.Equal(t, Pending, repo.changes["c1"].Status)
+	assert.Equal(t, 1, repo.changes["c1"].Attempts)
+	assert.Equal(t, "connection refused", repo.changes["c1"].LastError)
+	assert.Equal(t, launch.Add(time.Minute), repo.changes["c1"].NextAttemptAt)
+
+	// Not due again before the retry delay has passed.
+	_, err = svc.ApplyDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, repo.changes["c1"].Attempts)
+
+	now = launch.Add(time.Minute)
+	_, err = svc.ApplyDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, repo.changes["c1"].Attempts)
+	assert.Equal(t, now.Add(2*time.Minute), repo.changes["c1"].NextAttemptAt)
+
+	now = now.Add(2 * time.Minute)
+	_, err = svc.ApplyDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, Failed, repo.changes["c1"].Status)
+	assert.Equal(t, 3, repo.changes["c1"].Attempts)
+	assert.Equal(t, 44, productSvc.products["p1"].Stock)
+}
+
+func Test_serviceApplyDueClaims(t *testing.T) {
+	repo := newRepositoryMock()
+	productSvc := newProductServiceMock()
+	now := launch
+	svc := NewService(repo, productSvc, 3, time.Minute).(*service)
+	svc.now = func() time.Time { return now }
+
+	// A change updated after it was listed is not applied with the old patch.
+	repo.add(&Change{UUID: "c1", ProductUUID: "p1", Patch: json.RawMessage(`{"stock":100}`), NextAttemptAt: launch})
+	repo.beforeClaim = func() {
+		repo.changes["c1"].Patch = json.RawMessage(`{"stock":120}`)
+	}
+	applied, err := svc.ApplyDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, applied)
+	assert.Equal(t, 44, productSvc.products["p1"].Stock)
+	assert.Equal(t, Pending, repo.changes["c1"].Status)
+
+	repo.beforeClaim = nil
+	applied, err = svc.ApplyDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, applied)
+	assert.Equal(t, 120, productSvc.products["p1"].Stock)
+
+	// A running change is left alone until its claim expires, and then taken over.
+	repo.add(&Change{UUID: "c2", ProductUUID: "p1", Patch: json.RawMessage(`{"stock":7}`), Status: Running, Attempts: 1,
+		NextAttemptAt: launch.Add(claimTimeout)})
+	applied, err = svc.ApplyDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, applied)
+
+	now = launch.Add(claimTimeout)
+	applied, err = svc.ApplyDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, applied)
+	assert.Equal(t, 7, productSvc.products["p1"].Stock)
+	assert.Equal(t, Applied, repo.changes["c2"].Status)
+	assert.Equal(t, 2, repo.changes["c2"].Attempts)
+}
+
+func Test_serviceUpdateAndDelete(t *testing.T) {
+	repo := newRepositoryMock()
+	svc := NewService(repo, newProductServiceMock(), 3, time.Minute)
+
+	repo.add(&Change{UUID: "c1", ProductUUID: "p1", Patch: json.RawMessage(`{"stock":100}`), Status: Pending, Attempts: 2, LastError: "timeout"})
+	repo.add(&Change{UUID: "c2", ProductUUID: "p1", Patch: json.RawMessage(`{"stock":100}`), Status: Applied})
+
+	change := &Change{UUID: "c1", Patch: json.RawMessage(`{"stock":120}`), EffectiveAt: launch}
+	assert.NoError(t, svc.Update(context.Background(), change))
+	assert.Equal(t, "p1", change.ProductUUID)
+	assert.Equal(t, json.RawMessage(`{"stock":120}`), repo.changes["c1"].Patch)
+	assert.Equal(t, 0, repo.changes["c1"].Attempts)
+	assert.Equal(t, "", repo.changes["c1"].LastError)
+	assert.Equal(t, launch, repo.changes["c1"].NextAttemptAt)
+
+	err := svc.Update(context.Background(), &Change{UUID: "c2", Patch: json.RawMessage(`{"stock":120}`), EffectiveAt: launch})
+	assert.Equal(t, NewChangeNotPendingError("c2", Applied), err)
+	assert.Equal(t, NewChangeNotPendingError("c2", Applied), svc.Delete(context.Background(), "c2"))
+	assert.Equal(t, NewChangeNotFoundError("c3"), svc.Delete(context.Background(), "c3"))
+
+	assert.NoError(t, svc.Delete(context.Background(), "c1"))
+	assert.NotContains(t, repo.changes, "c1")
+}
+
+type repositoryMock struct {
+	changes map[string]*Change
+	// beforeClaim runs between listing the due changes and claiming them.
+	beforeClaim func()
+}
+
+func newRepositoryMock() *repositoryMock {
+	return &repositoryMock{changes: map[string]*Change{}}
+}
+
+func (m *repositoryMock) add(change *Change) {
+	if change.Status == "" {
+		change.Status = Pending
+	}
+	if change.CreatedBy == "" {
+		change.CreatedBy = "merch@gfg.com"
+	}
+	m.changes[change.UUID] = change
+}
+
+func (m *repositoryMock) List(ctx context.Context, productUUID string, status Status) ([]*Change, error) {
+	return nil, nil
+}
+
+func (m *repositoryMock) FindByUUID(ctx context.Context, uuid string) (*Change, error) {
+	change, ok := m.changes[uuid]
+	if !ok {
+		return nil, nil
+	}
+	cp := *change
+	return &cp, nil
+}
+
+func (m *repositoryMock) Create(ctx context.Context, change *Change) error {
+	cp := *change
+	m.changes[change.UUID] = &cp
+	return nil
+}
+
+func (m *repositoryMock) Update(ctx context.Context, change *Change) (bool, error) {
+	if existing, ok := m.changes[change.UUID]; !ok || existing.Status != Pending {
+		return false, nil
+	}
+	cp := *change
+	m.changes[change.UUID] = &cp
+	return true, nil
+}
+
+func (m *repositoryMock) Delete(ctx context.Context, uuid string) (bool, error) {
+	if existing, ok := m.changes[uuid]; !ok || existing.Status != Pending {
+		return false, nil
+	}
+	delete(m.changes, uuid)
+	return true, nil
+}
+
+func (m *repositoryMock) ListDue(ctx context.Context, now time.Time, limit int) ([]*Change, error) {
+	var due []*Change
+	for _, change := range m.changes {
+		if (change.Status == Pending || change.Status == Running) && !change.NextAttemptAt.After(now) {
+			cp := *change
+			due = append(due, &cp)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if m.beforeClaim != nil {
+		m.beforeClaim()
+	}
+	return due, nil
+}
+
+func (m *repositoryMock) Claim(ctx context.Context, change *Change, until time.Time) (bool, error) {
+	existing, ok := m.changes[change.UUID]
+	if !ok || existing.Status != change.Status || existing.Attempts != change.Attempts ||
+		!existing.NextAttemptAt.Equal(change.NextAttemptAt) || string(existing.Patch) != string(change.Patch) {
+		return false, nil
+	}
+	change.Status = Running
+	change.Attempts++
+	change.NextAttemptAt = until
+	cp := *change
+	m.changes[change.UUID] = &cp
+	return true, nil
+}
+
+func (m *repositoryMock) SaveOutcome(ctx context.Context, change *Change) (bool, error) {
+	if existing, ok := m.changes[change.UUID]; !ok || existing.Status != Running || existing.Attempts != change.Attempts {
+		return false, nil
+	}
+	cp := *change
+	m.changes[change.UUID] = &cp
+	return true, nil
+}
+
+// productServiceMock only implements the calls the schedule service makes.
+type productServiceMock struct {
+	product.Service
+	products  map[string]*product.Product
+	updateErr error
+	actors    []string
+}
+
+func newProductServiceMock() *productServiceMock {
+	return &productServiceMock{products: map[string]*product.Product{
+		"p1": {UUID: "p1", Name: "Berlin New Shirt", Stock: 44, Status: product.StatusDraft, Version: 1},
+	}}
+}
+
+func (m *productServiceMock) FindByUUID(ctx context.Context, uuid string) (*product.ProductInfo, error) {
+	p, ok := m.products[uuid]
+	if !ok {
+		return nil, product.NewProductNotFoundError(uuid)
+	}
+	cp := *p
+	return &product.ProductInfo{Product: &cp}, nil
+}
+
+func (m *productServiceMock) Update(ctx context.Context, p *product.Product) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	m.actors = append(m.actors, requestinfo.Actor(ctx))
+	cp := *p
+	m.products[p.UUID] = &cp
+	return nil
+}
+
+func (m *productServiceMock) SetStatus(ctx context.Context, uuid string, status product.Status, version int) (*product.ProductInfo, error) {
+	m.products[uuid].Status = status
+	return &product.ProductInfo{Product: m.products[uuid]}, nil
+}
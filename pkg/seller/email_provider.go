@@ -1,5 +1,7 @@
 package seller
 
+import "coding-challenge-go/pkg/money"
+
 func NewEmailProvider() NotiProvider {
 	return &emailProvider{}
 }
@@ -14,6 +16,18 @@ func (ep *emailProvider) StockChanged(oldStock int, newStock int, product string
 
 }
 
+func (ep *emailProvider) StockSummary(changes []StockChange, sl *Seller) {
+
+}
+
+func (ep *emailProvider) PriceDropped(oldPrice money.Money, newPrice money.Money, product string, subscribers []*Subscriber) {
+
+}
+
+func (ep *emailProvider) StockLow(stock int, product string, sl *Seller) {
+
+}
+
 func (ep *emailProvider) Type() ProviderType {
 	return Email
 }
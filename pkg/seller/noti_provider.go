@@ -1,5 +1,7 @@
 package seller
 
+import "coding-challenge-go/pkg/money"
+
 type ProviderType int
 
 //go:generate enumer -type=ProviderType -transform=kebab -text -yaml -output=z_provider_type_enumer.go
@@ -11,6 +13,28 @@ const (
 type (
 	NotiProvider interface {
 		StockChanged(oldStock int, newStock int, product string, sl *Seller)
+		// StockSummary is sent once for a batch of stock changes instead of a StockChanged per product.
+		StockSummary(changes []StockChange, sl *Seller)
+		// PriceDropped is sent to the subscribers of a product when its price falls by
+		// at least the configured alert percentage.
+		PriceDropped(oldPrice money.Money, newPrice money.Money, product string, subscribers []*Subscriber)
+		// StockLow is sent when the stock of a product or bundle falls to the low-stock threshold.
+		StockLow(stock int, product string, sl *Seller)
 		Type() ProviderType
 	}
+
+	// Subscriber asked to hear about price drops of a product. A provider delivers
+	// to the contact it supports and skips subscribers without one.
+	Subscriber struct {
+		UUID  string
+		Email string
+		Phone string
+	}
+
+	// StockChange is the stock of one product before and after a batch.
+	StockChange struct {
+		Product  string
+		OldStock int
+		NewStock int
+	}
 )
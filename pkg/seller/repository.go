@@ -76,6 +76,7 @@ func (r *repository) TopByProduct(ctx context.Context, limit int) ([]*Seller, er
 							seller
 								RIGHT JOIN
 							product ON product.fk_seller = seller.id_seller
+						WHERE product.deleted_at IS NULL
 						GROUP BY seller.id_seller
 						ORDER BY SUM(product.stock) DESC)
 				LIMIT ?
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/money"
 )
 
 func NewSMSProvider() NotiProvider {
@@ -20,6 +22,23 @@ func (ep *smsProvider) StockChanged(oldStock int, newStock int, product string,
 	log.Info().Msg(fmt.Sprintf("%s Warning sent to %s (Phone: %s): %s Product stock changed", "SMS", sl.UUID, sl.Phone, product))
 }
 
+func (ep *smsProvider) StockSummary(changes []StockChange, sl *Seller) {
+	log.Info().Msg(fmt.Sprintf("%s Warning sent to %s (Phone: %s): Stock of %d products changed", "SMS", sl.UUID, sl.Phone, len(changes)))
+}
+
+func (ep *smsProvider) PriceDropped(oldPrice money.Money, newPrice money.Money, product string, subscribers []*Subscriber) {
+	for _, sub := range subscribers {
+		if sub.Phone == "" {
+			continue
+		}
+		log.Info().Msg(fmt.Sprintf("%s Alert sent to subscriber %s (Phone: %s): %s Product price dropped from %s to %s", "SMS", sub.UUID, sub.Phone, product, oldPrice, newPrice))
+	}
+}
+
+func (ep *smsProvider) StockLow(stock int, product string, sl *Seller) {
+	log.Info().Msg(fmt.Sprintf("%s Alert sent to %s (Phone: %s): %s Product stock is low at %d", "SMS", sl.UUID, sl.Phone, product, stock))
+}
+
 func (ep *smsProvider) Type() ProviderType {
 	return SMS
 }
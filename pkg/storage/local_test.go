@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_localStorage(t *testing.T) {
+	root, err := ioutil.TempDir("", "storage")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	store := NewLocalStorage(root)
+	ctx := context.Background()
+
+	assert.NoError(t, store.Put(ctx, "products/p1/images/i1/original", strings.NewReader("original")))
+	assert.NoError(t, store.Put(ctx, "products/p1/images/i1/small", strings.NewReader("small")))
+
+	object, err := store.Open(ctx, "products/p1/images/i1/small")
+	assert.NoError(t, err)
+	data, err := ioutil.ReadAll(object)
+	assert.NoError(t, err)
+	assert.NoError(t, object.Close())
+	assert.Equal(t, "small", string(data))
+	assert.Equal(t, int64(5), object.Size)
+
+	assert.NoError(t, store.Delete(ctx, "products/p1/images/i1"))
+	_, err = store.Open(ctx, "products/p1/images/i1/original")
+	assert.Equal(t, NewObjectNotFoundError("products/p1/images/i1/original"), err)
+}
+
+func Test_localStoragePath(t *testing.T) {
+	store := &localStorage{root: "/srv/images"}
+
+	name, err := store.path("../../etc/passwd")
+	assert.NoError(t, err)
+	assert.Equal(t, "/srv/images/etc/passwd", name)
+
+	_, err = store.path("/")
+	assert.Error(t, err)
+}
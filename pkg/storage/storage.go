@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+type (
+	// Storage keeps binary objects under slash separated keys.
+	Storage interface {
+		// Put stores the content of r under key, replacing any object already there.
+		Put(ctx context.Context, key string, r io.Reader) error
+		// Open returns the object under key. The caller closes it.
+		Open(ctx context.Context, key string) (*Object, error)
+		// Delete removes every object whose key starts with prefix.
+		Delete(ctx context.Context, prefix string) error
+	}
+
+	Object struct {
+		io.ReadCloser
+		Size    int64
+		ModTime time.Time
+	}
+)
+
+type ObjectNotFoundError struct {
+	key string
+}
+
+func (e ObjectNotFoundError) Error() string {
+	return fmt.Sprintf("Object is not found with key=%s", e.key)
+}
+
+func NewObjectNotFoundError(key string) error {
+	return &ObjectNotFoundError{
+		key: key,
+	}
+}
@@ -1,11 +1,53 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 type AppConfig struct {
 	HTTPPort         int
 	MySQLConfig      MySQLConfig
 	NotiProdiverType string
+	// RequireIfMatch rejects product writes without an If-Match header.
+	RequireIfMatch bool
+	// AllowBackorders lets stock adjustments take stock below zero.
+	AllowBackorders bool
+	// ReservationTTL is how long a reservation holds stock when the client does not ask for a TTL.
+	ReservationTTL time.Duration
+	// ReservationReaperInterval is how often stale reservations are expired.
+	ReservationReaperInterval time.Duration
+	// ProductPurgeRetention is how long deleted products stay in the trash. Zero disables purging.
+	ProductPurgeRetention time.Duration
+	// ProductPurgeInterval is how often the trash is purged.
+	ProductPurgeInterval time.Duration
+	// PriceDropAlertPercent is the smallest price drop, in percent, that notifies subscribers. Zero disables the alert.
+	PriceDropAlertPercent float64
+	// LowStockThreshold is the stock at or below which a product or bundle alerts its seller. Zero disables the alert.
+	LowStockThreshold int
+	// ImageStoragePath is the directory product images and their thumbnails are stored in.
+	ImageStoragePath string
+	// MaxImageSize is the largest image upload accepted, in bytes.
+	MaxImageSize int64
+	// DuplicateCheck is what creating a product that looks like one of its seller's does: off, warn or reject.
+	DuplicateCheck string
+	// DuplicateSimilarity is how similar, from 0 to 1, name and brand must be to count as a duplicate.
+	DuplicateSimilarity float64
+	// IdempotencyKeyTTL is how long the response to an Idempotency-Key is replayed.
+	IdempotencyKeyTTL time.Duration
+	// IdempotencyReaperInterval is how often expired idempotency keys are deleted.
+	IdempotencyReaperInterval time.Duration
+	// SchedulerInterval is how often due scheduled product changes are applied.
+	SchedulerInterval time.Duration
+	// ScheduledChangeMaxAttempts is how often a scheduled change is tried before it is marked failed.
+	ScheduledChangeMaxAttempts int
+	// ScheduledChangeRetryDelay is added to the wait before the next attempt after every failed one.
+	ScheduledChangeRetryDelay time.Duration
+	// ManagementToken lets requests sending it in X-Management-Token see products that are not
+	// published. Empty means every request only sees published products.
+	ManagementToken string
 }
 
 func Load() *AppConfig {
@@ -23,10 +65,81 @@ func Load() *AppConfig {
 	}
 	v.SetDefault("HTTP_PORT", 8080)
 	v.SetDefault("NOTI_PROVIDER_TYPE", "email")
+	v.SetDefault("REQUIRE_IF_MATCH", false)
+	v.SetDefault("ALLOW_BACKORDERS", false)
+	v.SetDefault("RESERVATION_TTL", "15m")
+	v.SetDefault("RESERVATION_REAPER_INTERVAL", "1m")
+	v.SetDefault("PRODUCT_PURGE_RETENTION", "720h")
+	v.SetDefault("PRODUCT_PURGE_INTERVAL", "1h")
+	v.SetDefault("PRICE_DROP_ALERT_PERCENT", 10)
+	v.SetDefault("LOW_STOCK_THRESHOLD", 5)
+	v.SetDefault("IMAGE_STORAGE_PATH", "data/images")
+	v.SetDefault("MAX_IMAGE_SIZE", 5<<20)
+	v.SetDefault("DUPLICATE_CHECK", "off")
+	v.SetDefault("DUPLICATE_SIMILARITY", 0.9)
+	v.SetDefault("IDEMPOTENCY_KEY_TTL", "24h")
+	v.SetDefault("IDEMPOTENCY_REAPER_INTERVAL", "1h")
+	v.SetDefault("SCHEDULER_INTERVAL", "1m")
+	v.SetDefault("SCHEDULED_CHANGE_MAX_ATTEMPTS", 5)
+	v.SetDefault("SCHEDULED_CHANGE_RETRY_DELAY", "1m")
+	v.SetDefault("MANAGEMENT_TOKEN", "")
 
 	return &AppConfig{
 		MySQLConfig:      mySQLConfig,
 		HTTPPort:         v.GetInt("HTTP_PORT"),
 		NotiProdiverType: v.GetString("NOTI_PROVIDER_TYPE"),
+		RequireIfMatch:   v.GetBool("REQUIRE_IF_MATCH"),
+		AllowBackorders:  v.GetBool("ALLOW_BACKORDERS"),
+
+		ReservationTTL:            v.GetDuration("RESERVATION_TTL"),
+		ReservationReaperInterval: v.GetDuration("RESERVATION_REAPER_INTERVAL"),
+		ProductPurgeRetention:     v.GetDuration("PRODUCT_PURGE_RETENTION"),
+		ProductPurgeInterval:      v.GetDuration("PRODUCT_PURGE_INTERVAL"),
+		PriceDropAlertPercent:     v.GetFloat64("PRICE_DROP_ALERT_PERCENT"),
+		LowStockThreshold:         v.GetInt("LOW_STOCK_THRESHOLD"),
+		ImageStoragePath:          v.GetString("IMAGE_STORAGE_PATH"),
+		MaxImageSize:              v.GetInt64("MAX_IMAGE_SIZE"),
+		DuplicateCheck:            v.GetString("DUPLICATE_CHECK"),
+		DuplicateSimilarity:       v.GetFloat64("DUPLICATE_SIMILARITY"),
+		IdempotencyKeyTTL:         v.GetDuration("IDEMPOTENCY_KEY_TTL"),
+		IdempotencyReaperInterval: v.GetDuration("IDEMPOTENCY_REAPER_INTERVAL"),
+
+		SchedulerInterval:          v.GetDuration("SCHEDULER_INTERVAL"),
+		ScheduledChangeMaxAttempts: v.GetInt("SCHEDULED_CHANGE_MAX_ATTEMPTS"),
+		ScheduledChangeRetryDelay:  v.GetDuration("SCHEDULED_CHANGE_RETRY_DELAY"),
+		ManagementToken:            v.GetString("MANAGEMENT_TOKEN"),
+	}
+}
+
+// Validate rejects settings the server cannot run with, such as a background
+// job interval that is not positive.
+func (c *AppConfig) Validate() error {
+	type duration struct {
+		name  string
+		value time.Duration
+	}
+	durations := []duration{
+		{"RESERVATION_TTL", c.ReservationTTL},
+		{"RESERVATION_REAPER_INTERVAL", c.ReservationReaperInterval},
+		{"IDEMPOTENCY_KEY_TTL", c.IdempotencyKeyTTL},
+		{"IDEMPOTENCY_REAPER_INTERVAL", c.IdempotencyReaperInterval},
+		{"SCHEDULER_INTERVAL", c.SchedulerInterval},
+		{"SCHEDULED_CHANGE_RETRY_DELAY", c.ScheduledChangeRetryDelay},
+	}
+	if c.ProductPurgeRetention < 0 {
+		return fmt.Errorf("PRODUCT_PURGE_RETENTION must not be negative, not %s", c.ProductPurgeRetention)
+	}
+	// The purge interval only matters while purging is enabled.
+	if c.ProductPurgeRetention > 0 {
+		durations = append(durations, duration{"PRODUCT_PURGE_INTERVAL", c.ProductPurgeInterval})
+	}
+	for _, d := range durations {
+		if d.value <= 0 {
+			return fmt.Errorf("%s must be a positive duration, not %s", d.name, d.value)
+		}
+	}
+	if c.ScheduledChangeMaxAttempts < 1 {
+		return fmt.Errorf("SCHEDULED_CHANGE_MAX_ATTEMPTS must be at least 1, not %d", c.ScheduledChangeMaxAttempts)
 	}
+	return nil
 }
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr error
+	}{
+		{
+			name: "test defaults",
+		},
+		{
+			name:    "test zero reaper interval",
+			env:     map[string]string{"RESERVATION_REAPER_INTERVAL": "0"},
+			wantErr: errors.New("RESERVATION_REAPER_INTERVAL must be a positive duration, not 0s"),
+		},
+		{
+			name:    "test negative scheduler interval",
+			env:     map[string]string{"SCHEDULER_INTERVAL": "-1m"},
+			wantErr: errors.New("SCHEDULER_INTERVAL must be a positive duration, not -1m0s"),
+		},
+		{
+			name:    "test zero purge interval",
+			env:     map[string]string{"PRODUCT_PURGE_INTERVAL": "0"},
+			wantErr: errors.New("PRODUCT_PURGE_INTERVAL must be a positive duration, not 0s"),
+		},
+		{
+			name: "test zero purge interval with purging disabled",
+			env:  map[string]string{"PRODUCT_PURGE_INTERVAL": "0", "PRODUCT_PURGE_RETENTION": "0"},
+		},
+		{
+			name:    "test no scheduled change attempts",
+			env:     map[string]string{"SCHEDULED_CHANGE_MAX_ATTEMPTS": "0"},
+			wantErr: errors.New("SCHEDULED_CHANGE_MAX_ATTEMPTS must be at least 1, not 0"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+			}
+			defer func() {
+				for k := range tt.env {
+					os.Unsetenv(k)
+				}
+			}()
+			assert.Equal(t, tt.wantErr, Load().Validate())
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/brand"
+)
+
+func NewBrandController(brandSvc brand.Service) *brandController {
+	return &brandController{
+		brandSvc: brandSvc,
+	}
+}
+
+type (
+	brandController struct {
+		brandSvc brand.Service
+	}
+
+	brandRequest struct {
+		Name string `json:"name"`
+	}
+)
+
+func (bc *brandController) List(c *gin.Context) {
+	brands, err := bc.brandSvc.List(c.Request.Context())
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to query brands with err=%s", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query brands"})
+		return
+	}
+
+	bc.respond(c, http.StatusOK, brands)
+}
+
+func (bc *brandController) Get(c *gin.Context) {
+	b, err := bc.brandSvc.FindByUUID(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to get brand with err=%s", err.Error()))
+		bc.handleError(c, err)
+		return
+	}
+
+	bc.respond(c, http.StatusOK, b)
+}
+
+func (bc *brandController) Post(c *gin.Context) {
+	request := &brandRequest{}
+
+	if err := c.ShouldBindJSON(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	b := &brand.Brand{Name: request.Name}
+	if err := bc.brandSvc.Create(c.Request.Context(), b); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to create brand with err=%s", err.Error()))
+		bc.handleError(c, err)
+		return
+	}
+
+	bc.respond(c, http.StatusCreated, b)
+}
+
+func (bc *brandController) Put(c *gin.Context) {
+	request := &brandRequest{}
+
+	if err := c.ShouldBindJSON(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	b := &brand.Brand{UUID: c.Param("uuid"), Name: request.Name}
+	if err := bc.brandSvc.Update(c.Request.Context(), b); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to update brand with err=%s", err.Error()))
+		bc.handleError(c, err)
+		return
+	}
+
+	bc.respond(c, http.StatusOK, b)
+}
+
+func (bc *brandController) Delete(c *gin.Context) {
+	if err := bc.brandSvc.Delete(c.Request.Context(), c.Param("uuid")); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to delete brand with err=%s", err.Error()))
+		bc.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (bc *brandController) handleError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *brand.InvalidBrandError:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case *brand.BrandNotFoundError:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case *brand.BrandConflictError, *brand.BrandInUseError:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (bc *brandController) respond(c *gin.Context, status int, v interface{}) {
+	jsonData, err := json.Marshal(v)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal brand")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal brand"})
+		return
+	}
+
+	c.Data(status, "application/json; charset=utf-8", jsonData)
+}
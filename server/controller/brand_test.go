@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/brand"
+)
+
+func Test_Brands(t *testing.T) {
+	service := &brandServiceMock{
+		DoCreateFunc: func(b *brand.Brand) error {
+			if brand.Normalize(b.Name) == "shirtsco" {
+				return brand.NewBrandConflictError(b.Name, "b1")
+			}
+			b.UUID = "b2"
+			return nil
+		},
+		DoDeleteFunc: func(uuid string) error {
+			return brand.NewBrandInUseError(uuid, 3)
+		},
+	}
+	brandController := NewBrandController(service)
+	router := gin.Default()
+	router.POST("/api/v2/brands", brandController.Post)
+	router.DELETE("/api/v2/brands/:uuid", brandController.Delete)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v2/brands", strings.NewReader(`{"name":"TeeCo"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, `{"uuid":"b2","name":"TeeCo"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v2/brands", strings.NewReader(`{"name":"Shirts Co"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 409, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/api/v2/brands/b1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 409, w.Code)
+	assert.Equal(t, `{"error":"Brand id=b1 is used by 3 products and cannot be deleted"}`, w.Body.String())
+}
+
+type brandServiceMock struct {
+	brand.Service
+	DoCreateFunc func(b *brand.Brand) error
+	DoDeleteFunc func(uuid string) error
+}
+
+func (m *brandServiceMock) Create(ctx context.Context, b *brand.Brand) error {
+	return m.DoCreateFunc(b)
+}
+
+func (m *brandServiceMock) Delete(ctx context.Context, uuid string) error {
+	return m.DoDeleteFunc(uuid)
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/product"
+)
+
+func (pc *productController) SetComponents(c *gin.Context) {
+	request := &struct {
+		Components []struct {
+			ProductUUID string `json:"product_uuid"`
+			Quantity    int    `json:"quantity"`
+		} `json:"components" binding:"required,min=1,max=50"`
+	}{}
+
+	if !bindJSON(c, request) {
+		return
+	}
+
+	components := make([]*product.Component, len(request.Components))
+	for i, component := range request.Components {
+		components[i] = &product.Component{ProductUUID: component.ProductUUID, Quantity: component.Quantity}
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "If-Match header does not match any version"})
+		return
+	}
+
+	p, err := pc.productSvc.SetComponents(c.Request.Context(), c.Param("uuid"), components, version)
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to set bundle components with err=%s", err.Error()))
+
+		switch err.(type) {
+		case *product.ProductNotFoundError:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case *product.PreconditionFailedError:
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		case *product.InvalidBundleError:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		// Products with variants or locations keep their own stock.
+		case *product.DerivedStockError:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	jsonData, err := json.Marshal(p)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal product")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal product"})
+		return
+	}
+
+	setETag(c, p.Version)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/product"
+)
+
+func Test_ProductSetComponents(t *testing.T) {
+	var got []*product.Component
+	var gotVersion int
+	service := &productServiceMock{
+		DoSetComponentsFunc: func(uuid string, components []*product.Component, version int) (*product.ProductInfo, error) {
+			switch uuid {
+			case "p1":
+				got, gotVersion = components, version
+				return &product.ProductInfo{
+					Product:    &product.Product{UUID: uuid, Name: "Shirt and Tee Kit", Stock: 2, Version: 3},
+					Components: []*product.Component{{ProductUUID: "p2", Name: "Berlin New Shirt", Quantity: 2, Stock: 5}},
+				}, nil
+			case "p3":
+				return nil, product.NewInvalidBundleError("a bundle cannot contain itself")
+			case "p4":
+				return nil, product.NewDerivedStockError(uuid, product.StockSourceVariant)
+			case "p6":
+				return nil, product.NewPreconditionFailedError(uuid)
+			}
+			return nil, product.NewProductNotFoundError(uuid)
+		},
+	}
+	productController := NewProductController(service)
+	router := gin.Default()
+	router.PUT("/api/v2/products/:uuid/components", productController.SetComponents)
+
+	body := `{"components":[{"product_uuid":"p2","quantity":2}]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/v2/products/p1/components", strings.NewReader(body))
+	req.Header.Set("If-Match", `"2"`)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, []*product.Component{{ProductUUID: "p2", Quantity: 2}}, got)
+	assert.Equal(t, 2, gotVersion)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"components":[{"product_uuid":"p2","name":"Berlin New Shirt","quantity":2,"stock":5}]`)
+
+	tests := []struct {
+		uuid     string
+		ifMatch  string
+		wantCode int
+	}{
+		{uuid: "p3", wantCode: 400},
+		{uuid: "p4", wantCode: 409},
+		{uuid: "p5", wantCode: 404},
+		{uuid: "p6", ifMatch: `"2"`, wantCode: 412},
+		{uuid: "p1", ifMatch: "2", wantCode: 412},
+	}
+	for _, tt := range tests {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("PUT", "/api/v2/products/"+tt.uuid+"/components", strings.NewReader(body))
+		if tt.ifMatch != "" {
+			req.Header.Set("If-Match", tt.ifMatch)
+		}
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.wantCode, w.Code, tt.uuid)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/api/v2/products/p1/components", strings.NewReader(`{"components":[]}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 422, w.Code)
+	assert.Equal(t, `{"error":"Request is invalid","fields":[{"field":"components","code":"too_small","message":"must contain at least 1"}]}`, w.Body.String())
+}
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/category"
+)
+
+func NewCategoryController(categorySvc category.Service) *categoryController {
+	return &categoryController{
+		categorySvc: categorySvc,
+	}
+}
+
+type (
+	categoryController struct {
+		categorySvc category.Service
+	}
+
+	categoryRequest struct {
+		Name            string          `json:"name"`
+		Slug            string          `json:"slug"`
+		Parent          string          `json:"parent"`
+		AttributeSchema json.RawMessage `json:"attribute_schema"`
+	}
+)
+
+func (cc *categoryController) List(c *gin.Context) {
+	tree, err := cc.categorySvc.Tree(c.Request.Context())
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to query categories with err=%s", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query categories"})
+		return
+	}
+
+	cc.respond(c, http.StatusOK, tree)
+}
+
+func (cc *categoryController) Get(c *gin.Context) {
+	ct, err := cc.categorySvc.FindByUUID(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to get category with err=%s", err.Error()))
+		cc.handleError(c, err)
+		return
+	}
+
+	cc.respond(c, http.StatusOK, ct)
+}
+
+func (cc *categoryController) Post(c *gin.Context) {
+	request := &categoryRequest{}
+
+	if err := c.ShouldBindJSON(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ct := &category.Category{
+		Name:            request.Name,
+		Slug:            request.Slug,
+		ParentUUID:      request.Parent,
+		AttributeSchema: request.AttributeSchema,
+	}
+	if err := cc.categorySvc.Create(c.Request.Context(), ct); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to create category with err=%s", err.Error()))
+		cc.handleError(c, err)
+		return
+	}
+
+	cc.respond(c, http.StatusCreated, ct)
+}
+
+func (cc *categoryController) Put(c *gin.Context) {
+	request := &categoryRequest{}
+
+	if err := c.ShouldBindJSON(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ct := &category.Category{
+		UUID:            c.Param("uuid"),
+		Name:            request.Name,
+		Slug:            request.Slug,
+		ParentUUID:      request.Parent,
+		AttributeSchema: request.AttributeSchema,
+	}
+	if err := cc.categorySvc.Update(c.Request.Context(), ct); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to update category with err=%s", err.Error()))
+		cc.handleError(c, err)
+		return
+	}
+
+	cc.respond(c, http.StatusOK, ct)
+}
+
+func (cc *categoryController) Delete(c *gin.Context) {
+	if err := cc.categorySvc.Delete(c.Request.Context(), c.Param("uuid")); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to delete category with err=%s", err.Error()))
+		cc.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (cc *categoryController) handleError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *category.InvalidCategoryError:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case *category.CategoryNotFoundError:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case *category.CategoryPathConflictError, *category.CategoryNotEmptyError:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (cc *categoryController) respond(c *gin.Context, status int, v interface{}) {
+	jsonData, err := json.Marshal(v)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal category")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal category"})
+		return
+	}
+
+	c.Data(status, "application/json; charset=utf-8", jsonData)
+}
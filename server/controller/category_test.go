@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/category"
+)
+
+func Test_Categories(t *testing.T) {
+	service := &categoryServiceMock{
+		DoCreateFunc: func(c *category.Category) error {
+			if c.Slug == "men" {
+				return category.NewCategoryPathConflictError("men")
+			}
+			c.UUID = "c2"
+			c.Path = "men/" + c.Slug
+			return nil
+		},
+		DoDeleteFunc: func(uuid string) error {
+			return category.NewCategoryNotEmptyError(uuid)
+		},
+	}
+	categoryController := NewCategoryController(service)
+	router := gin.Default()
+	router.POST("/api/v2/categories", categoryController.Post)
+	router.DELETE("/api/v2/categories/:uuid", categoryController.Delete)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v2/categories", strings.NewReader(`{"name":"Shirts","slug":"shirts","parent":"c1"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, `{"uuid":"c2","name":"Shirts","slug":"shirts","parent_uuid":"c1","path":"men/shirts"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v2/categories", strings.NewReader(`{"name":"Men","slug":"men"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 409, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/api/v2/categories/c1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 409, w.Code)
+}
+
+type categoryServiceMock struct {
+	category.Service
+	DoCreateFunc func(c *category.Category) error
+	DoDeleteFunc func(uuid string) error
+}
+
+func (m *categoryServiceMock) Create(ctx context.Context, c *category.Category) error {
+	return m.DoCreateFunc(c)
+}
+
+func (m *categoryServiceMock) Delete(ctx context.Context, uuid string) error {
+	return m.DoDeleteFunc(uuid)
+}
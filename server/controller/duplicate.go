@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/product"
+)
+
+// setDuplicateWarning tells the client that the product it created looks like an
+// existing one, without changing the V1 response body.
+func setDuplicateWarning(c *gin.Context, duplicate *product.Duplicate) {
+	c.Header("Warning", fmt.Sprintf(`299 - "Product looks like a duplicate of product id=%s"`, duplicate.UUID))
+}
+
+func (pc *productController) Duplicates(c *gin.Context) {
+	request := &struct {
+		Seller string `form:"seller"`
+		Page   int    `form:"page,default=1"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	groups, err := pc.productSvc.Duplicates(c.Request.Context(), request.Seller, request.Page)
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to find duplicate products with err=%s", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to find duplicate products"})
+		return
+	}
+
+	jsonData, err := json.Marshal(groups)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal duplicate products")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal duplicate products"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
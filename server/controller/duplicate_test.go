@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/product"
+)
+
+func Test_PostDuplicateProduct(t *testing.T) {
+	body := `{"name":"Berlin New Shirt","brand":"ShirtsCo","stock":3,"seller":"38c47b48-f563-11e9-94e7-38baf859afa1"}`
+
+	tests := []struct {
+		name         string
+		statusCode   int
+		expected     string
+		warning      string
+		DoCreateFunc func(p *product.Product) (*product.Duplicate, error)
+	}{
+		{
+			name:       "test duplicate is rejected",
+			statusCode: 409,
+			expected:   `{"duplicate_of":"p1","error":"Product looks like a duplicate of product id=p1"}`,
+			DoCreateFunc: func(p *product.Product) (*product.Duplicate, error) {
+				return nil, product.NewDuplicateProductError("p1")
+			},
+		},
+		{
+			name:       "test duplicate is accepted with a warning",
+			statusCode: 200,
+			warning:    `299 - "Product looks like a duplicate of product id=p1"`,
+			DoCreateFunc: func(p *product.Product) (*product.Duplicate, error) {
+				return &product.Duplicate{UUID: "p1", Name: "Berlin New Shirt", Brand: "ShirtsCo", Similarity: 1}, nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			productController := NewProductController(&productServiceMock{DoCreateFunc: test.DoCreateFunc})
+			router := gin.Default()
+			router.POST("/api/v1/product", productController.Post)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/v1/product", strings.NewReader(body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.statusCode, w.Code)
+			assert.Equal(t, test.warning, w.Header().Get("Warning"))
+			if test.expected != "" {
+				assert.Equal(t, test.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_ProductDuplicates(t *testing.T) {
+	service := &productServiceMock{
+		DoDuplicatesFunc: func(sellerUUID string, page int) ([]*product.DuplicateGroup, error) {
+			assert.Equal(t, "s1", sellerUUID)
+			assert.Equal(t, 2, page)
+			return []*product.DuplicateGroup{{SellerUUID: "s1", Products: []*product.Product{
+				{UUID: "p1", Name: "Berlin New Shirt", Brand: "ShirtsCo", SellerUUID: "s1"},
+				{UUID: "p3", Name: "Berlin New Shirts", Brand: "ShirtsCo", SellerUUID: "s1"},
+			}}}, nil
+		},
+	}
+	productController := NewProductController(service)
+	router := gin.Default()
+	router.GET("/api/v2/products/duplicates", productController.Duplicates)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v2/products/duplicates?seller=s1&page=2", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `[{"seller_uuid":"s1","products":[`+
+		`{"uuid":"p1","name":"Berlin New Shirt","brand":"ShirtsCo","stock":0,"available_stock":0,"seller_uuid":"s1","price":null},`+
+		`{"uuid":"p3","name":"Berlin New Shirts","brand":"ShirtsCo","stock":0,"available_stock":0,"seller_uuid":"s1","price":null}]}]`, w.Body.String())
+}
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag exposes the product version so clients can send it back in If-Match.
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// ifMatchVersion returns the version sent in the If-Match header. It returns 0 when
+// the header is missing or "*", which means the write is not conditional.
+func ifMatchVersion(c *gin.Context) (int, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	// Weak validators are accepted because the version is the only thing compared.
+	etag := strings.TrimPrefix(header, "W/")
+	etag, err := strconv.Unquote(etag)
+	if err != nil {
+		return 0, false
+	}
+	version, err := strconv.Atoi(etag)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// RequireIfMatch rejects writes that are sent without an If-Match header when required is set.
+func RequireIfMatch(required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if required && c.GetHeader("If-Match") == "" {
+			c.AbortWithStatusJSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+			return
+		}
+		c.Next()
+	}
+}
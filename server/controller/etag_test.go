@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/product"
+)
+
+func Test_DeleteProductIfMatch(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		ifMatch        string
+		requireIfMatch bool
+		statusCode     int
+		expected       string
+		wantVersion    int
+		DoDeleteFunc   func(uuid string, version int) error
+	}{
+		{
+			name:        "test delete without If-Match is unconditional",
+			statusCode:  200,
+			expected:    `{}`,
+			wantVersion: 0,
+		},
+		{
+			name:        "test delete with matching If-Match",
+			ifMatch:     `"3"`,
+			statusCode:  200,
+			expected:    `{}`,
+			wantVersion: 3,
+		},
+		{
+			name:        "test delete with weak If-Match",
+			ifMatch:     `W/"3"`,
+			statusCode:  200,
+			expected:    `{}`,
+			wantVersion: 3,
+		},
+		{
+			name:       "test delete with stale If-Match",
+			ifMatch:    `"2"`,
+			statusCode: 412,
+			expected:   `{"error":"Product has been modified since it was read with id=p1"}`,
+			DoDeleteFunc: func(uuid string, version int) error {
+				return product.NewPreconditionFailedError(uuid)
+			},
+		},
+		{
+			name:       "test delete with malformed If-Match",
+			ifMatch:    `abc`,
+			statusCode: 412,
+			expected:   `{"error":"If-Match header does not match any version"}`,
+		},
+		{
+			name:           "test delete without required If-Match",
+			requireIfMatch: true,
+			statusCode:     428,
+			expected:       `{"error":"If-Match header is required"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			gotVersion := -1
+			service := &productServiceMock{
+				DoDeleteFunc: test.DoDeleteFunc,
+			}
+			if service.DoDeleteFunc == nil {
+				service.DoDeleteFunc = func(uuid string, version int) error {
+					gotVersion = version
+					return nil
+				}
+			}
+			productController := NewProductController(service)
+			router := gin.Default()
+			router.DELETE("/api/v1/product", RequireIfMatch(test.requireIfMatch), productController.Delete)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", "/api/v1/product?id=p1", nil)
+			if test.ifMatch != "" {
+				req.Header.Set("If-Match", test.ifMatch)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.statusCode, w.Code)
+			assert.Equal(t, test.expected, w.Body.String())
+			if test.statusCode == 200 {
+				assert.Equal(t, test.wantVersion, gotVersion)
+			}
+		})
+	}
+
+}
+
+func Test_GetProductETag(t *testing.T) {
+	service := &productServiceMock{
+		DoGetProductFunc: func(uuid string) (*product.ProductInfo, error) {
+			return &product.ProductInfo{Product: &product.Product{UUID: uuid, Version: 7, Status: product.StatusPublished}}, nil
+		},
+	}
+	productController := NewProductController(service)
+	router := setupRouter("/api/v2/product", productController.GetV2)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v2/product?id=p1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `"7"`, w.Header().Get("ETag"))
+}
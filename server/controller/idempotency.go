@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/idempotency"
+	"coding-challenge-go/pkg/requestinfo"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotentBodySize bounds the body buffered to fingerprint a request.
+	maxIdempotentBodySize = 1 << 20
+)
+
+// replayedHeaders are the response headers stored for retries. Others, such as
+// Date and Content-Length, are set anew for every response.
+var replayedHeaders = []string{"ETag", "Location", "Warning"}
+
+// recordingWriter keeps a copy of the response body so it can be stored.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes POST requests sent with an Idempotency-Key header safe to
+// retry. The first response to a key is stored and replayed for retries of the
+// same request, while reusing the key for a different request fails with 409.
+// Responses with a server error are not stored, so the request can be retried.
+//
+// Multipart bodies, i.e. image uploads, are streamed to the handler rather than
+// buffered, so they are fingerprinted by their length only.
+func Idempotency(svc idempotency.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+
+		var body []byte
+		if strings.HasPrefix(c.ContentType(), "multipart/") {
+			body = []byte(strconv.FormatInt(c.Request.ContentLength, 10))
+		} else {
+			var err error
+			body, err = ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+			if err != nil && len(body) == maxIdempotentBodySize {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("request body must not exceed %d bytes", maxIdempotentBodySize)})
+				return
+			}
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		response, err := svc.Begin(c.Request.Context(), key, requestFingerprint(c.Request, body))
+		if err != nil {
+			switch err.(type) {
+			case *idempotency.InvalidKeyError:
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case *idempotency.KeyMismatchError, *idempotency.KeyInProgressError:
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				log.Error().Err(err).Msg(fmt.Sprintf("Fail to claim idempotency key with err=%s", err.Error()))
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Fail to claim idempotency key"})
+			}
+			return
+		}
+		if response != nil {
+			for name, values := range response.Header {
+				for _, value := range values {
+					c.Writer.Header().Add(name, value)
+				}
+			}
+			c.Header(idempotentReplayedHeader, "true")
+			c.Data(response.StatusCode, response.ContentType, response.Body)
+			c.Abort()
+			return
+		}
+
+		// The client may be gone by now, and the key must not stay claimed because of it.
+		ctx := requestinfo.WithActor(context.Background(), requestinfo.Actor(c.Request.Context()))
+
+		// There is no recovery middleware, so a panicking handler would leave the key
+		// in progress until it expires.
+		defer func() {
+			if r := recover(); r != nil {
+				if err := svc.Release(ctx, key); err != nil {
+					log.Error().Err(err).Msg(fmt.Sprintf("Fail to release idempotency key with err=%s", err.Error()))
+				}
+				panic(r)
+			}
+		}()
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		if w.Status() >= http.StatusInternalServerError {
+			err = svc.Release(ctx, key)
+		} else {
+			err = svc.Complete(ctx, key, &idempotency.Response{
+				StatusCode:  w.Status(),
+				ContentType: w.Header().Get("Content-Type"),
+				Header:      storedHeader(w.Header()),
+				Body:        w.body.Bytes(),
+			})
+		}
+		if err != nil {
+			log.Error().Err(err).Msg(fmt.Sprintf("Fail to store response of idempotency key with err=%s", err.Error()))
+		}
+	}
+}
+
+// storedHeader picks the replayed headers out of header, or returns nil when
+// the response has none.
+func storedHeader(header http.Header) http.Header {
+	var stored http.Header
+	for _, name := range replayedHeaders {
+		for _, value := range header.Values(name) {
+			if stored == nil {
+				stored = http.Header{}
+			}
+			stored.Add(name, value)
+		}
+	}
+	return stored
+}
+
+// requestFingerprint identifies a request by its method, path, query and body.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", r.Method, r.URL.RequestURI())
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
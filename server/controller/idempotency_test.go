@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/idempotency"
+	"coding-challenge-go/pkg/product"
+)
+
+func Test_Idempotency(t *testing.T) {
+	var created int
+	service := &productServiceMock{
+		DoCreateFunc: func(p *product.Product) (*product.Duplicate, error) {
+			created++
+			if created > 2 {
+				return nil, product.NewInvalidPriceError("amount must not be negative")
+			}
+			return &product.Duplicate{UUID: "p0"}, nil
+		},
+	}
+	productController := NewProductController(service)
+	router := gin.Default()
+	router.POST("/api/v1/product", Idempotency(idempotency.NewService(&idempotencyRepositoryMock{}, time.Hour)), productController.Post)
+
+	post := func(key string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/product", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(idempotencyKeyHeader, key)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+	body := `{"name":"Berlin New Shirt","brand":"ShirtsCo","stock":3,"seller":"38c47b48-f563-11e9-94e7-38baf859afa1"}`
+
+	first := post("k1", body)
+	assert.Equal(t, 200, first.Code)
+	assert.Equal(t, "", first.Header().Get(idempotentReplayedHeader))
+
+	retry := post("k1", body)
+	assert.Equal(t, 200, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(idempotentReplayedHeader))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, first.Header().Get("Warning"), retry.Header().Get("Warning"))
+	assert.NotEmpty(t, retry.Header().Get("Warning"))
+	assert.Equal(t, 1, created)
+
+	w := post("k1", strings.Replace(body, `"stock":3`, `"stock":4`, 1))
+	assert.Equal(t, 409, w.Code)
+	assert.Equal(t, `{"error":"Idempotency-Key k1 was already used for a different request"}`, w.Body.String())
+
+	post("", body)
+	assert.Equal(t, 2, created)
+
+	w = post("bad key", body)
+	assert.Equal(t, 400, w.Code)
+
+	// Errors that are not the server's fault are replayed as well.
+	w = post("k2", body)
+	assert.Equal(t, 422, w.Code)
+	w = post("k2", body)
+	assert.Equal(t, 422, w.Code)
+	assert.Equal(t, "true", w.Header().Get(idempotentReplayedHeader))
+	assert.Equal(t, 3, created)
+
+	w = post("k3", `{"name":"`+strings.Repeat("x", maxIdempotentBodySize)+`"}`)
+	assert.Equal(t, 413, w.Code)
+	assert.Equal(t, 3, created)
+}
+
+func Test_IdempotencyScope(t *testing.T) {
+	repo := &idempotencyRepositoryMock{}
+	router := gin.New()
+	router.Use(RequestInfo)
+	router.POST("/api/v1/product", Idempotency(idempotency.NewService(repo, time.Hour)), func(c *gin.Context) {
+		if c.GetHeader(actorHeader) == "panicky" {
+			panic("handler failed")
+		}
+		c.JSON(http.StatusOK, gin.H{"actor": c.GetHeader(actorHeader)})
+	})
+
+	post := func(actor string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/product", strings.NewReader(`{}`))
+		req.Header.Set(idempotencyKeyHeader, "k1")
+		req.Header.Set(actorHeader, actor)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Two actors never see each other's responses.
+	assert.Equal(t, `{"actor":"alice"}`, post("alice").Body.String())
+	w := post("bob")
+	assert.Equal(t, `{"actor":"bob"}`, w.Body.String())
+	assert.Equal(t, "", w.Header().Get(idempotentReplayedHeader))
+	assert.Len(t, repo.records, 2)
+
+	// A panicking handler releases the key.
+	assert.Panics(t, func() { post("panicky") })
+	assert.Len(t, repo.records, 2)
+}
+
+type idempotencyRepositoryMock struct {
+	records map[string]*idempotency.Record
+}
+
+func (m *idempotencyRepositoryMock) Create(ctx context.Context, key string, fingerprint string, ttlSeconds int) (bool, error) {
+	if m.records == nil {
+		m.records = map[string]*idempotency.Record{}
+	}
+	if _, ok := m.records[key]; ok {
+		return false, nil
+	}
+	m.records[key] = &idempotency.Record{Key: key, Fingerprint: fingerprint}
+	return true, nil
+}
+
+func (m *idempotencyRepositoryMock) Find(ctx context.Context, key string) (*idempotency.Record, error) {
+	return m.records[key], nil
+}
+
+func (m *idempotencyRepositoryMock) Complete(ctx context.Context, key string, response *idempotency.Response) error {
+	m.records[key].Response = response
+	return nil
+}
+
+func (m *idempotencyRepositoryMock) Delete(ctx context.Context, key string) error {
+	delete(m.records, key)
+	return nil
+}
+
+func (m *idempotencyRepositoryMock) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/product"
+)
+
+// imageCacheControl lets clients and proxies keep images forever: an image is
+// never changed in place, a new upload gets a new UUID.
+const imageCacheControl = "public, max-age=31536000, immutable"
+
+func (pc *productController) ListImages(c *gin.Context) {
+	images, err := pc.productSvc.Images(c.Request.Context(), c.Param("uuid"))
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to query images with err=%s", err.Error()))
+		handleImageError(c, err)
+		return
+	}
+
+	jsonData, err := json.Marshal(images)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal images")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal images"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
+
+// PostImage takes a multipart form with the file in the "image" field. The part
+// is streamed to the service, which enforces the size limit.
+func (pc *productController) PostImage(c *gin.Context) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "multipart form has no image field"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if part.FormName() != "image" {
+			continue
+		}
+
+		image, err := pc.productSvc.AddImage(c.Request.Context(), c.Param("uuid"), part)
+		if err != nil {
+			log.Error().Err(err).Msg(fmt.Sprintf("Fail to add image with err=%s", err.Error()))
+			handleImageError(c, err)
+			return
+		}
+
+		jsonData, err := json.Marshal(image)
+
+		if err != nil {
+			log.Error().Err(err).Msg("Fail to marshal image")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal image"})
+			return
+		}
+
+		c.Data(http.StatusCreated, "application/json; charset=utf-8", jsonData)
+		return
+	}
+}
+
+func (pc *productController) GetImage(c *gin.Context) {
+	size := c.DefaultQuery("size", product.ImageSizeOriginal)
+	image, object, err := pc.productSvc.OpenImage(c.Request.Context(), c.Param("uuid"), c.Param("image"), size)
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to open image with err=%s", err.Error()))
+		handleImageError(c, err)
+		return
+	}
+	defer object.Close()
+
+	etag := strconv.Quote(image.UUID + "-" + size)
+	c.Header("Cache-Control", imageCacheControl)
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", image.CreatedAt.UTC().Format(http.TimeFormat))
+	if match := c.GetHeader("If-None-Match"); match == "*" || strings.Contains(match, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.DataFromReader(http.StatusOK, object.Size, image.ContentTypeOf(size), object, nil)
+}
+
+func (pc *productController) DeleteImage(c *gin.Context) {
+	err := pc.productSvc.DeleteImage(c.Request.Context(), c.Param("uuid"), c.Param("image"))
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to delete image with err=%s", err.Error()))
+		handleImageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func handleImageError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *product.InvalidImageError:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case *product.ProductNotFoundError, *product.ImageNotFoundError:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case *product.ImageTooLargeError:
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case *product.UnsupportedImageTypeError:
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/product"
+	"coding-challenge-go/pkg/storage"
+)
+
+func Test_PostImage(t *testing.T) {
+	var uploaded string
+	service := &productServiceMock{
+		DoAddImageFunc: func(productUUID string, r io.Reader) (*product.ImageInfo, error) {
+			data, _ := ioutil.ReadAll(r)
+			uploaded = string(data)
+			if uploaded == "%PDF" {
+				return nil, product.NewUnsupportedImageTypeError("application/pdf")
+			}
+			return &product.ImageInfo{Image: &product.Image{UUID: "i1", ProductUUID: productUUID, ContentType: "image/png"}}, nil
+		},
+	}
+	router := gin.Default()
+	router.POST("/api/v2/products/:uuid/images", NewProductController(service).PostImage)
+
+	tests := []struct {
+		name     string
+		field    string
+		content  string
+		wantCode int
+	}{
+		{name: "image is uploaded", field: "image", content: "png bytes", wantCode: 201},
+		{name: "other types are rejected", field: "image", content: "%PDF", wantCode: 415},
+		{name: "image field is required", field: "file", content: "png bytes", wantCode: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &bytes.Buffer{}
+			form := multipart.NewWriter(body)
+			part, _ := form.CreateFormFile(tt.field, "shirt.png")
+			part.Write([]byte(tt.content))
+			form.Close()
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/v2/products/p1/images", body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+	assert.Equal(t, "%PDF", uploaded)
+}
+
+func Test_GetImage(t *testing.T) {
+	createdAt := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	service := &productServiceMock{
+		DoOpenImageFunc: func(productUUID string, imageUUID string, size string) (*product.Image, *storage.Object, error) {
+			if imageUUID != "i1" {
+				return nil, nil, product.NewImageNotFoundError(productUUID, imageUUID)
+			}
+			return &product.Image{UUID: "i1", ContentType: "image/gif", CreatedAt: createdAt},
+				&storage.Object{ReadCloser: ioutil.NopCloser(strings.NewReader("thumb")), Size: 5}, nil
+		},
+	}
+	router := gin.Default()
+	router.GET("/api/v2/products/:uuid/images/:image", NewProductController(service).GetImage)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v2/products/p1/images/i1?size=small", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "thumb", w.Body.String())
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+	assert.Equal(t, `"i1-small"`, w.Header().Get("ETag"))
+	assert.Equal(t, "Mon, 01 Mar 2021 12:00:00 GMT", w.Header().Get("Last-Modified"))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v2/products/p1/images/i1?size=small", nil)
+	req.Header.Set("If-None-Match", `"i1-small"`)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 304, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v2/products/p1/images/i2", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 404, w.Code)
+}
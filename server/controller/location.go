@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/location"
+	"coding-challenge-go/pkg/product"
+)
+
+func NewLocationController(locationSvc location.Service) *locationController {
+	return &locationController{
+		locationSvc: locationSvc,
+	}
+}
+
+type (
+	locationController struct {
+		locationSvc location.Service
+	}
+
+	locationRequest struct {
+		Name string `json:"name" binding:"required,max=200"`
+	}
+)
+
+func (lc *locationController) List(c *gin.Context) {
+	request := &struct {
+		Seller string `form:"seller"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	locations, err := lc.locationSvc.List(c.Request.Context(), request.Seller)
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to query locations with err=%s", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query locations"})
+		return
+	}
+
+	lc.respond(c, http.StatusOK, locations)
+}
+
+func (lc *locationController) Get(c *gin.Context) {
+	l, err := lc.locationSvc.FindByUUID(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to get location with err=%s", err.Error()))
+		lc.handleError(c, err)
+		return
+	}
+
+	lc.respond(c, http.StatusOK, l)
+}
+
+func (lc *locationController) Post(c *gin.Context) {
+	request := &struct {
+		locationRequest
+		Seller string `json:"seller" binding:"required,uuid"`
+	}{}
+
+	if !bindJSON(c, request) {
+		return
+	}
+
+	l := &location.Location{Name: request.Name, SellerUUID: request.Seller}
+	if err := lc.locationSvc.Create(c.Request.Context(), l); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to create location with err=%s", err.Error()))
+		lc.handleError(c, err)
+		return
+	}
+
+	lc.respond(c, http.StatusCreated, l)
+}
+
+func (lc *locationController) Put(c *gin.Context) {
+	request := &locationRequest{}
+
+	if !bindJSON(c, request) {
+		return
+	}
+
+	l := &location.Location{UUID: c.Param("uuid"), Name: request.Name}
+	if err := lc.locationSvc.Update(c.Request.Context(), l); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to update location with err=%s", err.Error()))
+		lc.handleError(c, err)
+		return
+	}
+
+	lc.respond(c, http.StatusOK, l)
+}
+
+func (lc *locationController) Delete(c *gin.Context) {
+	if err := lc.locationSvc.Delete(c.Request.Context(), c.Param("uuid")); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to delete location with err=%s", err.Error()))
+		lc.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (lc *locationController) handleError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *location.InvalidLocationError:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case *location.LocationNotFoundError:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case *location.LocationConflictError, *location.LocationInUseError:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (lc *locationController) respond(c *gin.Context, status int, v interface{}) {
+	jsonData, err := json.Marshal(v)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal location")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal location"})
+		return
+	}
+
+	c.Data(status, "application/json; charset=utf-8", jsonData)
+}
+
+func (pc *productController) ListLocationStock(c *gin.Context) {
+	stock, err := pc.productSvc.LocationStock(c.Request.Context(), c.Param("uuid"))
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to query location stock with err=%s", err.Error()))
+		handleLocationStockError(c, err)
+		return
+	}
+
+	jsonData, err := json.Marshal(stock)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal location stock")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal location stock"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
+
+func (pc *productController) AdjustLocationStock(c *gin.Context) {
+	request := &struct {
+		Delta      int    `json:"delta" binding:"required"`
+		Reason     string `json:"reason" binding:"required,max=200"`
+		ReasonCode string `json:"reason_code" binding:"omitempty,oneof=sale return damage restock correction"`
+	}{}
+
+	if !bindJSON(c, request) {
+		return
+	}
+
+	stock, err := pc.productSvc.AdjustLocationStock(c.Request.Context(), c.Param("uuid"), c.Param("location"), &product.StockAdjustment{
+		Delta:  request.Delta,
+		Reason: request.Reason,
+		Code:   product.StockReason(request.ReasonCode),
+	})
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to adjust location stock with err=%s", err.Error()))
+		handleLocationStockError(c, err)
+		return
+	}
+
+	jsonData, err := json.Marshal(&stockAdjustmentResponse{
+		UUID:   stock.LocationUUID,
+		Delta:  request.Delta,
+		Reason: request.Reason,
+		Stock:  stock.Stock,
+	})
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal stock adjustment")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal stock adjustment"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
+
+func (pc *productController) MoveStock(c *gin.Context) {
+	request := &struct {
+		From     string `json:"from" binding:"required"`
+		To       string `json:"to" binding:"required"`
+		Quantity int    `json:"quantity" binding:"required,min=1"`
+		Reason   string `json:"reason" binding:"required,max=200"`
+	}{}
+
+	if !bindJSON(c, request) {
+		return
+	}
+
+	stock, err := pc.productSvc.MoveStock(c.Request.Context(), c.Param("uuid"), &product.StockMove{
+		From:     request.From,
+		To:       request.To,
+		Quantity: request.Quantity,
+		Reason:   request.Reason,
+	})
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to move stock with err=%s", err.Error()))
+		handleLocationStockError(c, err)
+		return
+	}
+
+	jsonData, err := json.Marshal(stock)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal location stock")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal location stock"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
+
+func handleLocationStockError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *product.InvalidStockAdjustmentError, *product.InvalidStockMoveError:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case *product.ProductNotFoundError, *location.LocationNotFoundError, *product.SellerNotFoundError:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case *product.InsufficientStockError, *product.DerivedStockError, *product.BundleComponentError:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
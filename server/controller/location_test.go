@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/location"
+	"coding-challenge-go/pkg/product"
+)
+
+func Test_Locations(t *testing.T) {
+	service := &locationServiceMock{
+		DoCreateFunc: func(l *location.Location) error {
+			if l.Name == "Berlin DC" {
+				return location.NewLocationConflictError(l.Name, "l1")
+			}
+			l.UUID = "l2"
+			return nil
+		},
+	}
+	locationController := NewLocationController(service)
+	router := gin.Default()
+	router.POST("/api/v2/locations", locationController.Post)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v2/locations", strings.NewReader(`{"name":"Hamburg","seller":"38c47b48-f563-11e9-94e7-38baf859afa1"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, `{"uuid":"l2","seller_uuid":"38c47b48-f563-11e9-94e7-38baf859afa1","name":"Hamburg"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v2/locations", strings.NewReader(`{"name":"Berlin DC","seller":"38c47b48-f563-11e9-94e7-38baf859afa1"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 409, w.Code)
+}
+
+func Test_LocationStock(t *testing.T) {
+	service := &productServiceMock{
+		DoAdjustLocationStockFunc: func(productUUID string, locationUUID string, adjustment *product.StockAdjustment) (*product.LocationStock, error) {
+			if locationUUID != "l1" {
+				return nil, location.NewLocationNotFoundError(locationUUID)
+			}
+			return &product.LocationStock{LocationUUID: "l1", Name: "Berlin DC", Stock: 4 + adjustment.Delta}, nil
+		},
+		DoMoveStockFunc: func(productUUID string, move *product.StockMove) ([]*product.LocationStock, error) {
+			if move.Quantity > 4 {
+				return nil, product.NewInsufficientStockError(move.From, 4, -move.Quantity)
+			}
+			return []*product.LocationStock{
+				{LocationUUID: "l1", Name: "Berlin DC", Stock: 4 - move.Quantity},
+				{LocationUUID: "l2", Name: "Hamburg", Stock: move.Quantity},
+			}, nil
+		},
+	}
+	productController := NewProductController(service)
+	router := gin.Default()
+	router.POST("/api/v2/products/:uuid/locations/:location/stock-adjustments", productController.AdjustLocationStock)
+	router.POST("/api/v2/products/:uuid/stock-moves", productController.MoveStock)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v2/products/p1/locations/l1/stock-adjustments", strings.NewReader(`{"delta":2,"reason":"inbound"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"uuid":"l1","delta":2,"reason":"inbound","stock":6}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v2/products/p1/locations/l9/stock-adjustments", strings.NewReader(`{"delta":2,"reason":"inbound"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 404, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v2/products/p1/stock-moves", strings.NewReader(`{"from":"l1","to":"l2","quantity":3,"reason":"rebalance"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `[{"location_uuid":"l1","name":"Berlin DC","stock":1},{"location_uuid":"l2","name":"Hamburg","stock":3}]`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v2/products/p1/stock-moves", strings.NewReader(`{"from":"l1","to":"l2","quantity":5,"reason":"rebalance"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 409, w.Code)
+}
+
+type locationServiceMock struct {
+	location.Service
+	DoCreateFunc func(l *location.Location) error
+}
+
+func (m *locationServiceMock) Create(ctx context.Context, l *location.Location) error {
+	return m.DoCreateFunc(l)
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/product"
+)
+
+func (pc *productController) ListPriceAlerts(c *gin.Context) {
+	alerts, err := pc.productSvc.PriceAlerts(c.Request.Context(), c.Param("uuid"))
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to query price alerts with err=%s", err.Error()))
+		handlePriceAlertError(c, err)
+		return
+	}
+
+	jsonData, err := json.Marshal(alerts)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal price alerts")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal price alerts"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
+
+func (pc *productController) PostPriceAlert(c *gin.Context) {
+	request := &struct {
+		Email string `json:"email" binding:"omitempty,email,max=255"`
+		Phone string `json:"phone" binding:"max=32"`
+	}{}
+
+	if !bindJSON(c, request) {
+		return
+	}
+
+	alert := &product.PriceAlert{Email: request.Email, Phone: request.Phone}
+	err := pc.productSvc.SubscribePriceAlert(c.Request.Context(), c.Param("uuid"), alert)
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to subscribe price alert with err=%s", err.Error()))
+		handlePriceAlertError(c, err)
+		return
+	}
+
+	jsonData, err := json.Marshal(alert)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal price alert")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal price alert"})
+		return
+	}
+
+	c.Data(http.StatusCreated, "application/json; charset=utf-8", jsonData)
+}
+
+func (pc *productController) DeletePriceAlert(c *gin.Context) {
+	err := pc.productSvc.UnsubscribePriceAlert(c.Request.Context(), c.Param("uuid"), c.Param("alert"))
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to unsubscribe price alert with err=%s", err.Error()))
+		handlePriceAlertError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func handlePriceAlertError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *product.InvalidPriceAlertError:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case *product.ProductNotFoundError, *product.PriceAlertNotFoundError:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
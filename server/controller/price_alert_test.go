@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/product"
+)
+
+func Test_ProductPriceAlerts(t *testing.T) {
+	subscribedAt := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	alerts := []*product.PriceAlert{}
+	service := &productServiceMock{
+		DoPriceAlertsFunc: func(uuid string) ([]*product.PriceAlert, error) {
+			if uuid != "p1" {
+				return nil, product.NewProductNotFoundError(uuid)
+			}
+			return alerts, nil
+		},
+		DoSubscribePriceAlertFunc: func(productUUID string, alert *product.PriceAlert) error {
+			if alert.Email == "" && alert.Phone == "" {
+				return product.NewInvalidPriceAlertError("email or phone is required")
+			}
+			alert.UUID, alert.ProductUUID, alert.CreatedAt = "a1", productUUID, subscribedAt
+			alerts = append(alerts, alert)
+			return nil
+		},
+		DoUnsubscribePriceAlertFunc: func(productUUID string, uuid string) error {
+			if uuid != "a1" {
+				return product.NewPriceAlertNotFoundError(productUUID, uuid)
+			}
+			alerts = alerts[:0]
+			return nil
+		},
+	}
+	productController := NewProductController(service)
+	router := gin.Default()
+	router.GET("/api/v2/products/:uuid/price-alerts", productController.ListPriceAlerts)
+	router.POST("/api/v2/products/:uuid/price-alerts", productController.PostPriceAlert)
+	router.DELETE("/api/v2/products/:uuid/price-alerts/:alert", productController.DeletePriceAlert)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "test subscribe",
+			method:   "POST",
+			path:     "/api/v2/products/p1/price-alerts",
+			body:     `{"email":"shopper@example.com"}`,
+			wantCode: 201,
+			wantBody: `{"uuid":"a1","product_uuid":"p1","email":"shopper@example.com","created_at":"2021-06-01T10:00:00Z"}`,
+		},
+		{
+			name:     "test subscribe with invalid email",
+			method:   "POST",
+			path:     "/api/v2/products/p1/price-alerts",
+			body:     `{"email":"shopper"}`,
+			wantCode: 422,
+		},
+		{
+			name:     "test subscribe without contact",
+			method:   "POST",
+			path:     "/api/v2/products/p1/price-alerts",
+			body:     `{}`,
+			wantCode: 400,
+		},
+		{
+			name:     "test list",
+			method:   "GET",
+			path:     "/api/v2/products/p1/price-alerts",
+			wantCode: 200,
+			wantBody: `[{"uuid":"a1","product_uuid":"p1","email":"shopper@example.com","created_at":"2021-06-01T10:00:00Z"}]`,
+		},
+		{
+			name:     "test list of unknown product",
+			method:   "GET",
+			path:     "/api/v2/products/p2/price-alerts",
+			wantCode: 404,
+		},
+		{
+			name:     "test unsubscribe",
+			method:   "DELETE",
+			path:     "/api/v2/products/p1/price-alerts/a1",
+			wantCode: 200,
+		},
+		{
+			name:     "test unsubscribe unknown alert",
+			method:   "DELETE",
+			path:     "/api/v2/products/p1/price-alerts/a2",
+			wantCode: 404,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
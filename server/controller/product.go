@@ -4,11 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 
+	"coding-challenge-go/pkg/brand"
+	"coding-challenge-go/pkg/category"
+	"coding-challenge-go/pkg/money"
 	"coding-challenge-go/pkg/product"
 )
 
@@ -25,8 +29,87 @@ type (
 		Stock      int    `json:"stock"`
 		SellerUUID string `json:"seller_uuid"`
 	}
+
+	stockAdjustmentResponse struct {
+		UUID   string `json:"uuid"`
+		Delta  int    `json:"delta"`
+		Reason string `json:"reason"`
+		Stock  int    `json:"stock"`
+	}
+
+	// productFilterRequest holds the query filters shared by the list and export endpoints.
+	productFilterRequest struct {
+		Seller   string `form:"seller"`
+		Brand    string `form:"brand"`
+		Currency string `form:"currency"`
+		MinPrice *int64 `form:"min_price"`
+		MaxPrice *int64 `form:"max_price"`
+		Sort     string `form:"sort"`
+		Category string `form:"category"`
+		Status   string `form:"status"`
+	}
 )
 
+// attributeFilterPrefix marks query parameters that filter on an attribute.
+const attributeFilterPrefix = "attr."
+
+// filterParams validates the request. Attribute filters are read from the query,
+// where they are passed as attr.<name>=<value>.
+func (r *productFilterRequest) filterParams(c *gin.Context) (*product.FilterParams, error) {
+	statuses, err := visibleStatuses(c, r.Status)
+	if err != nil {
+		return nil, err
+	}
+	sort, err := product.ParseSort(r.Sort)
+	if err != nil {
+		return nil, err
+	}
+	if r.Currency != "" && !money.IsCurrency(r.Currency) {
+		return nil, product.NewInvalidFilterError("currency", "must be a supported ISO-4217 code")
+	}
+	// Amounts are only comparable within one currency.
+	if (r.MinPrice != nil || r.MaxPrice != nil) && r.Currency == "" {
+		return nil, product.NewInvalidFilterError("currency", "is required with min_price or max_price")
+	}
+	var attributes map[string]string
+	for key, values := range c.Request.URL.Query() {
+		if !strings.HasPrefix(key, attributeFilterPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(key, attributeFilterPrefix)
+		if !product.IsAttributeName(name) {
+			return nil, product.NewInvalidFilterError(key, "is not a valid attribute name")
+		}
+		if attributes == nil {
+			attributes = map[string]string{}
+		}
+		attributes[name] = values[0]
+	}
+
+	return &product.FilterParams{
+		SellerUUID: r.Seller,
+		Brand:      r.Brand,
+		Currency:   r.Currency,
+		MinPrice:   r.MinPrice,
+		MaxPrice:   r.MaxPrice,
+		Sort:       sort,
+		Category:   r.Category,
+		Attributes: attributes,
+		Statuses:   statuses,
+	}, nil
+}
+
+func newProductResponseV1(p *product.Product) *ProductResponseV1 {
+	return &ProductResponseV1{
+		ProductID:  p.ProductID,
+		UUID:       p.UUID,
+		Name:       p.Name,
+		Brand:      p.Brand,
+		Stock:      p.Stock,
+		SellerUUID: p.SellerUUID,
+	}
+}
+
 func NewProductController(productSvc product.Service) *productController {
 	return &productController{
 		productSvc: productSvc,
@@ -35,6 +118,7 @@ func NewProductController(productSvc product.Service) *productController {
 
 func (pc *productController) List(c *gin.Context) {
 	request := &struct {
+		productFilterRequest
 		Page int `form:"page,default=1"`
 	}{}
 
@@ -43,9 +127,15 @@ func (pc *productController) List(c *gin.Context) {
 		return
 	}
 
-	products, err := pc.productSvc.List(c.Request.Context(), &product.FilterParams{Pagination: &product.Pagination{
+	params, err := request.filterParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params.Pagination = &product.Pagination{
 		PageNumber: request.Page,
-	}})
+	}
+	products, err := pc.productSvc.List(c.Request.Context(), params)
 
 	if err != nil {
 		log.Error().Err(err).Msg(fmt.Sprintf("Fail to query product list with err=%s", err.Error()))
@@ -54,14 +144,7 @@ func (pc *productController) List(c *gin.Context) {
 	}
 	result := make([]*ProductResponseV1, len(products))
 	for i, p := range products {
-		result[i] = &ProductResponseV1{
-			ProductID:  p.ProductID,
-			UUID:       p.UUID,
-			Name:       p.Name,
-			Brand:      p.Brand,
-			Stock:      p.Stock,
-			SellerUUID: p.SellerUUID,
-		}
+		result[i] = newProductResponseV1(p.Product)
 	}
 	productsJson, err := json.Marshal(result)
 
@@ -75,7 +158,17 @@ func (pc *productController) List(c *gin.Context) {
 }
 
 func (pc *productController) ListV2(c *gin.Context) {
+	pc.listV2(c, false)
+}
+
+// Trash lists soft-deleted products that can still be restored.
+func (pc *productController) Trash(c *gin.Context) {
+	pc.listV2(c, true)
+}
+
+func (pc *productController) listV2(c *gin.Context, deleted bool) {
 	request := &struct {
+		productFilterRequest
 		Page int `form:"page,default=1"`
 	}{}
 
@@ -84,9 +177,16 @@ func (pc *productController) ListV2(c *gin.Context) {
 		return
 	}
 
-	products, err := pc.productSvc.List(c.Request.Context(), &product.FilterParams{Pagination: &product.Pagination{
+	params, err := request.filterParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params.Pagination = &product.Pagination{
 		PageNumber: request.Page,
-	}})
+	}
+	params.Deleted = deleted
+	products, err := pc.productSvc.List(c.Request.Context(), params)
 
 	if err != nil {
 		log.Error().Err(err).Msg(fmt.Sprintf("Fail to query product list with err=%s", err.Error()))
@@ -104,6 +204,39 @@ func (pc *productController) ListV2(c *gin.Context) {
 	c.Data(http.StatusOK, "application/json; charset=utf-8", productsJson)
 }
 
+func (pc *productController) Export(c *gin.Context) {
+	request := &struct {
+		productFilterRequest
+		Format string `form:"format,default=csv"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format, err := product.ParseExportFormat(request.Format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	params, err := request.filterParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="products.%s"`, format))
+	c.Status(http.StatusOK)
+
+	err = pc.productSvc.Export(c.Request.Context(), params, format, c.Writer)
+	if err != nil {
+		// Headers and part of the body may already be sent, so the error can only be logged.
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to export products with err=%s", err.Error()))
+	}
+}
+
 func (pc *productController) Get(c *gin.Context) {
 	request := &struct {
 		UUID string `form:"id" binding:"required"`
@@ -126,15 +259,13 @@ func (pc *productController) Get(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query product by uuid"})
 		return
 	}
+	if !statusVisible(c, p.Product) {
+		c.JSON(http.StatusNotFound, gin.H{"error": product.NewProductNotFoundError(request.UUID).Error()})
+		return
+	}
+	setETag(c, p.Version)
 
-	productJson, err := json.Marshal(&ProductResponseV1{
-		ProductID:  p.ProductID,
-		UUID:       p.UUID,
-		Name:       p.Name,
-		Brand:      p.Brand,
-		Stock:      p.Stock,
-		SellerUUID: p.SellerUUID,
-	})
+	productJson, err := json.Marshal(newProductResponseV1(p.Product))
 
 	if err != nil {
 		log.Error().Err(err).Msg("Fail to marshal product")
@@ -167,6 +298,46 @@ func (pc *productController) GetV2(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query product by uuid"})
 		return
 	}
+	if !statusVisible(c, p.Product) {
+		c.JSON(http.StatusNotFound, gin.H{"error": product.NewProductNotFoundError(request.UUID).Error()})
+		return
+	}
+	setETag(c, p.Version)
+
+	productJson, err := json.Marshal(p)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal product")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal product"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", productJson)
+}
+
+// GetByGTIN looks a product up by the barcode printed on it.
+func (pc *productController) GetByGTIN(c *gin.Context) {
+	p, err := pc.productSvc.FindByGTIN(c.Request.Context(), c.Param("code"))
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to get product by gtin with err=%s", err.Error()))
+
+		if _, ok := err.(*product.InvalidIdentifierError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.ProductNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query product by gtin"})
+		return
+	}
+	if !statusVisible(c, p.Product) {
+		c.JSON(http.StatusNotFound, gin.H{"error": product.NewProductNotFoundError(c.Param("code")).Error()})
+		return
+	}
+	setETag(c, p.Version)
 
 	productJson, err := json.Marshal(p)
 
@@ -181,14 +352,17 @@ func (pc *productController) GetV2(c *gin.Context) {
 
 func (pc *productController) Post(c *gin.Context) {
 	request := &struct {
-		Name   string `form:"name"`
-		Brand  string `form:"brand"`
-		Stock  int    `form:"stock"`
-		Seller string `form:"seller"`
+		Name   string       `form:"name" binding:"required,max=200"`
+		Brand  string       `form:"brand" binding:"required,max=200"`
+		Stock  int          `form:"stock" binding:"min=0"`
+		Seller string       `form:"seller" binding:"required,uuid"`
+		Price  *money.Money `form:"price"`
+		SKU    string       `form:"sku" binding:"max=64"`
+		GTIN   string       `form:"gtin" binding:"max=14"`
+		Status string       `form:"status" binding:"omitempty,oneof=draft published"`
 	}{}
 
-	if err := c.ShouldBindJSON(request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, request) {
 		return
 	}
 	p := &product.Product{
@@ -197,11 +371,30 @@ func (pc *productController) Post(c *gin.Context) {
 		Brand:      request.Brand,
 		Stock:      request.Stock,
 		SellerUUID: request.Seller,
+		Price:      request.Price,
+		SKU:        request.SKU,
+		GTIN:       request.GTIN,
+		Status:     product.Status(request.Status),
 	}
 
-	err := pc.productSvc.Create(c.Request.Context(), p)
+	duplicate, err := pc.productSvc.Create(c.Request.Context(), p)
 	if err != nil {
 		log.Error().Err(err).Msg(fmt.Sprintf("Fail to create product with err=%s", err.Error()))
+		if writeInvalidProduct(c, err) {
+			return
+		}
+		if e, ok := err.(*product.DuplicateProductError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "duplicate_of": e.DuplicateOf()})
+			return
+		}
+		if _, ok := err.(*product.IdentifierConflictError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*brand.BrandNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		if _, ok := err.(*product.SellerNotFoundError); ok {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
@@ -215,7 +408,7 @@ func (pc *productController) Post(c *gin.Context) {
 		return
 	}
 
-	jsonData, err := json.Marshal(p)
+	jsonData, err := json.Marshal(newProductResponseV1(p))
 
 	if err != nil {
 		log.Error().Err(err).Msg("Fail to marshal product")
@@ -223,6 +416,9 @@ func (pc *productController) Post(c *gin.Context) {
 		return
 	}
 
+	if duplicate != nil {
+		setDuplicateWarning(c, duplicate)
+	}
 	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
 }
 
@@ -236,25 +432,135 @@ func (pc *productController) Put(c *gin.Context) {
 		return
 	}
 	request := &struct {
-		Name  string `form:"name"`
-		Brand string `form:"brand"`
-		Stock int    `form:"stock"`
+		Name  string       `form:"name" binding:"required,max=200"`
+		Brand string       `form:"brand" binding:"required,max=200"`
+		Stock int          `form:"stock" binding:"min=0"`
+		Price *money.Money `form:"price"`
+		SKU   string       `form:"sku" binding:"max=64"`
+		GTIN  string       `form:"gtin" binding:"max=14"`
 	}{}
 
-	if err := c.ShouldBindJSON(request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, request) {
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "If-Match header does not match any version"})
 		return
 	}
 	p := &product.Product{
-		UUID:  queryRequest.UUID,
-		Name:  request.Name,
-		Brand: request.Brand,
-		Stock: request.Stock,
+		UUID:    queryRequest.UUID,
+		Name:    request.Name,
+		Brand:   request.Brand,
+		Stock:   request.Stock,
+		Price:   request.Price,
+		SKU:     request.SKU,
+		GTIN:    request.GTIN,
+		Version: version,
 	}
 	err := pc.productSvc.Update(c.Request.Context(), p)
 
 	if err != nil {
 		log.Error().Err(err).Msg(fmt.Sprintf("Fail to update product with err=%s", err.Error()))
+		if writeInvalidProduct(c, err) {
+			return
+		}
+
+		if _, ok := err.(*product.PreconditionFailedError); ok {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.IdentifierConflictError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*brand.BrandNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.DerivedStockError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.HeldStockError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
+		if _, ok := err.(*product.ProductNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.SellerNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	jsonData, err := json.Marshal(newProductResponseV1(p))
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal product")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal product"})
+		return
+	}
+
+	setETag(c, p.Version)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
+
+func (pc *productController) Patch(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	patch, err := product.ParseMergePatch(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "If-Match header does not match any version"})
+		return
+	}
+
+	p, err := pc.productSvc.Patch(c.Request.Context(), c.Param("uuid"), version, patch)
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to patch product with err=%s", err.Error()))
+		if writeInvalidProduct(c, err) {
+			return
+		}
+
+		if _, ok := err.(*product.PreconditionFailedError); ok {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.IdentifierConflictError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.InvalidAttributesError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*brand.BrandNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.DerivedStockError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.HeldStockError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 
 		if _, ok := err.(*product.ProductNotFoundError); ok {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -275,6 +581,7 @@ func (pc *productController) Put(c *gin.Context) {
 		return
 	}
 
+	setETag(c, p.Version)
 	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
 }
 
@@ -288,11 +595,22 @@ func (pc *productController) Delete(c *gin.Context) {
 		return
 	}
 
-	err := pc.productSvc.Delete(c.Request.Context(), request.UUID)
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "If-Match header does not match any version"})
+		return
+	}
+
+	err := pc.productSvc.Delete(c.Request.Context(), request.UUID, version)
 
 	if err != nil {
 		log.Error().Err(err).Msg(fmt.Sprintf("Fail to delete product with err=%s", err.Error()))
 
+		if _, ok := err.(*product.PreconditionFailedError); ok {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
+
 		if _, ok := err.(*product.ProductNotFoundError); ok {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
@@ -302,3 +620,273 @@ func (pc *productController) Delete(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{})
 }
+
+func (pc *productController) AdjustStock(c *gin.Context) {
+	request := &struct {
+		Delta      int    `json:"delta" binding:"required"`
+		Reason     string `json:"reason" binding:"required,max=200"`
+		ReasonCode string `json:"reason_code" binding:"omitempty,oneof=sale return damage restock correction"`
+	}{}
+
+	if !bindJSON(c, request) {
+		return
+	}
+
+	p, err := pc.productSvc.AdjustStock(c.Request.Context(), c.Param("uuid"), &product.StockAdjustment{
+		Delta:  request.Delta,
+		Reason: request.Reason,
+		Code:   product.StockReason(request.ReasonCode),
+	})
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to adjust product stock with err=%s", err.Error()))
+
+		if _, ok := err.(*product.InvalidStockAdjustmentError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.ProductNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.SellerNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.InsufficientStockError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.DerivedStockError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.HeldStockError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	jsonData, err := json.Marshal(&stockAdjustmentResponse{
+		UUID:   p.UUID,
+		Delta:  request.Delta,
+		Reason: request.Reason,
+		Stock:  p.Stock,
+	})
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal stock adjustment")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal stock adjustment"})
+		return
+	}
+
+	setETag(c, p.Version)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
+
+func (pc *productController) SetCategories(c *gin.Context) {
+	request := &struct {
+		Categories []string `json:"categories"`
+	}{}
+
+	if err := c.ShouldBindJSON(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p, err := pc.productSvc.SetCategories(c.Request.Context(), c.Param("uuid"), request.Categories)
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to set product categories with err=%s", err.Error()))
+
+		if _, ok := err.(*product.ProductNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*category.CategoryNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		// The stored attributes do not satisfy the schema of a new category.
+		if _, ok := err.(*product.InvalidAttributesError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	jsonData, err := json.Marshal(p)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal product")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal product"})
+		return
+	}
+
+	setETag(c, p.Version)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
+
+func (pc *productController) Restore(c *gin.Context) {
+	p, err := pc.productSvc.Restore(c.Request.Context(), c.Param("uuid"))
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to restore product with err=%s", err.Error()))
+
+		if _, ok := err.(*product.ProductNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	jsonData, err := json.Marshal(p)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal product")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal product"})
+		return
+	}
+
+	setETag(c, p.Version)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
+
+func (pc *productController) History(c *gin.Context) {
+	request := &struct {
+		Page int `form:"page,default=1"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := pc.productSvc.History(c.Request.Context(), c.Param("uuid"), request.Page)
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to query product history with err=%s", err.Error()))
+
+		if _, ok := err.(*product.ProductNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query product history"})
+		return
+	}
+
+	jsonData, err := json.Marshal(entries)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal product history")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal product history"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
+
+func (pc *productController) PriceHistory(c *gin.Context) {
+	request := &struct {
+		Page int `form:"page,default=1"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	changes, err := pc.productSvc.PriceHistory(c.Request.Context(), c.Param("uuid"), request.Page)
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to query price history with err=%s", err.Error()))
+
+		if _, ok := err.(*product.ProductNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query price history"})
+		return
+	}
+
+	jsonData, err := json.Marshal(changes)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal price history")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal price history"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
+
+func (pc *productController) Revert(c *gin.Context) {
+	request := &struct {
+		Revision int `form:"revision" binding:"required,min=1"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "If-Match header does not match any version"})
+		return
+	}
+
+	p, err := pc.productSvc.Revert(c.Request.Context(), c.Param("uuid"), request.Revision, version)
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to revert product with err=%s", err.Error()))
+		if writeInvalidProduct(c, err) {
+			return
+		}
+
+		if _, ok := err.(*product.PreconditionFailedError); ok {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.InvalidAttributesError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*brand.BrandNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.ProductNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.RevisionNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.HeldStockError); ok {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if _, ok := err.(*product.SellerNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	jsonData, err := json.Marshal(p)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal product")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal product"})
+		return
+	}
+
+	setETag(c, p.Version)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
@@ -4,14 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 
+	"coding-challenge-go/pkg/money"
 	"coding-challenge-go/pkg/product"
+	"coding-challenge-go/pkg/storage"
 )
 
 type (
@@ -54,6 +59,7 @@ func Test_GetProductV1(t *testing.T) {
 						Brand:      "GFG",
 						Stock:      1,
 						SellerUUID: "e6461ea4-d698-11eb-890b-0242ac1a0003",
+						Status:     product.StatusPublished,
 					},
 					Seller: &product.SellerInfo{
 						UUID: "e6461ea4-d698-11eb-890b-0242ac1a0003",
@@ -163,6 +169,7 @@ func Test_GetProductV2(t *testing.T) {
 						Brand:      "GFG",
 						Stock:      1,
 						SellerUUID: "e6461ea4-d698-11eb-890b-0242ac1a0003",
+						Status:     product.StatusPublished,
 					},
 					Seller: &product.SellerInfo{
 						UUID: "e6461ea4-d698-11eb-890b-0242ac1a0003",
@@ -183,6 +190,7 @@ func Test_GetProductV2(t *testing.T) {
 					Brand:      "GFG",
 					Stock:      1,
 					SellerUUID: "e6461ea4-d698-11eb-890b-0242ac1a0003",
+					Status:     product.StatusPublished,
 				},
 				Seller: &product.SellerInfo{
 					UUID: "e6461ea4-d698-11eb-890b-0242ac1a0003",
@@ -526,27 +534,688 @@ func Test_ProductListV2(t *testing.T) {
 
 }
 
+func Test_ExportProducts(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		query        string
+		statusCode   int
+		contentType  string
+		expected     string
+		DoExportFunc func(params *product.FilterParams, format product.ExportFormat, w io.Writer) error
+	}{
+		{
+			name:        "test export csv with filters",
+			query:       "?format=csv&seller=e6461ea4-d698-11eb-890b-0242ac1a0003&brand=GFG",
+			statusCode:  200,
+			contentType: "text/csv; charset=utf-8",
+			expected:    "e6461ea4-d698-11eb-890b-0242ac1a0003|GFG|csv",
+			DoExportFunc: func(params *product.FilterParams, format product.ExportFormat, w io.Writer) error {
+				_, err := fmt.Fprintf(w, "%s|%s|%s", params.SellerUUID, params.Brand, format)
+				return err
+			},
+		},
+		{
+			name:        "test export ndjson",
+			query:       "?format=ndjson",
+			statusCode:  200,
+			contentType: "application/x-ndjson",
+			expected:    "ndjson",
+			DoExportFunc: func(params *product.FilterParams, format product.ExportFormat, w io.Writer) error {
+				_, err := fmt.Fprint(w, format)
+				return err
+			},
+		},
+		{
+			name:        "test export unsupported format",
+			query:       "?format=xml",
+			statusCode:  400,
+			contentType: "application/json; charset=utf-8",
+			expected:    `{"error":"Export format is not supported: xml"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			service := &productServiceMock{
+				DoExportFunc: test.DoExportFunc,
+			}
+			productController := NewProductController(service)
+			router := setupRouter("/api/v2/products/export", productController.Export)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/v2/products/export"+test.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.statusCode, w.Code)
+			assert.Equal(t, test.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, test.expected, w.Body.String())
+		})
+	}
+
+}
+
+func Test_PatchProduct(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		body        string
+		statusCode  int
+		expected    string
+		DoPatchFunc func(uuid string, version int, patch *product.Patch) (*product.ProductInfo, error)
+	}{
+		{
+			name:       "test patch product success",
+			body:       `{"stock":0}`,
+			statusCode: 200,
+			expected:   `{"uuid":"e6461ea4-d698-11eb-890b-0242ac1a0002","name":"product1","brand":"GFG","stock":0,"available_stock":0,"seller_uuid":"s1","price":null,"seller":null}`,
+			DoPatchFunc: func(uuid string, version int, patch *product.Patch) (*product.ProductInfo, error) {
+				p := &product.Product{UUID: uuid, Name: "product1", Brand: "GFG", Stock: 5, SellerUUID: "s1"}
+				patch.Apply(p)
+				return &product.ProductInfo{Product: p}, nil
+			},
+		},
+		{
+			name:       "test patch invalid document",
+			body:       `{"name":null}`,
+			statusCode: 400,
+			expected:   `{"error":"Patch is invalid for field=name: field cannot be removed"}`,
+		},
+		{
+			name:       "test patch breaks the product rules",
+			body:       `{"name":"","stock":-5}`,
+			statusCode: 422,
+			expected: `{"error":"Request is invalid","fields":[` +
+				`{"field":"name","code":"required","message":"is required"},` +
+				`{"field":"stock","code":"too_small","message":"must be at least 0"}]}`,
+			DoPatchFunc: func(uuid string, version int, patch *product.Patch) (*product.ProductInfo, error) {
+				return nil, patch.Validate()
+			},
+		},
+		{
+			name:       "test patch invalid price",
+			body:       `{"price":{"amount":-1}}`,
+			statusCode: 422,
+			expected:   `{"error":"Request is invalid","fields":[{"field":"price","code":"invalid","message":"amount must not be negative"}]}`,
+			DoPatchFunc: func(uuid string, version int, patch *product.Patch) (*product.ProductInfo, error) {
+				return nil, product.NewInvalidPriceError("amount must not be negative")
+			},
+		},
+		{
+			name:       "test patch product is not found",
+			body:       `{"name":"product1"}`,
+			statusCode: 404,
+			expected:   `{"error":"Product is not found with id=e6461ea4-d698-11eb-890b-0242ac1a0002"}`,
+			DoPatchFunc: func(uuid string, version int, patch *product.Patch) (*product.ProductInfo, error) {
+				return nil, product.NewProductNotFoundError(uuid)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			service := &productServiceMock{
+				DoPatchFunc: test.DoPatchFunc,
+			}
+			productController := NewProductController(service)
+			router := gin.Default()
+			router.PATCH("/api/v2/products/:uuid", productController.Patch)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PATCH", "/api/v2/products/e6461ea4-d698-11eb-890b-0242ac1a0002", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.statusCode, w.Code)
+			assert.Equal(t, test.expected, w.Body.String())
+		})
+	}
+
+}
+
+func Test_AdjustStock(t *testing.T) {
+
+	tests := []struct {
+		name              string
+		body              string
+		statusCode        int
+		expected          string
+		DoAdjustStockFunc func(uuid string, adjustment *product.StockAdjustment) (*product.ProductInfo, error)
+	}{
+		{
+			name:       "test adjust stock success",
+			body:       `{"delta":-3,"reason":"order 42"}`,
+			statusCode: 200,
+			expected:   `{"uuid":"p1","delta":-3,"reason":"order 42","stock":41}`,
+			DoAdjustStockFunc: func(uuid string, adjustment *product.StockAdjustment) (*product.ProductInfo, error) {
+				return &product.ProductInfo{Product: &product.Product{UUID: uuid, Stock: 44 + adjustment.Delta}}, nil
+			},
+		},
+		{
+			name:       "test adjust stock below zero",
+			body:       `{"delta":-50,"reason":"order 42"}`,
+			statusCode: 409,
+			expected:   `{"error":"Stock of product id=p1 is 44 and cannot be adjusted by -50"}`,
+			DoAdjustStockFunc: func(uuid string, adjustment *product.StockAdjustment) (*product.ProductInfo, error) {
+				return nil, product.NewInsufficientStockError(uuid, 44, adjustment.Delta)
+			},
+		},
+		{
+			name:       "test adjust stock invalid",
+			body:       `{"delta":0,"reason":"","reason_code":"theft"}`,
+			statusCode: 422,
+			expected: `{"error":"Request is invalid","fields":[` +
+				`{"field":"delta","code":"required","message":"is required"},` +
+				`{"field":"reason","code":"required","message":"is required"},` +
+				`{"field":"reason_code","code":"invalid_choice","message":"must be one of sale return damage restock correction"}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			service := &productServiceMock{
+				DoAdjustStockFunc: test.DoAdjustStockFunc,
+			}
+			productController := NewProductController(service)
+			router := gin.Default()
+			router.POST("/api/v2/products/:uuid/stock-adjustments", productController.AdjustStock)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/v2/products/p1/stock-adjustments", strings.NewReader(test.body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.statusCode, w.Code)
+			assert.Equal(t, test.expected, w.Body.String())
+		})
+	}
+
+}
+
+func Test_ListProductsPriceFilter(t *testing.T) {
+	minPrice := int64(1000)
+
+	tests := []struct {
+		name       string
+		query      string
+		statusCode int
+		expected   *product.FilterParams
+	}{
+		{
+			name:       "test price range with sort",
+			query:      "?currency=EUR&min_price=1000&sort=-price",
+			statusCode: 200,
+			expected: &product.FilterParams{
+				Pagination: &product.Pagination{PageNumber: 1},
+				Currency:   "EUR",
+				MinPrice:   &minPrice,
+				Sort:       product.SortPriceDesc,
+				Statuses:   []product.Status{product.StatusPublished},
+			},
+		},
+		{
+			name:       "test category path",
+			query:      "?category=men/shirts",
+			statusCode: 200,
+			expected: &product.FilterParams{
+				Pagination: &product.Pagination{PageNumber: 1},
+				Category:   "men/shirts",
+				Statuses:   []product.Status{product.StatusPublished},
+			},
+		},
+		{
+			name:       "test price range without currency",
+			query:      "?max_price=1000",
+			statusCode: 400,
+		},
+		{
+			name:       "test unknown currency",
+			query:      "?currency=XYZ",
+			statusCode: 400,
+		},
+		{
+			name:       "test unknown sort",
+			query:      "?sort=name",
+			statusCode: 400,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := &productServiceMock{
+				DoListProductsFunc: func() ([]*product.ProductInfo, error) {
+					return []*product.ProductInfo{}, nil
+				},
+			}
+			productController := NewProductController(service)
+			router := gin.Default()
+			router.GET("/api/v2/products", productController.ListV2)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/v2/products"+test.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.statusCode, w.Code)
+			assert.Equal(t, test.expected, service.DoListParams)
+		})
+	}
+}
+
+func Test_TrashAndRestore(t *testing.T) {
+	deletedAt := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	service := &productServiceMock{
+		DoListProductsFunc: func() ([]*product.ProductInfo, error) {
+			return []*product.ProductInfo{
+				{Product: &product.Product{UUID: "p1", Name: "product1", DeletedAt: &deletedAt}},
+			}, nil
+		},
+		DoRestoreFunc: func(uuid string) (*product.ProductInfo, error) {
+			if uuid != "p1" {
+				return nil, product.NewProductNotFoundError(uuid)
+			}
+			return &product.ProductInfo{Product: &product.Product{UUID: "p1", Name: "product1", Version: 3}}, nil
+		},
+	}
+	productController := NewProductController(service)
+	router := gin.Default()
+	router.GET("/api/v2/products/trash", productController.Trash)
+	router.POST("/api/v2/products/:uuid/restore", productController.Restore)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v2/products/trash?page=2", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `[{"uuid":"p1","name":"product1","brand":"","stock":0,"available_stock":0,"seller_uuid":"","price":null,"deleted_at":"2021-06-01T10:00:00Z","seller":null}]`, w.Body.String())
+	assert.True(t, service.DoListParams.Deleted)
+	assert.Equal(t, 2, service.DoListParams.Pagination.PageNumber)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v2/products/p1/restore", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v2/products/p2/restore", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 404, w.Code)
+}
+
+func Test_GetProductByGTIN(t *testing.T) {
+	service := &productServiceMock{
+		DoFindByGTINFunc: func(code string) (*product.ProductInfo, error) {
+			if err := product.ValidateGTIN(code); err != nil {
+				return nil, err
+			}
+			if code != "4006381333931" {
+				return nil, product.NewProductNotFoundError(code)
+			}
+			return &product.ProductInfo{Product: &product.Product{UUID: "p1", Name: "product1", GTIN: code, Version: 2, Status: product.StatusPublished}}, nil
+		},
+	}
+	productController := NewProductController(service)
+	router := gin.Default()
+	router.GET("/api/v2/products/by-gtin/:code", productController.GetByGTIN)
+	router.POST("/api/v2/products/:uuid/restore", productController.Restore)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v2/products/by-gtin/4006381333931", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	assert.Equal(t, `{"uuid":"p1","name":"product1","brand":"","stock":0,"available_stock":0,"seller_uuid":"","gtin":"4006381333931","price":null,"status":"published","seller":null}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v2/products/by-gtin/4006381333932", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v2/products/by-gtin/036000291452", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 404, w.Code)
+}
+
+func Test_ProductHistory(t *testing.T) {
+	createdAt := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	service := &productServiceMock{
+		DoHistoryFunc: func(uuid string, page int) ([]*product.AuditEntry, error) {
+			if uuid != "p1" {
+				return nil, product.NewProductNotFoundError(uuid)
+			}
+			return []*product.AuditEntry{
+				{
+					ProductUUID: uuid,
+					Revision:    2,
+					Action:      product.AuditActionUpdate,
+					Actor:       "admin@gfg.com",
+					RequestID:   "req-1",
+					Changes:     []*product.FieldChange{{Field: "stock", From: 10, To: 0}},
+					CreatedAt:   createdAt,
+				},
+			}, nil
+		},
+	}
+	productController := NewProductController(service)
+	router := gin.Default()
+	router.GET("/api/v2/products/:uuid/history", productController.History)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v2/products/p1/history", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `[{"product_uuid":"p1","revision":2,"action":"update","actor":"admin@gfg.com","request_id":"req-1",`+
+		`"changes":[{"field":"stock","from":10,"to":0}],"created_at":"2021-06-01T10:00:00Z"}]`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v2/products/p2/history", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 404, w.Code)
+}
+
+func Test_ProductPriceHistory(t *testing.T) {
+	changedAt := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	service := &productServiceMock{
+		DoPriceHistoryFunc: func(uuid string, page int) ([]*product.PriceChange, error) {
+			if uuid != "p1" {
+				return nil, product.NewProductNotFoundError(uuid)
+			}
+			return []*product.PriceChange{
+				{ProductUUID: uuid, Price: &money.Money{Amount: 1999, Currency: "EUR"}, ChangedAt: changedAt},
+				{ProductUUID: uuid, ChangedAt: changedAt.Add(-time.Hour)},
+			}, nil
+		},
+	}
+	productController := NewProductController(service)
+	router := gin.Default()
+	router.GET("/api/v2/products/:uuid/price-history", productController.PriceHistory)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v2/products/p1/price-history", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `[{"product_uuid":"p1","price":{"amount":1999,"currency":"EUR"},"changed_at":"2021-06-01T10:00:00Z"},`+
+		`{"product_uuid":"p1","price":null,"changed_at":"2021-06-01T09:00:00Z"}]`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v2/products/p2/price-history", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 404, w.Code)
+}
+
+func Test_RevertProduct(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		query        string
+		ifMatch      string
+		statusCode   int
+		expected     string
+		DoRevertFunc func(uuid string, revision int, version int) (*product.ProductInfo, error)
+	}{
+		{
+			name:       "test revert success",
+			query:      "?revision=2",
+			ifMatch:    `"5"`,
+			statusCode: 200,
+			expected:   `{"uuid":"p1","name":"rev 2 of version 5","brand":"","stock":0,"available_stock":0,"seller_uuid":"","price":null,"seller":null}`,
+			DoRevertFunc: func(uuid string, revision int, version int) (*product.ProductInfo, error) {
+				return &product.ProductInfo{Product: &product.Product{UUID: uuid, Name: fmt.Sprintf("rev %d of version %d", revision, version), Version: 6}}, nil
+			},
+		},
+		{
+			name:       "test revert without revision",
+			statusCode: 400,
+			expected:   `{"error":"Key: 'Revision' Error:Field validation for 'Revision' failed on the 'required' tag"}`,
+		},
+		{
+			name:       "test revert unknown revision",
+			query:      "?revision=9",
+			statusCode: 404,
+			expected:   `{"error":"Revision 9 is not found for product id=p1"}`,
+			DoRevertFunc: func(uuid string, revision int, version int) (*product.ProductInfo, error) {
+				return nil, product.NewRevisionNotFoundError(uuid, revision)
+			},
+		},
+		{
+			name:       "test revert stale version",
+			query:      "?revision=1",
+			ifMatch:    `"4"`,
+			statusCode: 412,
+			expected:   `{"error":"Product has been modified since it was read with id=p1"}`,
+			DoRevertFunc: func(uuid string, revision int, version int) (*product.ProductInfo, error) {
+				return nil, product.NewPreconditionFailedError(uuid)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			service := &productServiceMock{
+				DoRevertFunc: test.DoRevertFunc,
+			}
+			productController := NewProductController(service)
+			router := gin.Default()
+			router.POST("/api/v2/products/:uuid/revert", productController.Revert)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/v2/products/p1/revert"+test.query, nil)
+			if test.ifMatch != "" {
+				req.Header.Set("If-Match", test.ifMatch)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.statusCode, w.Code)
+			assert.Equal(t, test.expected, w.Body.String())
+		})
+	}
+
+}
+
 type productServiceMock struct {
 	DoGetProductFunc   func(uuid string) (*product.ProductInfo, error)
 	DoListProductsFunc func() ([]*product.ProductInfo, error)
+	DoExportFunc       func(params *product.FilterParams, format product.ExportFormat, w io.Writer) error
+	DoPatchFunc        func(uuid string, version int, patch *product.Patch) (*product.ProductInfo, error)
+	DoCreateFunc       func(p *product.Product) (*product.Duplicate, error)
+	DoUpdateFunc       func(p *product.Product) error
+	DoDeleteFunc       func(uuid string, version int) error
+	DoAdjustStockFunc  func(uuid string, adjustment *product.StockAdjustment) (*product.ProductInfo, error)
+	DoRestoreFunc      func(uuid string) (*product.ProductInfo, error)
+	DoListParams       *product.FilterParams
+	DoHistoryFunc      func(uuid string, page int) ([]*product.AuditEntry, error)
+	DoRevertFunc       func(uuid string, revision int, version int) (*product.ProductInfo, error)
+	DoPriceHistoryFunc func(uuid string, page int) ([]*product.PriceChange, error)
+
+	DoPriceAlertsFunc           func(uuid string) ([]*product.PriceAlert, error)
+	DoSubscribePriceAlertFunc   func(productUUID string, alert *product.PriceAlert) error
+	DoUnsubscribePriceAlertFunc func(productUUID string, uuid string) error
+
+	DoStockMovementsFunc     func(uuid string, page int) ([]*product.StockMovement, error)
+	DoStockDiscrepanciesFunc func(sellerUUID string) ([]*product.StockDiscrepancy, error)
+
+	DoVariantsFunc           func(uuid string) ([]*product.Variant, error)
+	DoCreateVariantFunc      func(productUUID string, variant *product.Variant) error
+	DoAdjustVariantStockFunc func(productUUID string, variantUUID string, adjustment *product.StockAdjustment) (*product.Variant, error)
+	DoDeleteVariantFunc      func(productUUID string, variantUUID string) error
+	DoSetCategoriesFunc      func(uuid string, categoryUUIDs []string) (*product.ProductInfo, error)
+	DoImagesFunc             func(uuid string) ([]*product.ImageInfo, error)
+	DoAddImageFunc           func(productUUID string, r io.Reader) (*product.ImageInfo, error)
+	DoOpenImageFunc          func(productUUID string, imageUUID string, size string) (*product.Image, *storage.Object, error)
+	DoDeleteImageFunc        func(productUUID string, imageUUID string) error
+	DoFindByGTINFunc         func(code string) (*product.ProductInfo, error)
+	DoDuplicatesFunc         func(sellerUUID string, page int) ([]*product.DuplicateGroup, error)
+	DoFindBySKUFunc          func(sellerUUID string, sku string) (*product.ProductInfo, error)
+	DoAdjustStockBatchFunc   func(batch *product.StockBatch) ([]*product.Product, error)
+	DoSetComponentsFunc      func(uuid string, components []*product.Component, version int) (*product.ProductInfo, error)
+
+	DoLocationStockFunc       func(uuid string) ([]*product.LocationStock, error)
+	DoAdjustLocationStockFunc func(productUUID string, locationUUID string, adjustment *product.StockAdjustment) (*product.LocationStock, error)
+	DoMoveStockFunc           func(productUUID string, move *product.StockMove) ([]*product.LocationStock, error)
+	DoSetStatusFunc           func(uuid string, status product.Status, version int) (*product.ProductInfo, error)
 }
 
 func (m *productServiceMock) FindByUUID(ctx context.Context, uuid string) (*product.ProductInfo, error) {
 	return m.DoGetProductFunc(uuid)
 }
 
-func (m *productServiceMock) Create(ctx context.Context, p *product.Product) error {
-	return nil
+func (m *productServiceMock) LocationStock(ctx context.Context, uuid string) ([]*product.LocationStock, error) {
+	return m.DoLocationStockFunc(uuid)
+}
+
+func (m *productServiceMock) AdjustLocationStock(ctx context.Context, productUUID string, locationUUID string, adjustment *product.StockAdjustment) (*product.LocationStock, error) {
+	return m.DoAdjustLocationStockFunc(productUUID, locationUUID, adjustment)
+}
+
+func (m *productServiceMock) MoveStock(ctx context.Context, productUUID string, move *product.StockMove) ([]*product.LocationStock, error) {
+	return m.DoMoveStockFunc(productUUID, move)
+}
+
+func (m *productServiceMock) FindByGTIN(ctx context.Context, code string) (*product.ProductInfo, error) {
+	return m.DoFindByGTINFunc(code)
+}
+
+func (m *productServiceMock) FindBySKU(ctx context.Context, sellerUUID string, sku string) (*product.ProductInfo, error) {
+	return m.DoFindBySKUFunc(sellerUUID, sku)
+}
+
+func (m *productServiceMock) Create(ctx context.Context, p *product.Product) (*product.Duplicate, error) {
+	if m.DoCreateFunc == nil {
+		return nil, nil
+	}
+	return m.DoCreateFunc(p)
+}
+
+func (m *productServiceMock) SetStatus(ctx context.Context, uuid string, status product.Status, version int) (*product.ProductInfo, error) {
+	return m.DoSetStatusFunc(uuid, status, version)
+}
+
+func (m *productServiceMock) Duplicates(ctx context.Context, sellerUUID string, page int) ([]*product.DuplicateGroup, error) {
+	return m.DoDuplicatesFunc(sellerUUID, page)
 }
 
 func (m *productServiceMock) Update(ctx context.Context, p *product.Product) error {
-	return nil
+	return m.DoUpdateFunc(p)
 }
 
-func (m *productServiceMock) Delete(ctx context.Context, uuid string) error {
-	return nil
+func (m *productServiceMock) Delete(ctx context.Context, uuid string, version int) error {
+	return m.DoDeleteFunc(uuid, version)
 }
 
 func (m *productServiceMock) List(ctx context.Context, params *product.FilterParams) ([]*product.ProductInfo, error) {
+	m.DoListParams = params
 	return m.DoListProductsFunc()
 }
+
+func (m *productServiceMock) Export(ctx context.Context, params *product.FilterParams, format product.ExportFormat, w io.Writer) error {
+	return m.DoExportFunc(params, format, w)
+}
+
+func (m *productServiceMock) Patch(ctx context.Context, uuid string, version int, patch *product.Patch) (*product.ProductInfo, error) {
+	return m.DoPatchFunc(uuid, version, patch)
+}
+
+func (m *productServiceMock) AdjustStock(ctx context.Context, uuid string, adjustment *product.StockAdjustment) (*product.ProductInfo, error) {
+	return m.DoAdjustStockFunc(uuid, adjustment)
+}
+
+func (m *productServiceMock) AdjustStockBatch(ctx context.Context, batch *product.StockBatch) ([]*product.Product, error) {
+	return m.DoAdjustStockBatchFunc(batch)
+}
+
+func (m *productServiceMock) Restore(ctx context.Context, uuid string) (*product.ProductInfo, error) {
+	return m.DoRestoreFunc(uuid)
+}
+
+func (m *productServiceMock) PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error) {
+	return 0, nil
+}
+
+func (m *productServiceMock) History(ctx context.Context, uuid string, page int) ([]*product.AuditEntry, error) {
+	return m.DoHistoryFunc(uuid, page)
+}
+
+func (m *productServiceMock) Revert(ctx context.Context, uuid string, revision int, version int) (*product.ProductInfo, error) {
+	return m.DoRevertFunc(uuid, revision, version)
+}
+
+func (m *productServiceMock) PriceHistory(ctx context.Context, uuid string, page int) ([]*product.PriceChange, error) {
+	return m.DoPriceHistoryFunc(uuid, page)
+}
+
+func (m *productServiceMock) PriceAlerts(ctx context.Context, uuid string) ([]*product.PriceAlert, error) {
+	return m.DoPriceAlertsFunc(uuid)
+}
+
+func (m *productServiceMock) SubscribePriceAlert(ctx context.Context, productUUID string, alert *product.PriceAlert) error {
+	return m.DoSubscribePriceAlertFunc(productUUID, alert)
+}
+
+func (m *productServiceMock) UnsubscribePriceAlert(ctx context.Context, productUUID string, uuid string) error {
+	return m.DoUnsubscribePriceAlertFunc(productUUID, uuid)
+}
+
+func (m *productServiceMock) StockMovements(ctx context.Context, uuid string, page int) ([]*product.StockMovement, error) {
+	return m.DoStockMovementsFunc(uuid, page)
+}
+
+func (m *productServiceMock) StockDiscrepancies(ctx context.Context, sellerUUID string) ([]*product.StockDiscrepancy, error) {
+	return m.DoStockDiscrepanciesFunc(sellerUUID)
+}
+
+func (m *productServiceMock) Variants(ctx context.Context, uuid string) ([]*product.Variant, error) {
+	return m.DoVariantsFunc(uuid)
+}
+
+func (m *productServiceMock) CreateVariant(ctx context.Context, productUUID string, variant *product.Variant) error {
+	return m.DoCreateVariantFunc(productUUID, variant)
+}
+
+func (m *productServiceMock) AdjustVariantStock(ctx context.Context, productUUID string, variantUUID string, adjustment *product.StockAdjustment) (*product.Variant, error) {
+	return m.DoAdjustVariantStockFunc(productUUID, variantUUID, adjustment)
+}
+
+func (m *productServiceMock) DeleteVariant(ctx context.Context, productUUID string, variantUUID string) error {
+	return m.DoDeleteVariantFunc(productUUID, variantUUID)
+}
+
+func (m *productServiceMock) SetCategories(ctx context.Context, uuid string, categoryUUIDs []string) (*product.ProductInfo, error) {
+	return m.DoSetCategoriesFunc(uuid, categoryUUIDs)
+}
+
+func (m *productServiceMock) SetComponents(ctx context.Context, uuid string, components []*product.Component, version int) (*product.ProductInfo, error) {
+	return m.DoSetComponentsFunc(uuid, components, version)
+}
+
+func (m *productServiceMock) Images(ctx context.Context, uuid string) ([]*product.ImageInfo, error) {
+	return m.DoImagesFunc(uuid)
+}
+
+func (m *productServiceMock) AddImage(ctx context.Context, productUUID string, r io.Reader) (*product.ImageInfo, error) {
+	return m.DoAddImageFunc(productUUID, r)
+}
+
+func (m *productServiceMock) OpenImage(ctx context.Context, productUUID string, imageUUID string, size string) (*product.Image, *storage.Object, error) {
+	return m.DoOpenImageFunc(productUUID, imageUUID, size)
+}
+
+func (m *productServiceMock) DeleteImage(ctx context.Context, productUUID string, imageUUID string) error {
+	return m.DoDeleteImageFunc(productUUID, imageUUID)
+}
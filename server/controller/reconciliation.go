@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/product"
+	"coding-challenge-go/pkg/reconciliation"
+)
+
+func NewReconciliationController(reconciliationSvc reconciliation.Service) *reconciliationController {
+	return &reconciliationController{
+		reconciliationSvc: reconciliationSvc,
+	}
+}
+
+type reconciliationController struct {
+	reconciliationSvc reconciliation.Service
+}
+
+func (rc *reconciliationController) List(c *gin.Context) {
+	request := &struct {
+		Seller string `form:"seller"`
+		Status string `form:"status" binding:"omitempty,oneof=pending approved applied rejected"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reconciliations, err := rc.reconciliationSvc.List(c.Request.Context(), request.Seller, reconciliation.Status(request.Status))
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to query reconciliations with err=%s", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query reconciliations"})
+		return
+	}
+
+	rc.respond(c, http.StatusOK, reconciliations)
+}
+
+func (rc *reconciliationController) Get(c *gin.Context) {
+	result, err := rc.reconciliationSvc.FindByUUID(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to get reconciliation with err=%s", err.Error()))
+		rc.handleError(c, err)
+		return
+	}
+
+	rc.respond(c, http.StatusOK, result)
+}
+
+// Post takes the counted stock as a CSV file in the file field of a multipart form.
+func (rc *reconciliationController) Post(c *gin.Context) {
+	request := &struct {
+		Seller string `form:"seller"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "multipart form has no file field"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		result, err := rc.reconciliationSvc.Create(c.Request.Context(), request.Seller, part)
+		if err != nil {
+			log.Error().Err(err).Msg(fmt.Sprintf("Fail to create reconciliation with err=%s", err.Error()))
+			rc.handleError(c, err)
+			return
+		}
+
+		rc.respond(c, http.StatusCreated, result)
+		return
+	}
+}
+
+func (rc *reconciliationController) Approve(c *gin.Context) {
+	result, err := rc.reconciliationSvc.Approve(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to approve reconciliation with err=%s", err.Error()))
+		rc.handleError(c, err)
+		return
+	}
+
+	rc.respond(c, http.StatusOK, result)
+}
+
+func (rc *reconciliationController) Reject(c *gin.Context) {
+	result, err := rc.reconciliationSvc.Reject(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to reject reconciliation with err=%s", err.Error()))
+		rc.handleError(c, err)
+		return
+	}
+
+	rc.respond(c, http.StatusOK, result)
+}
+
+// handleError reports a batch that cannot be applied as a conflict: the stock or
+// the products changed since the count was uploaded.
+func (rc *reconciliationController) handleError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *reconciliation.InvalidReconciliationError:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case *reconciliation.ReconciliationNotFoundError:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case *reconciliation.ReconciliationNotPendingError, *product.InsufficientStockError, *product.HeldStockError, *product.DerivedStockError,
+		*product.ProductNotFoundError:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (rc *reconciliationController) respond(c *gin.Context, status int, v interface{}) {
+	jsonData, err := json.Marshal(v)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal reconciliation")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal reconciliation"})
+		return
+	}
+
+	c.Data(status, "application/json; charset=utf-8", jsonData)
+}
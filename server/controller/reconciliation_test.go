@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/product"
+	"coding-challenge-go/pkg/reconciliation"
+)
+
+func Test_PostReconciliation(t *testing.T) {
+	createdAt := time.Date(2021, 9, 1, 18, 0, 0, 0, time.UTC)
+	var uploaded, seller string
+	service := &reconciliationServiceMock{
+		DoCreateFunc: func(sellerUUID string, r io.Reader) (*reconciliation.Reconciliation, error) {
+			data, _ := ioutil.ReadAll(r)
+			uploaded, seller = string(data), sellerUUID
+			if uploaded == "" {
+				return nil, reconciliation.NewInvalidReconciliationError("file is empty")
+			}
+			return &reconciliation.Reconciliation{
+				UUID:       "r1",
+				SellerUUID: sellerUUID,
+				Status:     reconciliation.Pending,
+				Lines:      []*reconciliation.Line{{Row: 2, ProductUUID: "p1", SKU: "PT-1", Name: "Plano Tee", Expected: 10, Counted: 8, Difference: -2}},
+				CreatedBy:  "warehouse@gfg.com",
+				CreatedAt:  createdAt,
+			}, nil
+		},
+	}
+	reconciliationController := NewReconciliationController(service)
+	router := gin.Default()
+	router.POST("/api/v2/reconciliations", reconciliationController.Post)
+
+	tests := []struct {
+		name       string
+		field      string
+		content    string
+		statusCode int
+		expected   string
+	}{
+		{
+			name:       "test upload count",
+			field:      "file",
+			content:    "sku,counted\nPT-1,8\n",
+			statusCode: 201,
+			expected: `{"uuid":"r1","seller_uuid":"s1","status":"pending","lines":[{"row":2,"product_uuid":"p1","sku":"PT-1","name":"Plano Tee",` +
+				`"expected":10,"counted":8,"difference":-2}],"created_by":"warehouse@gfg.com","created_at":"2021-09-01T18:00:00Z"}`,
+		},
+		{
+			name:       "test invalid count",
+			field:      "file",
+			content:    "",
+			statusCode: 400,
+			expected:   `{"error":"Stock count is invalid: file is empty"}`,
+		},
+		{
+			name:       "test file field is required",
+			field:      "counts",
+			content:    "sku,counted\nPT-1,8\n",
+			statusCode: 400,
+			expected:   `{"error":"multipart form has no file field"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &bytes.Buffer{}
+			form := multipart.NewWriter(body)
+			part, _ := form.CreateFormFile(tt.field, "count.csv")
+			part.Write([]byte(tt.content))
+			form.Close()
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/v2/reconciliations?seller=s1", body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.expected, w.Body.String())
+		})
+	}
+	assert.Equal(t, "s1", seller)
+}
+
+func Test_ApproveReconciliation(t *testing.T) {
+	service := &reconciliationServiceMock{
+		DoApproveFunc: func(uuid string) (*reconciliation.Reconciliation, error) {
+			switch uuid {
+			case "r1":
+				return &reconciliation.Reconciliation{UUID: uuid, Status: reconciliation.Applied, Lines: []*reconciliation.Line{}}, nil
+			case "r2":
+				return nil, reconciliation.NewReconciliationNotPendingError(uuid, reconciliation.Rejected)
+			case "r3":
+				return nil, product.NewInsufficientStockError("p1", 1, -2)
+			}
+			return nil, reconciliation.NewReconciliationNotFoundError(uuid)
+		},
+	}
+	reconciliationController := NewReconciliationController(service)
+	router := gin.Default()
+	router.POST("/api/v2/reconciliations/:uuid/approve", reconciliationController.Approve)
+
+	tests := []struct {
+		uuid       string
+		statusCode int
+	}{
+		{uuid: "r1", statusCode: 200},
+		{uuid: "r2", statusCode: 409},
+		{uuid: "r3", statusCode: 409},
+		{uuid: "r4", statusCode: 404},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v2/reconciliations/"+tt.uuid+"/approve", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.statusCode, w.Code, tt.uuid)
+	}
+}
+
+type reconciliationServiceMock struct {
+	reconciliation.Service
+	DoCreateFunc  func(sellerUUID string, r io.Reader) (*reconciliation.Reconciliation, error)
+	DoApproveFunc func(uuid string) (*reconciliation.Reconciliation, error)
+}
+
+func (m *reconciliationServiceMock) Create(ctx context.Context, sellerUUID string, r io.Reader) (*reconciliation.Reconciliation, error) {
+	return m.DoCreateFunc(sellerUUID, r)
+}
+
+func (m *reconciliationServiceMock) Approve(ctx context.Context, uuid string) (*reconciliation.Reconciliation, error) {
+	return m.DoApproveFunc(uuid)
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"coding-challenge-go/pkg/requestinfo"
+)
+
+const (
+	actorHeader     = "X-Actor"
+	requestIDHeader = "X-Request-ID"
+)
+
+// RequestInfo stores the actor and request ID of the request in its context. A
+// request ID is generated when the client does not send one, and is echoed back.
+// Values that would not fit the audit and ledger columns are rejected with 400,
+// since those records are written after the change and cannot fail it.
+func RequestInfo(c *gin.Context) {
+	requestID := c.GetHeader(requestIDHeader)
+	if requestID == "" {
+		requestID = uuid.New().String()
+	} else if err := checkHeader(requestIDHeader, requestID, requestinfo.MaxRequestIDLength, isRequestIDRune); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header(requestIDHeader, requestID)
+
+	actor := c.GetHeader(actorHeader)
+	if err := checkHeader(actorHeader, actor, requestinfo.MaxActorLength, unicode.IsPrint); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := requestinfo.WithRequestID(c.Request.Context(), requestID)
+	ctx = requestinfo.WithActor(ctx, actor)
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+}
+
+// checkHeader rejects a value longer than max characters or with a character that is not valid.
+func checkHeader(name string, value string, max int, valid func(r rune) bool) error {
+	if !utf8.ValidString(value) {
+		return fmt.Errorf("%s header is not valid UTF-8", name)
+	}
+	if utf8.RuneCountInString(value) > max {
+		return fmt.Errorf("%s header must not be longer than %d characters", name, max)
+	}
+	for _, r := range value {
+		if !valid(r) {
+			return fmt.Errorf("%s header contains an invalid character %q", name, r)
+		}
+	}
+	return nil
+}
+
+// isRequestIDRune allows the letters, digits and separators request IDs are made of.
+func isRequestIDRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' || r == ':')
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/requestinfo"
+)
+
+func Test_RequestInfo(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		headers       map[string]string
+		wantActor     string
+		wantRequestID string
+		wantCode      int
+	}{
+		{
+			name:          "test request info from headers",
+			headers:       map[string]string{"X-Actor": "admin@gfg.com", "X-Request-ID": "req-1"},
+			wantActor:     "admin@gfg.com",
+			wantRequestID: "req-1",
+		},
+		{
+			name:      "test request info without headers",
+			wantActor: requestinfo.AnonymousActor,
+		},
+		{
+			name:     "test actor too long for the audit log",
+			headers:  map[string]string{"X-Actor": strings.Repeat("a", 201)},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "test request ID too long",
+			headers:  map[string]string{"X-Request-ID": strings.Repeat("1", 65)},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "test request ID with spaces",
+			headers:  map[string]string{"X-Request-ID": "req 1"},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			var actor, requestID string
+			router := gin.Default()
+			router.Use(RequestInfo)
+			router.GET("/", func(c *gin.Context) {
+				actor = requestinfo.Actor(c.Request.Context())
+				requestID = requestinfo.RequestID(c.Request.Context())
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+			router.ServeHTTP(w, req)
+
+			if test.wantCode != 0 {
+				assert.Equal(t, test.wantCode, w.Code)
+				assert.Empty(t, actor)
+				return
+			}
+			assert.Equal(t, test.wantActor, actor)
+			assert.Equal(t, requestID, w.Header().Get("X-Request-ID"))
+			if test.wantRequestID != "" {
+				assert.Equal(t, test.wantRequestID, requestID)
+			} else {
+				assert.NotEmpty(t, requestID)
+			}
+		})
+	}
+
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/product"
+	"coding-challenge-go/pkg/reservation"
+)
+
+func NewReservationController(reservationSvc reservation.Service) *reservationController {
+	return &reservationController{
+		reservationSvc: reservationSvc,
+	}
+}
+
+type reservationController struct {
+	reservationSvc reservation.Service
+}
+
+func (rc *reservationController) Post(c *gin.Context) {
+	request := &struct {
+		Product    string `json:"product" binding:"required"`
+		Quantity   int    `json:"quantity" binding:"required,min=1"`
+		TTLSeconds int    `json:"ttl_seconds" binding:"min=0,max=86400"`
+	}{}
+
+	if !bindJSON(c, request) {
+		return
+	}
+
+	r, err := rc.reservationSvc.Create(c.Request.Context(), request.Product, request.Quantity, time.Duration(request.TTLSeconds)*time.Second)
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to create reservation with err=%s", err.Error()))
+		rc.handleError(c, err)
+		return
+	}
+
+	rc.respond(c, http.StatusCreated, r)
+}
+
+func (rc *reservationController) Get(c *gin.Context) {
+	r, err := rc.reservationSvc.FindByUUID(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to get reservation with err=%s", err.Error()))
+		rc.handleError(c, err)
+		return
+	}
+
+	rc.respond(c, http.StatusOK, r)
+}
+
+func (rc *reservationController) Confirm(c *gin.Context) {
+	r, err := rc.reservationSvc.Confirm(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to confirm reservation with err=%s", err.Error()))
+		rc.handleError(c, err)
+		return
+	}
+
+	rc.respond(c, http.StatusOK, r)
+}
+
+func (rc *reservationController) Release(c *gin.Context) {
+	r, err := rc.reservationSvc.Release(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to release reservation with err=%s", err.Error()))
+		rc.handleError(c, err)
+		return
+	}
+
+	rc.respond(c, http.StatusOK, r)
+}
+
+func (rc *reservationController) handleError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *reservation.InvalidReservationError:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case *reservation.ReservationNotFoundError, *product.ProductNotFoundError, *product.SellerNotFoundError:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case *reservation.InsufficientAvailableStockError, *reservation.ReservationNotActiveError, *product.InsufficientStockError, *product.HeldStockError, *product.DerivedStockError:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (rc *reservationController) respond(c *gin.Context, status int, r *reservation.Reservation) {
+	jsonData, err := json.Marshal(r)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal reservation")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal reservation"})
+		return
+	}
+
+	c.Data(status, "application/json; charset=utf-8", jsonData)
+}
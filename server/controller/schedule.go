@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/product"
+	"coding-challenge-go/pkg/schedule"
+)
+
+func NewScheduleController(scheduleSvc schedule.Service) *scheduleController {
+	return &scheduleController{
+		scheduleSvc: scheduleSvc,
+	}
+}
+
+type (
+	scheduleController struct {
+		scheduleSvc schedule.Service
+	}
+
+	scheduledChangeRequest struct {
+		Patch       json.RawMessage `json:"patch" binding:"required"`
+		EffectiveAt time.Time       `json:"effective_at" binding:"required"`
+	}
+)
+
+func (sc *scheduleController) List(c *gin.Context) {
+	request := &struct {
+		Product string `form:"product"`
+		Status  string `form:"status" binding:"omitempty,oneof=pending running applied failed"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	changes, err := sc.scheduleSvc.List(c.Request.Context(), request.Product, schedule.Status(request.Status))
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to query scheduled changes with err=%s", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query scheduled changes"})
+		return
+	}
+
+	sc.respond(c, http.StatusOK, changes)
+}
+
+func (sc *scheduleController) Get(c *gin.Context) {
+	change, err := sc.scheduleSvc.FindByUUID(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to get scheduled change with err=%s", err.Error()))
+		sc.handleError(c, err)
+		return
+	}
+
+	sc.respond(c, http.StatusOK, change)
+}
+
+func (sc *scheduleController) Post(c *gin.Context) {
+	request := &struct {
+		scheduledChangeRequest
+		Product string `json:"product" binding:"required"`
+	}{}
+
+	if !bindJSON(c, request) {
+		return
+	}
+
+	change := &schedule.Change{ProductUUID: request.Product, Patch: request.Patch, EffectiveAt: request.EffectiveAt}
+	if err := sc.scheduleSvc.Create(c.Request.Context(), change); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to create scheduled change with err=%s", err.Error()))
+		sc.handleError(c, err)
+		return
+	}
+
+	sc.respond(c, http.StatusCreated, change)
+}
+
+func (sc *scheduleController) Put(c *gin.Context) {
+	request := &scheduledChangeRequest{}
+
+	if !bindJSON(c, request) {
+		return
+	}
+
+	change := &schedule.Change{UUID: c.Param("uuid"), Patch: request.Patch, EffectiveAt: request.EffectiveAt}
+	if err := sc.scheduleSvc.Update(c.Request.Context(), change); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to update scheduled change with err=%s", err.Error()))
+		sc.handleError(c, err)
+		return
+	}
+
+	sc.respond(c, http.StatusOK, change)
+}
+
+func (sc *scheduleController) Delete(c *gin.Context) {
+	if err := sc.scheduleSvc.Delete(c.Request.Context(), c.Param("uuid")); err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to delete scheduled change with err=%s", err.Error()))
+		sc.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (sc *scheduleController) handleError(c *gin.Context, err error) {
+	if writeInvalidProduct(c, err) {
+		return
+	}
+	switch err.(type) {
+	case *schedule.InvalidChangeError:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case *schedule.ChangeNotFoundError, *product.ProductNotFoundError:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case *schedule.ChangeNotPendingError:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (sc *scheduleController) respond(c *gin.Context, status int, v interface{}) {
+	jsonData, err := json.Marshal(v)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal scheduled change")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal scheduled change"})
+		return
+	}
+
+	c.Data(status, "application/json; charset=utf-8", jsonData)
+}
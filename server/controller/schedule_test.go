@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/product"
+	"coding-challenge-go/pkg/schedule"
+)
+
+func Test_ScheduledChanges(t *testing.T) {
+	createdAt := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+	service := &scheduleServiceMock{
+		DoCreateFunc: func(change *schedule.Change) error {
+			if change.ProductUUID != "p1" {
+				return product.NewProductNotFoundError(change.ProductUUID)
+			}
+			if string(change.Patch) == `{}` {
+				return schedule.NewInvalidChangeError("patch must change at least one field")
+			}
+			change.UUID = "c1"
+			change.Status = schedule.Pending
+			change.NextAttemptAt = change.EffectiveAt
+			change.CreatedBy = "merch@gfg.com"
+			change.CreatedAt = createdAt
+			return nil
+		},
+		DoUpdateFunc: func(change *schedule.Change) error {
+			return schedule.NewChangeNotPendingError(change.UUID, schedule.Applied)
+		},
+	}
+	scheduleController := NewScheduleController(service)
+	router := gin.Default()
+	router.POST("/api/v2/scheduled-changes", scheduleController.Post)
+	router.PUT("/api/v2/scheduled-changes/:uuid", scheduleController.Put)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+		expected   string
+	}{
+		{
+			name:       "test schedule a launch",
+			method:     "POST",
+			path:       "/api/v2/scheduled-changes",
+			body:       `{"product":"p1","patch":{"stock":100,"status":"published"},"effective_at":"2021-09-01T08:00:00Z"}`,
+			statusCode: 201,
+			expected: `{"uuid":"c1","product_uuid":"p1","patch":{"stock":100,"status":"published"},"effective_at":"2021-09-01T08:00:00Z",` +
+				`"status":"pending","attempts":0,"next_attempt_at":"2021-09-01T08:00:00Z","created_by":"merch@gfg.com","created_at":"2021-08-01T12:00:00Z"}`,
+		},
+		{
+			name:       "test schedule without effective time",
+			method:     "POST",
+			path:       "/api/v2/scheduled-changes",
+			body:       `{"product":"p1","patch":{"stock":100}}`,
+			statusCode: 422,
+			expected:   `{"error":"Request is invalid","fields":[{"field":"effective_at","code":"required","message":"is required"}]}`,
+		},
+		{
+			name:       "test schedule empty patch",
+			method:     "POST",
+			path:       "/api/v2/scheduled-changes",
+			body:       `{"product":"p1","patch":{},"effective_at":"2021-09-01T08:00:00Z"}`,
+			statusCode: 400,
+			expected:   `{"error":"Scheduled change is invalid: patch must change at least one field"}`,
+		},
+		{
+			name:       "test schedule unknown product",
+			method:     "POST",
+			path:       "/api/v2/scheduled-changes",
+			body:       `{"product":"p2","patch":{"stock":100},"effective_at":"2021-09-01T08:00:00Z"}`,
+			statusCode: 404,
+			expected:   `{"error":"Product is not found with id=p2"}`,
+		},
+		{
+			name:       "test update applied change",
+			method:     "PUT",
+			path:       "/api/v2/scheduled-changes/c1",
+			body:       `{"patch":{"stock":120},"effective_at":"2021-09-01T08:00:00Z"}`,
+			statusCode: 409,
+			expected:   `{"error":"Scheduled change id=c1 is applied and can no longer be changed"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.statusCode, w.Code)
+			assert.Equal(t, test.expected, w.Body.String())
+		})
+	}
+}
+
+type scheduleServiceMock struct {
+	schedule.Service
+	DoCreateFunc func(change *schedule.Change) error
+	DoUpdateFunc func(change *schedule.Change) error
+}
+
+func (m *scheduleServiceMock) Create(ctx context.Context, change *schedule.Change) error {
+	return m.DoCreateFunc(change)
+}
+
+func (m *scheduleServiceMock) Update(ctx context.Context, change *schedule.Change) error {
+	return m.DoUpdateFunc(change)
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/product"
+)
+
+const (
+	managementTokenHeader = "X-Management-Token"
+	managementKey         = "management"
+)
+
+// Management marks requests that send the configured token in X-Management-Token
+// as coming from a seller or admin tool, which may see products that are not
+// published. A wrong token is rejected with 401. Without a token configured every
+// request is treated as a shopper's.
+func Management(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sent := c.GetHeader(managementTokenHeader)
+		if sent == "" {
+			c.Next()
+			return
+		}
+		if token == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": managementTokenHeader + " is invalid"})
+			return
+		}
+		c.Set(managementKey, true)
+		c.Next()
+	}
+}
+
+func isManagementRequest(c *gin.Context) bool {
+	return c.GetBool(managementKey)
+}
+
+// visibleStatuses returns the statuses a list request may see. Shoppers only see
+// published products, while management requests see drafts and published products
+// unless they ask for other statuses, e.g. status=archived.
+func visibleStatuses(c *gin.Context, requested string) ([]product.Status, error) {
+	if !isManagementRequest(c) {
+		if requested != "" && requested != string(product.StatusPublished) {
+			return nil, product.NewInvalidFilterError("status", "only published products are public")
+		}
+		return []product.Status{product.StatusPublished}, nil
+	}
+	if requested == "" {
+		return []product.Status{product.StatusDraft, product.StatusPublished}, nil
+	}
+	statuses, err := product.ParseStatuses(requested)
+	if err != nil {
+		return nil, product.NewInvalidFilterError("status", err.Error())
+	}
+	return statuses, nil
+}
+
+// statusVisible reports whether the request may see p.
+func statusVisible(c *gin.Context, p *product.Product) bool {
+	return p.Status == product.StatusPublished || isManagementRequest(c)
+}
+
+// RequireVisible answers 404 for a product the request may not see, so the
+// history, stock, variants, locations and images of a draft or archived product
+// stay as hidden as the product itself.
+func (pc *productController) RequireVisible(c *gin.Context) {
+	if isManagementRequest(c) {
+		c.Next()
+		return
+	}
+
+	p, err := pc.productSvc.FindByUUID(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to get product with err=%s", err.Error()))
+
+		if _, ok := err.(*product.ProductNotFoundError); ok {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Fail to query product by uuid"})
+		return
+	}
+	if !statusVisible(c, p.Product) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": product.NewProductNotFoundError(c.Param("uuid")).Error()})
+		return
+	}
+	c.Next()
+}
+
+// SetStatus moves a product through its lifecycle, e.g. publishes a draft.
+func (pc *productController) SetStatus(c *gin.Context) {
+	request := &struct {
+		Status string `json:"status" binding:"required,oneof=draft published archived"`
+	}{}
+
+	if !bindJSON(c, request) {
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "If-Match header does not match any version"})
+		return
+	}
+
+	p, err := pc.productSvc.SetStatus(c.Request.Context(), c.Param("uuid"), product.Status(request.Status), version)
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to change product status with err=%s", err.Error()))
+
+		switch err.(type) {
+		case *product.PreconditionFailedError:
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		case *product.StatusTransitionError:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case *product.ProductNotFoundError:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to change product status"})
+		}
+		return
+	}
+	setETag(c, p.Version)
+
+	jsonData, err := json.Marshal(p)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal product")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal product"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
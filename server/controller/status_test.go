@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/product"
+)
+
+func Test_SetProductStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		ifMatch    string
+		statusCode int
+		expected   string
+	}{
+		{
+			name:       "test publish draft",
+			body:       `{"status":"published"}`,
+			ifMatch:    `"3"`,
+			statusCode: 200,
+			expected:   `{"uuid":"p1","name":"","brand":"","stock":0,"available_stock":0,"seller_uuid":"","price":null,"status":"published","seller":null}`,
+		},
+		{
+			name:       "test transition not allowed",
+			body:       `{"status":"draft"}`,
+			statusCode: 409,
+			expected:   `{"error":"Product id=p1 cannot move from published to draft"}`,
+		},
+		{
+			name:       "test unknown status",
+			body:       `{"status":"deleted"}`,
+			statusCode: 422,
+			expected: `{"error":"Request is invalid","fields":[` +
+				`{"field":"status","code":"invalid_choice","message":"must be one of draft published archived"}]}`,
+		},
+		{
+			name:       "test stale version",
+			body:       `{"status":"archived"}`,
+			ifMatch:    `"2"`,
+			statusCode: 412,
+			expected:   `{"error":"Product has been modified since it was read with id=p1"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := &productServiceMock{
+				DoSetStatusFunc: func(uuid string, status product.Status, version int) (*product.ProductInfo, error) {
+					if version != 0 && version != 3 {
+						return nil, product.NewPreconditionFailedError(uuid)
+					}
+					if status == product.StatusDraft {
+						return nil, product.NewStatusTransitionError(uuid, product.StatusPublished, status)
+					}
+					return &product.ProductInfo{Product: &product.Product{UUID: uuid, Status: status, Version: 4}}, nil
+				},
+			}
+			productController := NewProductController(service)
+			router := gin.Default()
+			router.POST("/api/v2/products/:uuid/status", productController.SetStatus)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/v2/products/p1/status", strings.NewReader(test.body))
+			if test.ifMatch != "" {
+				req.Header.Set("If-Match", test.ifMatch)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.statusCode, w.Code)
+			assert.Equal(t, test.expected, w.Body.String())
+		})
+	}
+}
+
+func Test_ProductStatusVisibility(t *testing.T) {
+	service := &productServiceMock{
+		DoGetProductFunc: func(uuid string) (*product.ProductInfo, error) {
+			return &product.ProductInfo{Product: &product.Product{UUID: uuid, Status: product.StatusDraft}}, nil
+		},
+		DoListProductsFunc: func() ([]*product.ProductInfo, error) {
+			return []*product.ProductInfo{}, nil
+		},
+		DoVariantsFunc: func(uuid string) ([]*product.Variant, error) {
+			return []*product.Variant{}, nil
+		},
+	}
+	productController := NewProductController(service)
+	router := gin.Default()
+	router.Use(Management("secret"))
+	router.GET("/api/v1/product", productController.Get)
+	router.GET("/api/v2/product", productController.GetV2)
+	router.GET("/api/v2/products", productController.ListV2)
+	router.GET("/api/v2/products/:uuid/variants", productController.RequireVisible, productController.ListVariants)
+
+	get := func(path string, token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set(actorHeader, "seller@gfg.com")
+		if token != "" {
+			req.Header.Set(managementTokenHeader, token)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Naming an actor alone does not reveal drafts.
+	assert.Equal(t, 404, get("/api/v1/product?id=p1", "").Code)
+	assert.Equal(t, 404, get("/api/v2/product?id=p1", "").Code)
+	assert.Equal(t, 401, get("/api/v2/product?id=p1", "guess").Code)
+	assert.Equal(t, 200, get("/api/v2/product?id=p1", "secret").Code)
+
+	// Sub-resources of a draft are as hidden as the draft.
+	assert.Equal(t, 404, get("/api/v2/products/p1/variants", "").Code)
+	assert.Equal(t, 200, get("/api/v2/products/p1/variants", "secret").Code)
+
+	assert.Equal(t, 200, get("/api/v2/products", "").Code)
+	assert.Equal(t, []product.Status{product.StatusPublished}, service.DoListParams.Statuses)
+
+	assert.Equal(t, 400, get("/api/v2/products?status=draft", "").Code)
+
+	assert.Equal(t, 200, get("/api/v2/products", "secret").Code)
+	assert.Equal(t, []product.Status{product.StatusDraft, product.StatusPublished}, service.DoListParams.Statuses)
+
+	assert.Equal(t, 200, get("/api/v2/products?status=archived", "secret").Code)
+	assert.Equal(t, []product.Status{product.StatusArchived}, service.DoListParams.Statuses)
+
+	assert.Equal(t, 400, get("/api/v2/products?status=deleted", "secret").Code)
+
+	// Without a configured token no request is a management request.
+	router = gin.Default()
+	router.Use(Management(""))
+	router.GET("/api/v2/product", productController.GetV2)
+	assert.Equal(t, 401, get("/api/v2/product?id=p1", "secret").Code)
+	assert.Equal(t, 404, get("/api/v2/product?id=p1", "").Code)
+}
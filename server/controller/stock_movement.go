@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/product"
+)
+
+func (pc *productController) StockMovements(c *gin.Context) {
+	request := &struct {
+		Page int `form:"page,default=1"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	movements, err := pc.productSvc.StockMovements(c.Request.Context(), c.Param("uuid"), request.Page)
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to query stock movements with err=%s", err.Error()))
+
+		if _, ok := err.(*product.ProductNotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query stock movements"})
+		return
+	}
+
+	jsonData, err := json.Marshal(movements)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal stock movements")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal stock movements"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
+
+// StockDiscrepancies lists the products whose stock does not match their stock
+// movements. An empty list means the ledger is consistent.
+func (pc *productController) StockDiscrepancies(c *gin.Context) {
+	request := &struct {
+		Seller string `form:"seller"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	discrepancies, err := pc.productSvc.StockDiscrepancies(c.Request.Context(), request.Seller)
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to check stock ledger with err=%s", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to check stock ledger"})
+		return
+	}
+
+	jsonData, err := json.Marshal(discrepancies)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal stock discrepancies")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal stock discrepancies"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
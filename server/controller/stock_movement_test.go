@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/product"
+)
+
+func Test_ProductStockMovements(t *testing.T) {
+	movedAt := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	service := &productServiceMock{
+		DoStockMovementsFunc: func(uuid string, page int) ([]*product.StockMovement, error) {
+			if uuid != "p1" {
+				return nil, product.NewProductNotFoundError(uuid)
+			}
+			if page > 1 {
+				return []*product.StockMovement{}, nil
+			}
+			return []*product.StockMovement{
+				{ProductUUID: uuid, Delta: -2, Reason: product.StockReasonSale, Reference: "order 1001", Actor: "shop", StockAfter: 8, CreatedAt: movedAt},
+				{ProductUUID: uuid, Delta: 10, Reason: product.StockReasonInitial, Actor: "admin@gfg.com", StockAfter: 10, CreatedAt: movedAt.Add(-time.Hour)},
+			}, nil
+		},
+	}
+	productController := NewProductController(service)
+	router := gin.Default()
+	router.GET("/api/v2/products/:uuid/stock-movements", productController.StockMovements)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v2/products/p1/stock-movements", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `[{"product_uuid":"p1","delta":-2,"reason":"sale","reference":"order 1001","actor":"shop","stock_after":8,"created_at":"2021-06-01T10:00:00Z"},`+
+		`{"product_uuid":"p1","delta":10,"reason":"initial","actor":"admin@gfg.com","stock_after":10,"created_at":"2021-06-01T09:00:00Z"}]`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v2/products/p1/stock-movements?page=2", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `[]`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v2/products/p2/stock-movements", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 404, w.Code)
+}
+
+func Test_ProductStockDiscrepancies(t *testing.T) {
+	var seller string
+	service := &productServiceMock{
+		DoStockDiscrepanciesFunc: func(sellerUUID string) ([]*product.StockDiscrepancy, error) {
+			seller = sellerUUID
+			return []*product.StockDiscrepancy{{ProductUUID: "p1", Stock: 8, LedgerStock: 10}}, nil
+		},
+	}
+	productController := NewProductController(service)
+	router := gin.Default()
+	router.GET("/api/v2/products/stock-discrepancies", productController.StockDiscrepancies)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v2/products/stock-discrepancies?seller=s1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "s1", seller)
+	assert.Equal(t, `[{"product_uuid":"p1","stock":8,"ledger_stock":10}]`, w.Body.String())
+}
+
+func Test_AdjustStockReasonCode(t *testing.T) {
+	var code product.StockReason
+	service := &productServiceMock{
+		DoAdjustStockFunc: func(uuid string, adjustment *product.StockAdjustment) (*product.ProductInfo, error) {
+			code = adjustment.Code
+			return &product.ProductInfo{Product: &product.Product{UUID: uuid, Stock: 9}}, nil
+		},
+	}
+	productController := NewProductController(service)
+	router := gin.Default()
+	router.POST("/api/v2/products/:uuid/stock-adjustments", productController.AdjustStock)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v2/products/p1/stock-adjustments",
+		strings.NewReader(`{"delta":-1,"reason":"dropped in warehouse","reason_code":"damage"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, product.StockReasonDamage, code)
+}
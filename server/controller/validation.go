@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"coding-challenge-go/pkg/brand"
+	"coding-challenge-go/pkg/product"
+)
+
+// Request payloads declare their rules in binding tags. The rules that mirror a
+// column follow the column sizes in build/init_database.sql.
+const (
+	// fieldCodeRequired and the other codes tell clients which rule a field broke.
+	fieldCodeRequired = "required"
+	fieldCodeTooLong  = "too_long"
+	fieldCodeTooLarge = "too_large"
+	fieldCodeTooSmall = "too_small"
+	fieldCodeUUID     = "invalid_uuid"
+	fieldCodeChoice   = "invalid_choice"
+	fieldCodeInvalid  = "invalid"
+)
+
+type (
+	fieldError struct {
+		Field   string `json:"field"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+
+	validationErrorResponse struct {
+		Error  string        `json:"error"`
+		Fields []*fieldError `json:"fields"`
+	}
+)
+
+// requestFieldName returns the name clients send for a field of request rather
+// than its Go name.
+func requestFieldName(request interface{}, fe validator.FieldError) string {
+	field, ok := reflect.TypeOf(request).Elem().FieldByName(fe.StructField())
+	if !ok {
+		return fe.Field()
+	}
+	for _, tag := range []string{"json", "form"} {
+		if name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+	return fe.Field()
+}
+
+// bindJSON decodes the body into request and checks its binding rules. It answers
+// 422 listing every failing field, or 400 when the body cannot be decoded, and
+// reports whether the handler may go on.
+func bindJSON(c *gin.Context, request interface{}) bool {
+	err := c.ShouldBindJSON(request)
+	if err == nil {
+		return true
+	}
+
+	errs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	response := &validationErrorResponse{Error: "Request is invalid"}
+	for _, fe := range errs {
+		response.Fields = append(response.Fields, newFieldError(requestFieldName(request, fe), fe))
+	}
+	c.JSON(http.StatusUnprocessableEntity, response)
+	return false
+}
+
+// writeInvalidProduct answers 422 in the bindJSON format when err tells which
+// fields of a product break a rule, and reports whether it did. The service
+// checks the product a patch or revert produces, so the request rules hold for
+// every write.
+func writeInvalidProduct(c *gin.Context, err error) bool {
+	response := &validationErrorResponse{Error: "Request is invalid"}
+	switch e := err.(type) {
+	case *product.InvalidFieldsError:
+		for _, fe := range e.Fields() {
+			response.Fields = append(response.Fields, newFieldError(fe.Field(), fe))
+		}
+	case *product.InvalidPriceError:
+		response.Fields = []*fieldError{{Field: "price", Code: fieldCodeInvalid, Message: e.Reason()}}
+	case *product.InvalidIdentifierError:
+		response.Fields = []*fieldError{{Field: e.Field(), Code: fieldCodeInvalid, Message: e.Reason()}}
+	case *brand.InvalidBrandError:
+		response.Fields = []*fieldError{{Field: "brand", Code: fieldCodeInvalid, Message: e.Reason()}}
+	default:
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, response)
+	return true
+}
+
+func newFieldError(field string, fe validator.FieldError) *fieldError {
+	e := &fieldError{Field: field}
+	switch fe.Tag() {
+	case "required":
+		e.Code, e.Message = fieldCodeRequired, "is required"
+	case "max":
+		switch fe.Kind() {
+		case reflect.String:
+			e.Code, e.Message = fieldCodeTooLong, fmt.Sprintf("must be at most %s characters", fe.Param())
+		case reflect.Slice, reflect.Map:
+			e.Code, e.Message = fieldCodeTooLong, fmt.Sprintf("must contain at most %s", fe.Param())
+		default:
+			e.Code, e.Message = fieldCodeTooLarge, fmt.Sprintf("must be at most %s", fe.Param())
+		}
+	case "min":
+		switch fe.Kind() {
+		case reflect.Slice, reflect.Map:
+			e.Code, e.Message = fieldCodeTooSmall, fmt.Sprintf("must contain at least %s", fe.Param())
+		default:
+			e.Code, e.Message = fieldCodeTooSmall, fmt.Sprintf("must be at least %s", fe.Param())
+		}
+	case "uuid":
+		e.Code, e.Message = fieldCodeUUID, "must be a UUID"
+	case "oneof":
+		e.Code, e.Message = fieldCodeChoice, fmt.Sprintf("must be one of %s", fe.Param())
+	default:
+		e.Code, e.Message = fieldCodeInvalid, fmt.Sprintf("breaks rule %s", fe.Tag())
+	}
+	return e
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PostProductValidation(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+		expected   string
+	}{
+		{
+			name:       "test valid product",
+			body:       `{"name":"Berlin New Shirt","brand":"ShirtsCo","stock":3,"seller":"38c47b48-f563-11e9-94e7-38baf859afa1"}`,
+			statusCode: 200,
+		},
+		{
+			name:       "test every failing field is listed",
+			body:       `{"name":"","stock":-1,"seller":"s1","sku":"` + strings.Repeat("x", 65) + `"}`,
+			statusCode: 422,
+			expected: `{"error":"Request is invalid","fields":[` +
+				`{"field":"name","code":"required","message":"is required"},` +
+				`{"field":"brand","code":"required","message":"is required"},` +
+				`{"field":"stock","code":"too_small","message":"must be at least 0"},` +
+				`{"field":"seller","code":"invalid_uuid","message":"must be a UUID"},` +
+				`{"field":"sku","code":"too_long","message":"must be at most 64 characters"}]}`,
+		},
+		{
+			name:       "test name longer than the column",
+			body:       `{"name":"` + strings.Repeat("ä", 201) + `","brand":"ShirtsCo","seller":"38c47b48-f563-11e9-94e7-38baf859afa1"}`,
+			statusCode: 422,
+			expected:   `{"error":"Request is invalid","fields":[{"field":"name","code":"too_long","message":"must be at most 200 characters"}]}`,
+		},
+		{
+			name:       "test malformed body",
+			body:       `{"name":`,
+			statusCode: 400,
+			expected:   `{"error":"unexpected EOF"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			productController := NewProductController(&productServiceMock{})
+			router := gin.Default()
+			router.POST("/api/v1/product", productController.Post)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/v1/product", strings.NewReader(test.body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.statusCode, w.Code)
+			if test.expected != "" {
+				assert.Equal(t, test.expected, w.Body.String())
+			}
+		})
+	}
+
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"coding-challenge-go/pkg/product"
+)
+
+func (pc *productController) ListVariants(c *gin.Context) {
+	variants, err := pc.productSvc.Variants(c.Request.Context(), c.Param("uuid"))
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to query variants with err=%s", err.Error()))
+		handleVariantError(c, err)
+		return
+	}
+
+	jsonData, err := json.Marshal(variants)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal variants")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal variants"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
+
+func (pc *productController) PostVariant(c *gin.Context) {
+	request := &struct {
+		SKU     string            `json:"sku" binding:"required,max=64"`
+		Options map[string]string `json:"options" binding:"required,min=1"`
+		Stock   int               `json:"stock" binding:"min=0"`
+	}{}
+
+	if !bindJSON(c, request) {
+		return
+	}
+
+	variant := &product.Variant{
+		SKU:     request.SKU,
+		Options: request.Options,
+		Stock:   request.Stock,
+	}
+	err := pc.productSvc.CreateVariant(c.Request.Context(), c.Param("uuid"), variant)
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to create variant with err=%s", err.Error()))
+		handleVariantError(c, err)
+		return
+	}
+
+	jsonData, err := json.Marshal(variant)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal variant")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal variant"})
+		return
+	}
+
+	c.Data(http.StatusCreated, "application/json; charset=utf-8", jsonData)
+}
+
+func (pc *productController) AdjustVariantStock(c *gin.Context) {
+	request := &struct {
+		Delta      int    `json:"delta" binding:"required"`
+		Reason     string `json:"reason" binding:"required,max=200"`
+		ReasonCode string `json:"reason_code" binding:"omitempty,oneof=sale return damage restock correction"`
+	}{}
+
+	if !bindJSON(c, request) {
+		return
+	}
+
+	variant, err := pc.productSvc.AdjustVariantStock(c.Request.Context(), c.Param("uuid"), c.Param("variant"), &product.StockAdjustment{
+		Delta:  request.Delta,
+		Reason: request.Reason,
+		Code:   product.StockReason(request.ReasonCode),
+	})
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to adjust variant stock with err=%s", err.Error()))
+		handleVariantError(c, err)
+		return
+	}
+
+	jsonData, err := json.Marshal(&stockAdjustmentResponse{
+		UUID:   variant.UUID,
+		Delta:  request.Delta,
+		Reason: request.Reason,
+		Stock:  variant.Stock,
+	})
+
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to marshal stock adjustment")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal stock adjustment"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
+
+func (pc *productController) DeleteVariant(c *gin.Context) {
+	err := pc.productSvc.DeleteVariant(c.Request.Context(), c.Param("uuid"), c.Param("variant"))
+
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("Fail to delete variant with err=%s", err.Error()))
+		handleVariantError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func handleVariantError(c *gin.Context, err error) {
+	switch err.(type) {
+	case *product.InvalidVariantError, *product.InvalidStockAdjustmentError:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case *product.ProductNotFoundError, *product.VariantNotFoundError, *product.SellerNotFoundError:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case *product.InsufficientStockError, *product.DerivedStockError, *product.BundleComponentError:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"coding-challenge-go/pkg/product"
+)
+
+func Test_Variants(t *testing.T) {
+	service := &productServiceMock{
+		DoGetProductFunc: func(uuid string) (*product.ProductInfo, error) {
+			return &product.ProductInfo{
+				Product: &product.Product{UUID: uuid, Name: "Berlin New Shirt", Stock: 4, Version: 3, Status: product.StatusPublished},
+				Variants: []*product.Variant{
+					{UUID: "v1", SKU: "BNS-M", Options: map[string]string{"size": "M"}, Stock: 4},
+				},
+			}, nil
+		},
+		DoCreateVariantFunc: func(productUUID string, variant *product.Variant) error {
+			if variant.SKU == "" {
+				return product.NewInvalidVariantError("sku must be between 1 and 64 characters")
+			}
+			variant.UUID = "v2"
+			return nil
+		},
+		DoAdjustVariantStockFunc: func(productUUID string, variantUUID string, adjustment *product.StockAdjustment) (*product.Variant, error) {
+			if variantUUID != "v1" {
+				return nil, product.NewVariantNotFoundError(productUUID, variantUUID)
+			}
+			return &product.Variant{UUID: "v1", SKU: "BNS-M", Stock: 4 + adjustment.Delta}, nil
+		},
+		DoDeleteVariantFunc: func(productUUID string, variantUUID string) error {
+			return nil
+		},
+	}
+	productController := NewProductController(service)
+	router := gin.Default()
+	router.GET("/api/v2/product", productController.GetV2)
+	router.POST("/api/v2/products/:uuid/variants", productController.PostVariant)
+	router.POST("/api/v2/products/:uuid/variants/:variant/stock-adjustments", productController.AdjustVariantStock)
+	router.DELETE("/api/v2/products/:uuid/variants/:variant", productController.DeleteVariant)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v2/product?id=p1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"uuid":"p1","name":"Berlin New Shirt","brand":"","stock":4,"available_stock":0,"seller_uuid":"","price":null,"status":"published","seller":null,`+
+		`"variants":[{"uuid":"v1","sku":"BNS-M","options":{"size":"M"},"stock":4}]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v2/products/p1/variants", strings.NewReader(`{"sku":"BNS-L","options":{"size":"L"},"stock":2}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, `{"uuid":"v2","sku":"BNS-L","options":{"size":"L"},"stock":2}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v2/products/p1/variants", strings.NewReader(`{"options":{"size":"L"}}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 422, w.Code)
+	assert.Equal(t, `{"error":"Request is invalid","fields":[{"field":"sku","code":"required","message":"is required"}]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v2/products/p1/variants/v1/stock-adjustments", strings.NewReader(`{"delta":-1,"reason":"order 1042"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"uuid":"v1","delta":-1,"reason":"order 1042","stock":3}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v2/products/p1/variants/v9/stock-adjustments", strings.NewReader(`{"delta":-1,"reason":"order 1042"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 404, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/api/v2/products/p1/variants/v1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+
+	"coding-challenge-go/pkg/product"
+	"coding-challenge-go/pkg/seller"
+	"coding-challenge-go/server/config"
+)
+
+// Export runs the export subcommand, streaming the product catalog into a file.
+func Export(cfg *config.AppConfig, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	formatName := fs.String("format", string(product.ExportFormatCSV), "output format: csv or ndjson")
+	output := fs.String("output", "", "file to write, defaults to products.<format>")
+	sellerUUID := fs.String("seller", "", "only export products of this seller")
+	brand := fs.String("brand", "", "only export products of this brand")
+	currency := fs.String("currency", "", "only export products priced in this currency")
+	categoryName := fs.String("category", "", "only export products in this category (UUID or path) or below it")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := product.ParseExportFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		*output = fmt.Sprintf("products.%s", format)
+	}
+
+	db, err := sql.Open("mysql", cfg.MySQLConfig.DSN())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	productSvc := product.NewService(product.NewRepository(db), seller.NewRepository(db), getNotiProvider(cfg.NotiProdiverType))
+	err = productSvc.Export(context.Background(), &product.FilterParams{
+		SellerUUID: *sellerUUID,
+		Brand:      *brand,
+		Currency:   *currency,
+		Category:   *categoryName,
+	}, format, f)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"os"
+
+	"coding-challenge-go/pkg/brand"
+	"coding-challenge-go/server/config"
+)
+
+// MigrateBrands runs the migrate-brands subcommand, linking the free-text brand
+// of every product to a brand and printing which spellings were merged.
+func MigrateBrands(cfg *config.AppConfig, args []string) error {
+	fs := flag.NewFlagSet("migrate-brands", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only report what would be merged")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := sql.Open("mysql", cfg.MySQLConfig.DSN())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	report, err := brand.NewService(brand.NewRepository(db)).Migrate(context.Background(), *dryRun)
+	if err != nil {
+		return err
+	}
+
+	return report.WriteText(os.Stdout)
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -8,8 +9,16 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
+	"coding-challenge-go/pkg/brand"
+	"coding-challenge-go/pkg/category"
+	"coding-challenge-go/pkg/idempotency"
+	"coding-challenge-go/pkg/location"
 	"coding-challenge-go/pkg/product"
+	"coding-challenge-go/pkg/reconciliation"
+	"coding-challenge-go/pkg/reservation"
+	"coding-challenge-go/pkg/schedule"
 	"coding-challenge-go/pkg/seller"
+	"coding-challenge-go/pkg/storage"
 	"coding-challenge-go/server/config"
 	"coding-challenge-go/server/controller"
 )
@@ -28,37 +37,145 @@ func Server(cfg *config.AppConfig) {
 	defer db.Close()
 
 	r := gin.New()
+	r.Use(controller.RequestInfo)
+	r.Use(controller.Management(cfg.ManagementToken))
 
 	productRepository := product.NewRepository(db)
 	sellerRepository := seller.NewRepository(db)
+	categoryRepository := category.NewRepository(db)
+	brandSvc := brand.NewService(brand.NewRepository(db))
+	locationRepository := location.NewRepository(db)
 	notiProvider := getNotiProvider(cfg.NotiProdiverType)
 	if notiProvider == nil {
 		log.Fatal().Msg("NotiProvider is nil")
 	}
-	productSvc := product.NewService(productRepository, sellerRepository, notiProvider)
+	duplicateMode, err := product.ParseDuplicateMode(cfg.DuplicateCheck)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Unsupported duplicate check")
+	}
+	productSvc := product.NewService(
+		productRepository, sellerRepository, notiProvider,
+		product.WithBackorders(cfg.AllowBackorders),
+		product.WithAuditRepository(product.NewAuditRepository(db)),
+		product.WithPriceHistoryRepository(product.NewPriceHistoryRepository(db)),
+		product.WithPriceAlertRepository(product.NewPriceAlertRepository(db)),
+		product.WithStockMovementRepository(product.NewStockMovementRepository(db)),
+		product.WithPriceDropAlert(cfg.PriceDropAlertPercent),
+		product.WithLowStockAlert(cfg.LowStockThreshold),
+		product.WithVariantRepository(product.NewVariantRepository(db)),
+		product.WithCategoryRepository(categoryRepository),
+		product.WithBrandResolver(brandSvc),
+		product.WithLocationRepository(locationRepository),
+		product.WithLocationStockRepository(product.NewLocationStockRepository(db)),
+		product.WithBundleRepository(product.NewBundleRepository(db)),
+		product.WithImageRepository(product.NewImageRepository(db)),
+		product.WithImageStorage(storage.NewLocalStorage(cfg.ImageStoragePath)),
+		product.WithMaxImageSize(cfg.MaxImageSize),
+		product.WithDuplicateCheck(duplicateMode, cfg.DuplicateSimilarity),
+	)
 	sellerSvc := seller.NewService(sellerRepository)
+	reservationSvc := reservation.NewService(reservation.NewRepository(db), productSvc, cfg.ReservationTTL)
 	productController := controller.NewProductController(productSvc)
 	sellerController := controller.NewSellerController(sellerSvc)
+	categoryController := controller.NewCategoryController(category.NewService(categoryRepository))
+	brandController := controller.NewBrandController(brandSvc)
+	locationController := controller.NewLocationController(location.NewService(locationRepository, sellerRepository))
+	reservationController := controller.NewReservationController(reservationSvc)
+	idempotencySvc := idempotency.NewService(idempotency.NewRepository(db), cfg.IdempotencyKeyTTL)
+	scheduleSvc := schedule.NewService(schedule.NewRepository(db), productSvc, cfg.ScheduledChangeMaxAttempts, cfg.ScheduledChangeRetryDelay)
+	scheduleController := controller.NewScheduleController(scheduleSvc)
+	reconciliationController := controller.NewReconciliationController(reconciliation.NewService(reconciliation.NewRepository(db), productSvc))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reservation.Reap(ctx, reservationSvc, cfg.ReservationReaperInterval)
+	go idempotency.Reap(ctx, idempotencySvc, cfg.IdempotencyReaperInterval)
+	go schedule.Run(ctx, scheduleSvc, cfg.SchedulerInterval)
+	if cfg.ProductPurgeRetention > 0 {
+		go product.Purge(ctx, productSvc, cfg.ProductPurgeInterval, cfg.ProductPurgeRetention)
+	}
 
-	v1 := r.Group("api/v1")
+	requireIfMatch := controller.RequireIfMatch(cfg.RequireIfMatch)
+	idempotent := controller.Idempotency(idempotencySvc)
+
+	v1 := r.Group("api/v1", idempotent)
 	{
 		// path for product
 		v1.GET("products", productController.List)
 		v1.GET("product", productController.Get)
 		v1.POST("product", productController.Post)
-		v1.PUT("product", productController.Put)
-		v1.DELETE("product", productController.Delete)
+		v1.PUT("product", requireIfMatch, productController.Put)
+		v1.DELETE("product", requireIfMatch, productController.Delete)
 
 		// Path for seller
 		v1.GET("sellers", sellerController.List)
 	}
 
-	v2 := r.Group("api/v2")
+	v2 := r.Group("api/v2", idempotent)
 	{
 		v2.GET("products", productController.ListV2)
+		v2.GET("products/export", productController.Export)
+		v2.GET("products/trash", productController.Trash)
+		v2.GET("products/duplicates", productController.Duplicates)
+		v2.GET("products/stock-discrepancies", productController.StockDiscrepancies)
+		v2.GET("products/by-gtin/:code", productController.GetByGTIN)
 		v2.GET("product", productController.GetV2)
+		v2.PATCH("products/:uuid", requireIfMatch, productController.Patch)
+		v2.POST("products/:uuid/stock-adjustments", productController.AdjustStock)
+		v2.POST("products/:uuid/restore", productController.Restore)
+		v2.POST("products/:uuid/status", requireIfMatch, productController.SetStatus)
+		v2.GET("products/:uuid/history", productController.RequireVisible, productController.History)
+		v2.GET("products/:uuid/price-history", productController.RequireVisible, productController.PriceHistory)
+		v2.GET("products/:uuid/price-alerts", productController.RequireVisible, productController.ListPriceAlerts)
+		v2.POST("products/:uuid/price-alerts", productController.PostPriceAlert)
+		v2.DELETE("products/:uuid/price-alerts/:alert", productController.DeletePriceAlert)
+		v2.GET("products/:uuid/stock-movements", productController.RequireVisible, productController.StockMovements)
+		v2.GET("products/:uuid/variants", productController.RequireVisible, productController.ListVariants)
+		v2.POST("products/:uuid/variants", productController.PostVariant)
+		v2.POST("products/:uuid/variants/:variant/stock-adjustments", productController.AdjustVariantStock)
+		v2.DELETE("products/:uuid/variants/:variant", productController.DeleteVariant)
+		v2.PUT("products/:uuid/categories", productController.SetCategories)
+		v2.PUT("products/:uuid/components", requireIfMatch, productController.SetComponents)
+		v2.GET("products/:uuid/locations", productController.RequireVisible, productController.ListLocationStock)
+		v2.POST("products/:uuid/locations/:location/stock-adjustments", productController.AdjustLocationStock)
+		v2.POST("products/:uuid/stock-moves", productController.MoveStock)
+		v2.GET("products/:uuid/images", productController.RequireVisible, productController.ListImages)
+		v2.POST("products/:uuid/images", productController.PostImage)
+		v2.GET("products/:uuid/images/:image", productController.RequireVisible, productController.GetImage)
+		v2.DELETE("products/:uuid/images/:image", productController.DeleteImage)
+		v2.GET("categories", categoryController.List)
+		v2.POST("categories", categoryController.Post)
+		v2.GET("categories/:uuid", categoryController.Get)
+		v2.PUT("categories/:uuid", categoryController.Put)
+		v2.DELETE("categories/:uuid", categoryController.Delete)
+		v2.GET("brands", brandController.List)
+		v2.POST("brands", brandController.Post)
+		v2.GET("brands/:uuid", brandController.Get)
+		v2.PUT("brands/:uuid", brandController.Put)
+		v2.DELETE("brands/:uuid", brandController.Delete)
+		v2.GET("locations", locationController.List)
+		v2.POST("locations", locationController.Post)
+		v2.GET("locations/:uuid", locationController.Get)
+		v2.PUT("locations/:uuid", locationController.Put)
+		v2.DELETE("locations/:uuid", locationController.Delete)
+		v2.GET("scheduled-changes", scheduleController.List)
+		v2.POST("scheduled-changes", scheduleController.Post)
+		v2.GET("scheduled-changes/:uuid", scheduleController.Get)
+		v2.PUT("scheduled-changes/:uuid", scheduleController.Put)
+		v2.DELETE("scheduled-changes/:uuid", scheduleController.Delete)
+		v2.GET("reconciliations", reconciliationController.List)
+		v2.POST("reconciliations", reconciliationController.Post)
+		v2.GET("reconciliations/:uuid", reconciliationController.Get)
+		v2.POST("reconciliations/:uuid/approve", reconciliationController.Approve)
+		v2.POST("reconciliations/:uuid/reject", reconciliationController.Reject)
+		v2.POST("products/:uuid/revert", requireIfMatch, productController.Revert)
 
 		v2.GET("sellers/top10", sellerController.Top10ByProduct)
+
+		v2.POST("reservations", reservationController.Post)
+		v2.GET("reservations/:uuid", reservationController.Get)
+		v2.POST("reservations/:uuid/confirm", reservationController.Confirm)
+		v2.POST("reservations/:uuid/release", reservationController.Release)
 	}
 
 	log.Info().Msg("Start server")